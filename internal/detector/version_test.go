@@ -0,0 +1,61 @@
+package detector
+
+import "testing"
+
+const (
+	mssqlBanner      = "Microsoft SQL Server 2019 (RTM-CU18) (KB5017593) - 15.0.4261.1 (X64)\n\tSep 12 2022 15:07:06\n\tCopyright (C) 2019 Microsoft Corporation\n\tDeveloper Edition (64-bit) on Linux (Ubuntu 20.04.5 LTS) <X64>"
+	oracleBanner     = "Oracle Database 19c Enterprise Edition Release 19.0.0.0.0 - Production"
+	oracleBannerFull = oracleBanner + "\nVersion 19.3.0.0.0"
+	cockroachBanner  = "CockroachDB CCL v23.1.11 (x86_64-pc-linux-gnu, built 2023/09/27 01:53:43, go1.19.10)"
+)
+
+func TestPlausibleVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		banner string
+		want   bool
+	}{
+		{"mysql", "8.0.36-0ubuntu0.22.04.1", true},
+		{"mariadb", "10.6.12-MariaDB-1:10.6.12+maria~ubu2004", true},
+		{"mssql multiline", mssqlBanner, true},
+		{"postgres", "PostgreSQL 15.4 on x86_64-pc-linux-gnu, compiled by gcc", true},
+		{"cockroach", cockroachBanner, true},
+		{"oracle banner_full", oracleBannerFull, true},
+		{"db2 level", "SQL11057", true},
+		{"control character", "8.0.\x0136", false},
+		{"invalid utf-8", "8.0\xff.36", false},
+	}
+
+	for _, tt := range tests {
+		if got := PlausibleVersion(tt.banner); got != tt.want {
+			t.Errorf("%s: PlausibleVersion(%q) = %v, want %v", tt.name, tt.banner, got, tt.want)
+		}
+	}
+}
+
+func TestMergeVersions(t *testing.T) {
+	tests := []struct {
+		name   string
+		dbType DatabaseType
+		a, b   string
+		want   string
+	}{
+		{"mysql truncated", MySQL, "8.0.3", "8.0.36-log", "8.0.36-log"},
+		{"mysql empty", MySQL, "8.0.36", "", "8.0.36"},
+		{"mariadb same version, more detail", MySQL, "10.6.12-MariaDB", "10.6.12", "10.6.12-MariaDB"},
+		{"mariadb conflict keeps b", MySQL, "10.6.12-MariaDB", "10.5.9-MariaDB", "10.5.9-MariaDB"},
+		{"mssql version beats noise", MSSQL, mssqlBanner, "Microsoft SQL Server", mssqlBanner},
+		{"postgres truncated", PostgreSQL, "PostgreSQL 15", "PostgreSQL 15.4 on x86_64-pc-linux-gnu", "PostgreSQL 15.4 on x86_64-pc-linux-gnu"},
+		{"cockroach same version", PostgreSQL, "CockroachDB CCL v23.1.11", cockroachBanner, cockroachBanner},
+		{"oracle banner_full", Oracle, oracleBanner, oracleBannerFull, oracleBannerFull},
+		{"oracle noise", Oracle, "PL/SQL Release", oracleBanner, oracleBanner},
+		{"db2 level conflict keeps b", DB2, "SQL11057", "SQL11058", "SQL11058"},
+		{"db2 level vs number", DB2, "DB2 v11.5.7.0", "SQL11057", "DB2 v11.5.7.0"},
+	}
+
+	for _, tt := range tests {
+		if got := MergeVersions(tt.dbType, tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: MergeVersions(%q, %q) = %q, want %q", tt.name, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
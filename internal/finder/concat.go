@@ -0,0 +1,71 @@
+package finder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+// rowDelimiter separates cells in concatenated row extraction. A multi-char
+// sequence is unlikely to appear in data, and collisions are still detected by
+// checking the number of cells after splitting.
+const rowDelimiter = "~|~"
+
+// errConcatMismatch means a concatenated row could not be split back into its cells
+var errConcatMismatch = errors.New("concatenated row does not split into the expected cells")
+
+// SetConcatRows enables extracting each row with a single concatenated query
+func (f *Finder) SetConcatRows(enabled bool) {
+	f.concatRows = enabled
+}
+
+// extractRowConcatenated extracts all cells of a row at once by concatenating the
+// columns with rowDelimiter, which saves the length probes of every cell but one.
+// Returns errConcatMismatch when a cell contains the delimiter or the row was
+// truncated, so the caller can fall back to per-column extraction.
+func (f *Finder) extractRowConcatenated(tableName string, columns []string, rowIdx int) ([]string, error) {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = f.quote(col)
+	}
+	expr := f.payloadGen.GetConcatPayload(quoted, rowDelimiter)
+	query := f.getExprQuery(tableName, expr, f.rowOrder(tableName, columns), rowIdx)
+
+	// Each cell keeps its own max length, plus room for the delimiters
+	maxLen := 0
+	if f.maxLen > 0 {
+		maxLen = f.maxLen*len(columns) + len(rowDelimiter)*(len(columns)-1)
+	}
+
+	ui.Progress("Row %d: extracting (concatenated)...", rowIdx+1)
+	combined, err := f.extractStringLimit(query, maxLen, false)
+	ui.ProgressDone()
+	if err != nil {
+		return nil, err
+	}
+
+	// No row at this offset
+	if combined == "" {
+		return make([]string, len(columns)), nil
+	}
+
+	// Uncertain chars can't be attributed to a cell, redo the row cell by cell
+	if len(f.uncertain) > 0 {
+		return nil, fmt.Errorf("%w: uncertain characters", errConcatMismatch)
+	}
+
+	cells := strings.Split(combined, rowDelimiter)
+	if len(cells) != len(columns) {
+		return nil, fmt.Errorf("%w: got %d of %d", errConcatMismatch, len(cells), len(columns))
+	}
+
+	for _, cell := range cells {
+		f.cache.SaveKnownString(cell)
+	}
+	ui.Progress("Row %d: | %s", rowIdx+1, strings.Join(cells, " | "))
+	ui.ProgressDone()
+
+	return cells, nil
+}
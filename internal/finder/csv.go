@@ -0,0 +1,77 @@
+package finder
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Output formats for dumped tables
+const (
+	OutputMarkdown = "md"
+	OutputCSV      = "csv"
+)
+
+// WriteTableCSV writes a table's data to a CSV file (header row = columns)
+func WriteTableCSV(path string, table TableData) error {
+	if err := initCSVFile(path, table.Columns, false); err != nil {
+		return err
+	}
+	for _, row := range table.Rows {
+		if err := appendCSVRow(path, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// initCSVFile creates the CSV file with the header row. With appendMode, rows
+// are added to an existing file and the header row is only written if it is empty.
+func initCSVFile(path string, columns []string, appendMode bool) error {
+	file, hasContent, err := openOutputFile(path, appendMode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if hasContent {
+		return nil
+	}
+
+	w := csv.NewWriter(file)
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// appendCSVRow appends a single row to the CSV file
+func appendCSVRow(path string, row []string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write(row); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvPathForTable returns the CSV path for a table in multi-table runs
+// (e.g. "out.csv" + "users" -> "out_users.csv")
+func csvPathForTable(outputPath, tableName string) string {
+	ext := filepath.Ext(outputPath)
+	base := strings.TrimSuffix(outputPath, ext)
+	safeName := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, tableName)
+	return base + "_" + safeName + ".csv"
+}
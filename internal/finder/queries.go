@@ -2,23 +2,88 @@ package finder
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/morkin1792/flatsqli/internal/detector"
+	"github.com/morkin1792/flatsqli/internal/payloads"
 )
 
 // All queries use simple LIKE with single term - WAF-friendly, works on all databases
 
+// quote quotes a table or column name for use outside string literals. Names
+// read from the catalog keep their exact case, names typed by the user are
+// left to case folding when they are single-case.
+func (f *Finder) quote(name string) string {
+	if f.payloadGen == nil {
+		return name
+	}
+	if f.exact[name] {
+		return payloads.QuoteCatalogName(f.payloadGen, name)
+	}
+	return f.payloadGen.QuoteIdentifier(name)
+}
+
+// exactName records a name read from the catalog (or cached from it), whose
+// case is exact, and returns it
+func (f *Finder) exactName(name string) string {
+	f.exact[name] = true
+	return name
+}
+
+// qualify quotes a table name, prefixed with the schema set by SetSchema. MSSQL
+// schemas are databases, whose tables are reached through the default schema.
+func (f *Finder) qualify(tableName string) string {
+	switch {
+	case f.schema == "" || strings.Contains(tableName, "."):
+		return f.quote(tableName)
+	case f.dbType == detector.MSSQL:
+		return f.quote(f.schema) + ".." + f.quote(tableName)
+	default:
+		return f.quote(f.schema) + "." + f.quote(tableName)
+	}
+}
+
+// legacyPaging reports whether the database predates OFFSET ... FETCH, which
+// DB2 only supports since 11.1. ROW_NUMBER() is used instead.
+func (f *Finder) legacyPaging() bool {
+	if f.dbType != detector.DB2 || f.versionMajor == 0 {
+		return false
+	}
+	return detector.CompareVersion(f.versionMajor, f.versionMinor, 0, 11, 1, 0) < 0
+}
+
+// columnName returns the catalog column holding column names
+func (f *Finder) columnName() string {
+	if f.dbType == detector.DB2 {
+		return "colname"
+	}
+	return "column_name"
+}
+
+// termFilter matches column names containing term (DB2 names are upper case)
+func (f *Finder) termFilter(term string) string {
+	if f.dbType == detector.DB2 {
+		return fmt.Sprintf("LOWER(colname) LIKE '%%%s%%'", term)
+	}
+	return fmt.Sprintf("column_name LIKE '%%%s%%'", term)
+}
+
 // getTableAtOffsetSingleTerm returns query to get table_name matching a single term at offset
 func (f *Finder) getTableAtOffsetSingleTerm(term string, offset int) string {
+	c := f.columnCatalog()
+	where := c.filter(f.termFilter(term))
 	switch f.dbType {
-	case detector.MySQL:
-		return fmt.Sprintf("SELECT table_name FROM (SELECT DISTINCT table_name FROM information_schema.columns WHERE table_schema=database() AND column_name LIKE '%%%s%%' ORDER BY table_name) t LIMIT 1 OFFSET %d", term, offset)
+	case detector.MySQL, detector.PostgreSQL:
+		return fmt.Sprintf("SELECT table_name FROM (SELECT DISTINCT table_name FROM %s%s ORDER BY table_name) t LIMIT 1 OFFSET %d", c.name, where, offset)
 	case detector.MSSQL:
-		return fmt.Sprintf("SELECT table_name FROM (SELECT table_name, ROW_NUMBER() OVER (ORDER BY table_name) as rn FROM (SELECT DISTINCT table_name FROM INFORMATION_SCHEMA.COLUMNS WHERE table_schema NOT IN ('sys','INFORMATION_SCHEMA') AND column_name LIKE '%%%s%%') t) x WHERE rn=%d", term, offset+1)
-	case detector.PostgreSQL:
-		return fmt.Sprintf("SELECT table_name FROM (SELECT DISTINCT table_name FROM information_schema.columns WHERE table_schema='public' AND column_name LIKE '%%%s%%' ORDER BY table_name) t LIMIT 1 OFFSET %d", term, offset)
+		return fmt.Sprintf("SELECT table_name FROM (SELECT table_name, ROW_NUMBER() OVER (ORDER BY table_name) as rn FROM (SELECT DISTINCT table_name FROM %s%s) t) x WHERE rn=%d", c.name, where, offset+1)
 	case detector.Oracle:
-		return fmt.Sprintf("SELECT table_name FROM (SELECT table_name, ROW_NUMBER() OVER (ORDER BY table_name) rn FROM (SELECT DISTINCT table_name FROM user_tab_columns WHERE column_name LIKE '%%%s%%') t) WHERE rn=%d", term, offset+1)
+		return fmt.Sprintf("SELECT table_name FROM (SELECT table_name, ROW_NUMBER() OVER (ORDER BY table_name) rn FROM (SELECT DISTINCT table_name FROM %s%s) t) WHERE rn=%d", c.name, where, offset+1)
+	case detector.DB2:
+		if f.legacyPaging() {
+			return fmt.Sprintf("SELECT tabname FROM (SELECT tabname, ROW_NUMBER() OVER (ORDER BY tabname) rn FROM (SELECT DISTINCT tabname FROM %s%s) t) x WHERE rn=%d", c.name, where, offset+1)
+		}
+		return fmt.Sprintf("SELECT DISTINCT tabname FROM %s%s ORDER BY tabname OFFSET %d ROWS FETCH FIRST 1 ROW ONLY", c.name, where, offset)
 	default:
 		return ""
 	}
@@ -26,15 +91,21 @@ func (f *Finder) getTableAtOffsetSingleTerm(term string, offset int) string {
 
 // getColumnAtOffsetSingleTerm returns query to get column_name matching a single term at offset
 func (f *Finder) getColumnAtOffsetSingleTerm(term string, offset int) string {
+	c := f.columnCatalog()
+	where := c.filter(f.termFilter(term))
+	col := f.columnName()
 	switch f.dbType {
-	case detector.MySQL:
-		return fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_schema=database() AND column_name LIKE '%%%s%%' ORDER BY table_name, column_name LIMIT 1 OFFSET %d", term, offset)
+	case detector.MySQL, detector.PostgreSQL:
+		return fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s, %s LIMIT 1 OFFSET %d", col, c.name, where, c.column, col, offset)
 	case detector.MSSQL:
-		return fmt.Sprintf("SELECT column_name FROM (SELECT column_name, ROW_NUMBER() OVER (ORDER BY table_name, column_name) as rn FROM INFORMATION_SCHEMA.COLUMNS WHERE table_schema NOT IN ('sys','INFORMATION_SCHEMA') AND column_name LIKE '%%%s%%') x WHERE rn=%d", term, offset+1)
-	case detector.PostgreSQL:
-		return fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_schema='public' AND column_name LIKE '%%%s%%' ORDER BY table_name, column_name LIMIT 1 OFFSET %d", term, offset)
+		return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY %s, %s) as rn FROM %s%s) x WHERE rn=%d", col, col, c.column, col, c.name, where, offset+1)
 	case detector.Oracle:
-		return fmt.Sprintf("SELECT column_name FROM (SELECT column_name, ROW_NUMBER() OVER (ORDER BY table_name, column_name) rn FROM user_tab_columns WHERE column_name LIKE '%%%s%%') WHERE rn=%d", term, offset+1)
+		return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY %s, %s) rn FROM %s%s) WHERE rn=%d", col, col, c.column, col, c.name, where, offset+1)
+	case detector.DB2:
+		if f.legacyPaging() {
+			return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY %s, %s) rn FROM %s%s) x WHERE rn=%d", col, col, c.column, col, c.name, where, offset+1)
+		}
+		return fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s, %s OFFSET %d ROWS FETCH FIRST 1 ROW ONLY", col, c.name, where, c.column, col, offset)
 	default:
 		return ""
 	}
@@ -42,53 +113,109 @@ func (f *Finder) getColumnAtOffsetSingleTerm(term string, offset int) string {
 
 // getTableColumnAtOffset returns query to get a column name from a table at offset
 func (f *Finder) getTableColumnAtOffset(tableName string, offset int) string {
+	c := f.columnCatalog()
+	where := c.filter(fmt.Sprintf("%s='%s'", c.column, tableName))
+	col := f.columnName()
 	switch f.dbType {
-	case detector.MySQL:
-		return fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_schema=database() AND table_name='%s' ORDER BY ordinal_position LIMIT 1 OFFSET %d", tableName, offset)
+	case detector.MySQL, detector.PostgreSQL:
+		return fmt.Sprintf("SELECT %s FROM %s%s ORDER BY ordinal_position LIMIT 1 OFFSET %d", col, c.name, where, offset)
 	case detector.MSSQL:
-		return fmt.Sprintf("SELECT column_name FROM (SELECT column_name, ROW_NUMBER() OVER (ORDER BY ordinal_position) as rn FROM INFORMATION_SCHEMA.COLUMNS WHERE table_name='%s') x WHERE rn=%d", tableName, offset+1)
-	case detector.PostgreSQL:
-		return fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_schema='public' AND table_name='%s' ORDER BY ordinal_position LIMIT 1 OFFSET %d", tableName, offset)
+		return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY ordinal_position) as rn FROM %s%s) x WHERE rn=%d", col, col, c.name, where, offset+1)
 	case detector.Oracle:
-		return fmt.Sprintf("SELECT column_name FROM (SELECT column_name, ROW_NUMBER() OVER (ORDER BY column_id) rn FROM user_tab_columns WHERE table_name='%s') WHERE rn=%d", tableName, offset+1)
+		return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY column_id) rn FROM %s%s) WHERE rn=%d", col, col, c.name, where, offset+1)
+	case detector.DB2:
+		if f.legacyPaging() {
+			return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY colno) rn FROM %s%s) x WHERE rn=%d", col, col, c.name, where, offset+1)
+		}
+		return fmt.Sprintf("SELECT %s FROM %s%s ORDER BY colno OFFSET %d ROWS FETCH FIRST 1 ROW ONLY", col, c.name, where, offset)
 	default:
 		return ""
 	}
 }
 
-// getCellQuery returns query to get a specific cell value
-func (f *Finder) getCellQuery(tableName, columnName string, rowOffset int) string {
+// getCellQuery returns query to get a specific cell value.
+// orderBy is the column giving a stable row order ("" for the natural order).
+func (f *Finder) getCellQuery(tableName, columnName, orderBy string, rowOffset int) string {
+	return f.rowQuery(f.qualify(tableName), f.quote(columnName), f.quote(orderBy), rowOffset)
+}
+
+// rowQuery builds the query selecting expr from one row. Names must be quoted.
+func (f *Finder) rowQuery(tableName, columnName, orderBy string, rowOffset int) string {
+	if f.dbType == detector.Oracle {
+		return f.oracleRowQuery(tableName, columnName, orderBy, rowOffset)
+	}
+
+	if orderBy == "" {
+		switch f.dbType {
+		case detector.MySQL, detector.PostgreSQL:
+			return fmt.Sprintf("SELECT %s FROM %s LIMIT 1 OFFSET %d", columnName, tableName, rowOffset)
+		case detector.MSSQL:
+			return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY (SELECT NULL)) as rn FROM %s) x WHERE rn=%d", columnName, columnName, tableName, rowOffset+1)
+		case detector.DB2:
+			if f.legacyPaging() {
+				return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER () rn FROM %s) x WHERE rn=%d", columnName, columnName, tableName, rowOffset+1)
+			}
+			return fmt.Sprintf("SELECT %s FROM %s OFFSET %d ROWS FETCH FIRST 1 ROW ONLY", columnName, tableName, rowOffset)
+		default:
+			return ""
+		}
+	}
+
 	switch f.dbType {
-	case detector.MySQL:
-		return fmt.Sprintf("SELECT %s FROM %s LIMIT 1 OFFSET %d", columnName, tableName, rowOffset)
+	case detector.MySQL, detector.PostgreSQL:
+		return fmt.Sprintf("SELECT %s FROM %s ORDER BY %s LIMIT 1 OFFSET %d", columnName, tableName, orderBy, rowOffset)
 	case detector.MSSQL:
-		return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY (SELECT NULL)) as rn FROM %s) x WHERE rn=%d", columnName, columnName, tableName, rowOffset+1)
-	case detector.PostgreSQL:
-		return fmt.Sprintf("SELECT %s FROM %s LIMIT 1 OFFSET %d", columnName, tableName, rowOffset)
-	case detector.Oracle:
-		return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROWNUM rn FROM %s) WHERE rn=%d", columnName, columnName, tableName, rowOffset+1)
+		return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY %s) as rn FROM %s) x WHERE rn=%d", columnName, columnName, orderBy, tableName, rowOffset+1)
+	case detector.DB2:
+		if f.legacyPaging() {
+			return fmt.Sprintf("SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY %s) rn FROM %s) x WHERE rn=%d", columnName, columnName, orderBy, tableName, rowOffset+1)
+		}
+		return fmt.Sprintf("SELECT %s FROM %s ORDER BY %s OFFSET %d ROWS FETCH FIRST 1 ROW ONLY", columnName, tableName, orderBy, rowOffset)
 	default:
 		return ""
 	}
 }
 
+// oracleRowQuery pages Oracle rows with OFFSET ... FETCH on 12c and later, and
+// ROW_NUMBER() before. ROWNUM is assigned before any ordering, so the row it
+// numbers N can change between probes. Without an ordering column, ROWID keeps
+// the order stable.
+func (f *Finder) oracleRowQuery(tableName, expr, orderBy string, rowOffset int) string {
+	if orderBy == "" {
+		orderBy = "ROWID"
+	}
+	if f.versionMajor >= 12 {
+		return fmt.Sprintf("SELECT %s FROM %s ORDER BY %s OFFSET %d ROWS FETCH NEXT 1 ROWS ONLY", expr, tableName, orderBy, rowOffset)
+	}
+	return fmt.Sprintf("SELECT v FROM (SELECT %s v, ROW_NUMBER() OVER (ORDER BY %s) rn FROM %s) WHERE rn=%d", expr, orderBy, tableName, rowOffset+1)
+}
+
+// getExprQuery returns query to get an expression over a table row.
+// Unlike getCellQuery the expression is aliased, so it can reference any column.
+func (f *Finder) getExprQuery(tableName, expr, orderBy string, rowOffset int) string {
+	tableName, orderBy = f.qualify(tableName), f.quote(orderBy)
+	switch f.dbType {
+	case detector.MSSQL:
+		order := "(SELECT NULL)"
+		if orderBy != "" {
+			order = orderBy
+		}
+		return fmt.Sprintf("SELECT v FROM (SELECT %s AS v, ROW_NUMBER() OVER (ORDER BY %s) as rn FROM %s) x WHERE rn=%d", expr, order, tableName, rowOffset+1)
+	default:
+		return f.rowQuery(tableName, expr, orderBy, rowOffset)
+	}
+}
+
 // getRowCountQuery returns query to count rows in a table
 func (f *Finder) getRowCountQuery(tableName string) string {
-	return fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s", f.qualify(tableName))
 }
 
 // getColumnCountQuery returns query to count columns in a table
 func (f *Finder) getColumnCountQuery(tableName string) string {
-	switch f.dbType {
-	case detector.MySQL:
-		return fmt.Sprintf("SELECT COUNT(*) FROM information_schema.columns WHERE table_schema=database() AND table_name='%s'", tableName)
-	case detector.MSSQL:
-		return fmt.Sprintf("SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS WHERE table_name='%s'", tableName)
-	case detector.PostgreSQL:
-		return fmt.Sprintf("SELECT COUNT(*) FROM information_schema.columns WHERE table_schema='public' AND table_name='%s'", tableName)
-	case detector.Oracle:
-		return fmt.Sprintf("SELECT COUNT(*) FROM user_tab_columns WHERE table_name='%s'", tableName)
-	default:
+	c := f.columnCatalog()
+	if c.name == "" {
 		return ""
 	}
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s%s", c.name, c.filter(fmt.Sprintf("%s='%s'", c.column, tableName)))
 }
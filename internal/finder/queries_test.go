@@ -0,0 +1,35 @@
+package finder
+
+import (
+	"testing"
+
+	"github.com/morkin1792/flatsqli/internal/payloads"
+)
+
+func TestQuotePostgresCatalogNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		exact bool
+		want  string
+	}{
+		{name: "Users", exact: true, want: `"Users"`},
+		{name: "USERS", exact: true, want: `"USERS"`},
+		{name: "users", exact: true, want: `users`},
+		{name: "app.OrderItems", exact: true, want: `app."OrderItems"`},
+		{name: "USERS", exact: false, want: `USERS`},
+		{name: "Users", exact: false, want: `"Users"`},
+	}
+
+	for _, tt := range tests {
+		f := &Finder{
+			payloadGen: payloads.GetPayloadsForDatabase(payloads.PostgreSQL),
+			exact:      make(map[string]bool),
+		}
+		if tt.exact {
+			f.exactName(tt.name)
+		}
+		if got := f.quote(tt.name); got != tt.want {
+			t.Errorf("quote(%q), exact %v = %s, want %s", tt.name, tt.exact, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package finder
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultTemplate renders the markdown output. Its "table" template also
+// renders each table of the streamed output, so both stay the same.
+//
+//go:embed templates/report.md.tmpl
+var defaultTemplate string
+
+// defaultReport is defaultTemplate, parsed
+var defaultReport = template.Must(LoadTemplate(""))
+
+// Report is the data passed to output templates
+type Report struct {
+	Target    string // METHOD scheme://host/path
+	Database  string // database type
+	Version   string // database version, if known
+	Generated time.Time
+	Tables    []TableData
+}
+
+// templateFuncs are the helpers available to output templates
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"rowCount": func(table TableData) string {
+		return formatRowCount(table.RowCount, table.Exact)
+	},
+	"separators": func(columns []string) string {
+		separators := make([]string, len(columns))
+		for i := range separators {
+			separators[i] = "---"
+		}
+		return strings.Join(separators, " | ")
+	},
+	// cells joins the values of a row, padded to the columns
+	"cells": func(columns, row []string) string {
+		values := make([]string, len(columns))
+		copy(values, row)
+		return strings.Join(values, " | ")
+	},
+}
+
+// LoadTemplate parses an output template file, or the default template when path is empty
+func LoadTemplate(path string) (*template.Template, error) {
+	text := defaultTemplate
+	name := "default"
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		text = string(data)
+		name = path
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// SetTemplate renders the output file with tmpl once extraction ends, instead of
+// streaming markdown. report carries the target info, tables are filled in later.
+func (f *Finder) SetTemplate(tmpl *template.Template, report Report) {
+	f.template = tmpl
+	f.report = report
+}
+
+// streamsMarkdown reports whether markdown output is written as rows are extracted
+func (f *Finder) streamsMarkdown() bool {
+	return f.outputFormat != OutputCSV && f.template == nil
+}
+
+// writeReport renders the configured template with the extracted tables
+func (f *Finder) writeReport(outputPath string, tables []TableData) error {
+	report := f.report
+	report.Generated = time.Now()
+	report.Tables = tables
+
+	file, _, err := openOutputFile(outputPath, f.appendOutput)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return f.template.Execute(file, report)
+}
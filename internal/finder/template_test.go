@@ -0,0 +1,69 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultTemplateMatchesStreamedMarkdown(t *testing.T) {
+	tables := []TableData{
+		{
+			TableName: "users",
+			Columns:   []string{"id", "login", "password"},
+			Rows:      [][]string{{"1", "admin", "5f4dcc3b"}, {"2", "guest"}},
+			RowCount:  1500,
+		},
+		{
+			TableName: "logs",
+			Columns:   []string{"message"},
+			Rows:      [][]string{{"started"}},
+		},
+	}
+	want := `# FlatSQLi Extraction Results
+
+## users
+
+* **Rows:** +1K
+* **Dumped Rows:** 2
+
+| id | login | password |
+| --- | --- | --- |
+| 1 | admin | 5f4dcc3b |
+| 2 | guest |  |
+
+## logs
+
+* **Rows:** 1
+
+| message |
+| --- |
+| started |
+
+`
+
+	dir := t.TempDir()
+	streamed := filepath.Join(dir, "streamed.md")
+	if err := InitOutputFile(streamed, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range tables {
+		if err := AppendTableToOutput(streamed, table); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rendered := filepath.Join(dir, "rendered.md")
+	if err := WriteOutputFile(rendered, tables); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{streamed, rendered} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s:\n%s\nwant:\n%s", filepath.Base(path), got, want)
+		}
+	}
+}
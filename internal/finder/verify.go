@@ -0,0 +1,63 @@
+package finder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+// defaultSelfCheck re-verifies one of every N binary-searched characters
+const defaultSelfCheck = 8
+
+// SetSelfCheck sets how often a binary-searched character is re-verified with an
+// equality probe: every nth one, starting with the first (0 = never)
+func (f *Finder) SetSelfCheck(n int) {
+	if n < 0 {
+		n = 0
+	}
+	f.selfCheck = n
+}
+
+// shouldVerify reports whether the searched-th binary-searched char gets checked
+func (f *Finder) shouldVerify(searched int) bool {
+	return f.selfCheck > 0 && (searched-1)%f.selfCheck == 0
+}
+
+// verifyChar confirms a binary-searched character with an equality probe. On a
+// contradiction (e.g. a probe whose difference vanished) the character is searched
+// again; if the new result is not confirmed either it is reported as uncertain.
+func (f *Finder) verifyChar(query string, pos int, char rune) (rune, bool, error) {
+	// Equality is on ASCII(), which only covers single-byte chars
+	if f.codePoints() && char > 127 {
+		return char, true, nil
+	}
+
+	confirmed, err := f.calibration.Probe(f.requester, f.payloadGen.GetEqualityPayload(query, pos, int(char)))
+	if err != nil || confirmed {
+		return char, true, err
+	}
+
+	ui.Verbose(f.verbose, "Self-check failed for char %d (%q), searching again", pos, char)
+	retry, err := f.findChar(query, pos)
+	if err != nil {
+		return char, false, err
+	}
+	confirmed, err = f.calibration.Probe(f.requester, f.payloadGen.GetEqualityPayload(query, pos, int(retry)))
+	if err != nil {
+		return retry, false, err
+	}
+	return retry, confirmed, nil
+}
+
+// markUncertain annotates a value with the positions of characters that failed the self-check
+func markUncertain(value string, positions []int) string {
+	if len(positions) == 0 {
+		return value
+	}
+	marks := make([]string, len(positions))
+	for i, pos := range positions {
+		marks[i] = fmt.Sprintf("%d", pos)
+	}
+	return fmt.Sprintf("%s [uncertain chars: %s]", value, strings.Join(marks, ","))
+}
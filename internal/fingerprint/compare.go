@@ -0,0 +1,119 @@
+package fingerprint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompareMode is how strictly two fingerprints must agree to be equal
+type CompareMode int
+
+const (
+	// CompareAuto is the default: status, then word count, then content length within 5%
+	CompareAuto CompareMode = iota
+	// CompareStatus only compares status codes (IsSimilar)
+	CompareStatus
+	// CompareWordCount compares status and exact word count
+	CompareWordCount
+	// CompareLength compares status and exact content length
+	CompareLength
+	// CompareBodyHash compares status and the exact body
+	CompareBodyHash
+)
+
+// String returns the name used by -compare-mode
+func (m CompareMode) String() string {
+	switch m {
+	case CompareStatus:
+		return "status-only"
+	case CompareWordCount:
+		return "wordcount"
+	case CompareLength:
+		return "content-length"
+	case CompareBodyHash:
+		return "body-hash"
+	default:
+		return "auto"
+	}
+}
+
+// ParseCompareMode parses a -compare-mode name
+func ParseCompareMode(name string) (CompareMode, error) {
+	for _, m := range []CompareMode{CompareAuto, CompareStatus, CompareWordCount, CompareLength, CompareBodyHash} {
+		if strings.EqualFold(name, m.String()) {
+			return m, nil
+		}
+	}
+	return CompareAuto, fmt.Errorf("unknown compare mode %q (auto, status-only, wordcount, content-length, body-hash)", name)
+}
+
+// EqualsMode compares two fingerprints with the given strictness. The match
+// string and the watched header are explicit user signals and are checked in
+// every mode; timing and strict word sets are opt-in refinements of the body
+// comparison and are skipped by status-only.
+func (f *Fingerprint) EqualsMode(other *Fingerprint, mode CompareMode) bool {
+	if f == nil || other == nil {
+		return false
+	}
+
+	// If match string was used, it takes priority
+	if f.ContainsMatchString != other.ContainsMatchString {
+		return false
+	}
+
+	// A watched response header tells states apart even with identical bodies
+	if f.UseHeader && other.UseHeader && f.Header != other.Header {
+		return false
+	}
+
+	if mode == CompareStatus {
+		return f.IsSimilar(other)
+	}
+
+	// Primary check: status code
+	if f.StatusCode != other.StatusCode {
+		return false
+	}
+
+	// Timing check (opt-in): responses more than one duration class apart
+	// differ, neighbouring classes are jitter across a bucket bound
+	if f.UseTiming && other.UseTiming && abs(f.DurationClass()-other.DurationClass()) > 1 {
+		return false
+	}
+
+	// Strict check (opt-in): same word count but different words still differ
+	if f.UseWordSet && other.UseWordSet && f.WordSetHash != other.WordSetHash {
+		return false
+	}
+
+	switch mode {
+	case CompareWordCount:
+		return f.WordCount == other.WordCount
+	case CompareLength:
+		return f.ContentLength == other.ContentLength
+	case CompareBodyHash:
+		return f.BodyHash == other.BodyHash
+	}
+
+	// Secondary check: word count (exact match)
+	if f.WordCount == other.WordCount {
+		return true
+	}
+
+	// Tertiary check: content length within tolerance (5%)
+	tolerance := float64(f.ContentLength) * 0.05
+	diff := float64(f.ContentLength - other.ContentLength)
+	if diff < 0 {
+		diff = -diff
+	}
+
+	return diff <= tolerance
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,66 @@
+package fingerprint
+
+// maxEditWindow bounds the differing region Ratio computes an exact edit
+// distance over, the cost is quadratic. Larger regions fall back to comparing
+// byte counts, like difflib's quick_ratio.
+const maxEditWindow = 2048
+
+// Ratio returns how similar two bodies are, from 0 (nothing in common) to 1
+// (identical): one minus their edit distance over the longer length. The common
+// prefix and suffix are skipped first, so a large page with a small change
+// stays cheap to compare.
+func Ratio(a, b []byte) float64 {
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 1
+	}
+
+	start, endA := differingRange(a, b)
+	endB := len(b) - (len(a) - endA)
+	midA, midB := a[start:endA], b[start:endB]
+
+	var distance int
+	if len(midA) <= maxEditWindow && len(midB) <= maxEditWindow {
+		distance = editDistance(midA, midB)
+	} else {
+		distance = max(len(midA), len(midB)) - commonBytes(midA, midB)
+	}
+	return 1 - float64(distance)/float64(longest)
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b []byte) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// commonBytes counts the bytes a and b have in common, regardless of order
+func commonBytes(a, b []byte) int {
+	var counts [256]int
+	for _, c := range a {
+		counts[c]++
+	}
+	common := 0
+	for _, c := range b {
+		if counts[c] > 0 {
+			counts[c]--
+			common++
+		}
+	}
+	return common
+}
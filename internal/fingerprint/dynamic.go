@@ -0,0 +1,145 @@
+package fingerprint
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// dynamicAnchor is how much stable text around a dynamic span locates it
+const dynamicAnchor = 20
+
+// DynamicSpan is a region of a page that changes between identical requests
+// (CSRF tokens, timestamps, ads...), located by the stable text around it
+type DynamicSpan struct {
+	Prefix     string
+	Suffix     string
+	SingleLine bool // found within a line, the span never runs past it
+}
+
+// FindDynamicContent compares two responses to the same request and returns the
+// spans that differ. Pages with the same number of lines are compared line by
+// line, otherwise everything between the common prefix and suffix is dynamic.
+func FindDynamicContent(first, second []byte) []DynamicSpan {
+	if bytes.Equal(first, second) {
+		return nil
+	}
+
+	firstLines := bytes.SplitAfter(first, []byte("\n"))
+	secondLines := bytes.SplitAfter(second, []byte("\n"))
+	if len(firstLines) != len(secondLines) {
+		start, end := differingRange(first, second)
+		return spanAt(first, start, end)
+	}
+
+	var spans []DynamicSpan
+	offset := 0
+	for i, line := range firstLines {
+		if !bytes.Equal(line, secondLines[i]) {
+			start, end := differingRange(line, secondLines[i])
+			for _, span := range spanAt(first, offset+start, offset+end) {
+				span.SingleLine = true
+				spans = append(spans, span)
+			}
+		}
+		offset += len(line)
+	}
+	return spans
+}
+
+// differingRange returns the bounds, in a, of what lies between the common
+// prefix and suffix of a and b
+func differingRange(a, b []byte) (int, int) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	return prefix, len(a) - suffix
+}
+
+// spanAt anchors body[start:end] with the text around it. The span is widened
+// to whole words (a token differing in its last char is still one token), and
+// anchors stay on its line when possible. A span touching both ends of the body
+// can't be anchored and is dropped.
+func spanAt(body []byte, start, end int) []DynamicSpan {
+	for start > 0 && isWordByte(body[start-1]) {
+		start--
+	}
+	for end < len(body) && isWordByte(body[end]) {
+		end++
+	}
+
+	prefix := body[max(0, start-dynamicAnchor):start]
+	if i := bytes.LastIndexByte(prefix, '\n'); i >= 0 && i < len(prefix)-1 {
+		prefix = prefix[i+1:]
+	}
+	suffix := body[end:min(len(body), end+dynamicAnchor)]
+	if i := bytes.IndexByte(suffix, '\n'); i > 0 {
+		suffix = suffix[:i]
+	}
+
+	span := DynamicSpan{Prefix: string(prefix), Suffix: string(suffix)}
+	if span.Prefix == "" && span.Suffix == "" {
+		return nil
+	}
+	return []DynamicSpan{span}
+}
+
+// isWordByte reports whether c is part of a token (letters, digits, _ and -)
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// DynamicFilter removes dynamic spans from response bodies, so only the stable
+// region is fingerprinted. A nil filter leaves bodies unchanged.
+type DynamicFilter struct {
+	patterns     []*regexp.Regexp
+	replacements [][]byte
+}
+
+// NewDynamicFilter compiles the spans found by FindDynamicContent
+func NewDynamicFilter(spans []DynamicSpan) *DynamicFilter {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	f := &DynamicFilter{}
+	for _, span := range spans {
+		// Without a suffix the span runs to the end of the body (of the line
+		// for spans found within one)
+		gap := `(?s:.*?)`
+		switch {
+		case span.SingleLine && span.Suffix == "":
+			gap = `[^\n]*`
+		case span.SingleLine:
+			gap = `[^\n]*?`
+		case span.Suffix == "":
+			gap = `(?s:.*)`
+		}
+		f.patterns = append(f.patterns, regexp.MustCompile(regexp.QuoteMeta(span.Prefix)+gap+regexp.QuoteMeta(span.Suffix)))
+		f.replacements = append(f.replacements, []byte(span.Prefix+span.Suffix))
+	}
+	return f
+}
+
+// Len returns the number of dynamic spans
+func (f *DynamicFilter) Len() int {
+	if f == nil {
+		return 0
+	}
+	return len(f.patterns)
+}
+
+// Remove blanks the dynamic spans of body, keeping their anchors
+func (f *DynamicFilter) Remove(body []byte) []byte {
+	if f == nil {
+		return body
+	}
+	for i, pattern := range f.patterns {
+		body = pattern.ReplaceAllLiteral(body, f.replacements[i])
+	}
+	return body
+}
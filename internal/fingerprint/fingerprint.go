@@ -3,9 +3,20 @@ package fingerprint
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"sort"
 	"strings"
+	"time"
 )
 
+// durationBuckets are the upper bounds of each duration class
+var durationBuckets = []time.Duration{
+	500 * time.Millisecond,
+	1 * time.Second,
+	2 * time.Second,
+	4 * time.Second,
+	8 * time.Second,
+}
+
 // Fingerprint represents response characteristics for comparison
 type Fingerprint struct {
 	StatusCode          int
@@ -13,7 +24,15 @@ type Fingerprint struct {
 	WordCount           int
 	LineCount           int
 	BodyHash            string
-	ContainsMatchString bool // True if the match string was found in response
+	ContainsMatchString bool          // True if the match string was found in response
+	Duration            time.Duration // Response time, only compared when UseTiming is set
+	UseTiming           bool          // Compare duration classes in Equals
+	WordSetHash         string        // Hash of the sorted unique words
+	UseWordSet          bool          // Compare word sets in Equals (strict mode)
+	Header              string        // State of the -match-header response header
+	UseHeader           bool          // Compare header states in Equals
+	Truncated           bool          // Body was cut at the size limit (-max-body-size)
+	Body                []byte        // Normalized body, only kept for content-diff comparison (-diff-mode)
 }
 
 // New creates a fingerprint from response data
@@ -39,38 +58,24 @@ func NewWithMatchString(statusCode int, body []byte, matchString string) *Finger
 		LineCount:           countLines(bodyStr),
 		BodyHash:            hex.EncodeToString(hash[:]),
 		ContainsMatchString: containsMatch,
+		WordSetHash:         hashWordSet(bodyStr),
 	}
 }
 
-// Equals checks if two fingerprints are effectively the same
+// Equals checks if two fingerprints are effectively the same (CompareAuto)
 func (f *Fingerprint) Equals(other *Fingerprint) bool {
-	if f == nil || other == nil {
-		return false
-	}
-
-	// If match string was used, it takes priority
-	if f.ContainsMatchString != other.ContainsMatchString {
-		return false
-	}
-
-	// Primary check: status code
-	if f.StatusCode != other.StatusCode {
-		return false
-	}
-
-	// Secondary check: word count (exact match)
-	if f.WordCount == other.WordCount {
-		return true
-	}
+	return f.EqualsMode(other, CompareAuto)
+}
 
-	// Tertiary check: content length within tolerance (5%)
-	tolerance := float64(f.ContentLength) * 0.05
-	diff := float64(f.ContentLength - other.ContentLength)
-	if diff < 0 {
-		diff = -diff
+// DurationClass buckets the response time so small jitter compares equal:
+// 0 (<500ms), 1 (<1s), 2 (<2s), 3 (<4s), 4 (<8s), 5 (>=8s)
+func (f *Fingerprint) DurationClass() int {
+	for i, bound := range durationBuckets {
+		if f.Duration < bound {
+			return i
+		}
 	}
-
-	return diff <= tolerance
+	return len(durationBuckets)
 }
 
 // IsSimilar is a more relaxed comparison
@@ -103,6 +108,9 @@ func (f *Fingerprint) Diff(other *Fingerprint) string {
 	if f.BodyHash != other.BodyHash {
 		diffs = append(diffs, "body content")
 	}
+	if f.Header != other.Header {
+		diffs = append(diffs, "header")
+	}
 
 	if len(diffs) == 0 {
 		return "identical"
@@ -117,6 +125,23 @@ func countWords(s string) int {
 	return len(words)
 }
 
+// hashWordSet hashes the sorted unique words of a string, so responses with the
+// same words in any order or repetition hash the same
+func hashWordSet(s string) string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, word := range strings.Fields(s) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	sort.Strings(words)
+
+	hash := md5.Sum([]byte(strings.Join(words, " ")))
+	return hex.EncodeToString(hash[:])
+}
+
 // countLines counts the number of lines in a string
 func countLines(s string) int {
 	if s == "" {
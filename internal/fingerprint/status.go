@@ -0,0 +1,62 @@
+package fingerprint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusRange is an inclusive range of HTTP status codes
+type StatusRange struct {
+	Low, High int
+}
+
+// StatusRanges is a set of status codes, e.g. parsed from "200,301-302"
+type StatusRanges []StatusRange
+
+// ParseStatusRanges parses comma-separated codes and low-high ranges. An empty
+// string gives an empty set.
+func ParseStatusRanges(s string) (StatusRanges, error) {
+	var ranges StatusRanges
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		low, high, isRange := strings.Cut(part, "-")
+		lowCode, err := parseStatus(low)
+		if err != nil {
+			return nil, err
+		}
+		highCode := lowCode
+		if isRange {
+			if highCode, err = parseStatus(high); err != nil {
+				return nil, err
+			}
+			if highCode < lowCode {
+				return nil, fmt.Errorf("invalid status range %q", part)
+			}
+		}
+		ranges = append(ranges, StatusRange{Low: lowCode, High: highCode})
+	}
+	return ranges, nil
+}
+
+// parseStatus parses a single status code
+func parseStatus(s string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", s)
+	}
+	return code, nil
+}
+
+// Contains reports whether code is in one of the ranges
+func (s StatusRanges) Contains(code int) bool {
+	for _, r := range s {
+		if code >= r.Low && code <= r.High {
+			return true
+		}
+	}
+	return false
+}
@@ -15,23 +15,41 @@ type Writer struct {
 	hasItems       bool
 	headersWritten bool
 	urlBlockOpened bool
+	startSize      int64 // size of the file before this run (append mode)
 }
 
 // New creates a writer for the given path. Returns nil if path is empty.
-func New(path string, isURLInput bool) (*Writer, error) {
+// With appendMode, results are added as a new section after any existing content.
+func New(path string, isURLInput bool, appendMode bool) (*Writer, error) {
 	if path == "" {
 		return nil, nil
 	}
 
-	file, err := os.Create(path)
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendMode {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0644)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create output file: %w", err)
 	}
 
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat output file: %w", err)
+	}
+
 	w := &Writer{
-		file:     file,
-		filePath: path,
-		isURLs:   isURLInput,
+		file:      file,
+		filePath:  path,
+		isURLs:    isURLInput,
+		startSize: info.Size(),
+	}
+
+	// Separate from the previous run's section
+	if w.startSize > 0 {
+		w.writeString("\n")
 	}
 
 	// Write header title only (code block will be opened when first item is written or after headers)
@@ -124,7 +142,8 @@ func (w *Writer) Close() error {
 	return w.file.Close()
 }
 
-// CloseAndCleanup closes the file and deletes it if no results were written
+// CloseAndCleanup closes the file and deletes it if no results were written.
+// An appended file is truncated back to its previous content instead.
 func (w *Writer) CloseAndCleanup() error {
 	if w == nil {
 		return nil
@@ -140,6 +159,9 @@ func (w *Writer) CloseAndCleanup() error {
 
 	// Delete the file if no results were written
 	if !hasItems && filePath != "" {
+		if w.startSize > 0 {
+			return os.Truncate(filePath, w.startSize)
+		}
 		return os.Remove(filePath)
 	}
 	return nil
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+)
+
+// Encodings supported by EncodePayload
+var Encodings = []string{"base64", "hex", "url", "double-url"}
+
+// ValidateEncoding returns an error if encoding is not supported ("" means none)
+func ValidateEncoding(encoding string) error {
+	if encoding == "" {
+		return nil
+	}
+	for _, e := range Encodings {
+		if e == encoding {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported encoding: %s (use base64, hex, url or double-url)", encoding)
+}
+
+// EncodePayload encodes the substituted value for parameters that are decoded
+// by the application before reaching the SQL layer. The whole value is encoded,
+// so base64 and hex only make sense when the marker is the entire parameter value
+// (put any prefix such as 1' AND inside the payload, not around the marker).
+// The usual URL escaping of markers in the request line is still applied afterwards.
+func EncodePayload(payload, encoding string) string {
+	switch encoding {
+	case "base64":
+		return base64.StdEncoding.EncodeToString([]byte(payload))
+	case "hex":
+		return hex.EncodeToString([]byte(payload))
+	case "url":
+		return url.QueryEscape(payload)
+	case "double-url":
+		return url.QueryEscape(url.QueryEscape(payload))
+	default:
+		return payload
+	}
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// namedMarker matches named markers such as <INJECT:cols>, which are replaced
+// independently of each other and of the unnamed marker
+var namedMarker = regexp.MustCompile(`<(?:PAYLOAD|FUZZ|INJECT):([A-Za-z0-9_-]+)>`)
+
+// escapedMarker matches a marker URL-encoded by url.URL (e.g. in the path)
+var escapedMarker = regexp.MustCompile(`(?i)%3C((?:PAYLOAD|FUZZ|INJECT)(?::[A-Za-z0-9_-]+)?)%3E`)
+
+// NamedMarkers returns the names of the named markers in the request, in order
+// of first appearance
+func (p *ParsedRequest) NamedMarkers() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range namedMarker.FindAllStringSubmatch(p.RawRequest, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// BuildRequestMulti creates a new ParsedRequest with each named marker replaced
+// by values[name] and the unnamed marker by values[""]. Every named marker must
+// have a value. Payloads get the same encoding and request line escaping as
+// with BuildRequest.
+func (p *ParsedRequest) BuildRequestMulti(values map[string]string) (*ParsedRequest, error) {
+	raw := p.RawRequest
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range namedMarker.FindAllStringSubmatchIndex(raw, -1) {
+		name := raw[loc[2]:loc[3]]
+		value, ok := values[name]
+		if !ok {
+			return nil, fmt.Errorf("no value for marker %s", raw[loc[0]:loc[1]])
+		}
+		value = p.escapeAt(loc[0], EncodePayload(value, p.Encoding))
+		b.WriteString(raw[last:loc[0]])
+		b.WriteString(value)
+		last = loc[1]
+	}
+	b.WriteString(raw[last:])
+
+	named := p.Clone()
+	named.RawRequest = b.String()
+	if named.MarkerType == "" {
+		newReq, err := ParseRequest(named.RawRequest)
+		if err != nil {
+			return nil, err
+		}
+		newReq.Scheme = p.Scheme
+		return newReq, nil
+	}
+	return named.BuildRequest(values[""])
+}
@@ -3,9 +3,10 @@ package parser
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
-	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -23,6 +24,8 @@ type ParsedRequest struct {
 	RawRequest     string
 	MarkerPosition int
 	MarkerType     string
+	SingleMarker   bool   // Replace only the first marker occurrence
+	Encoding       string // Encoding applied to the payload, see EncodePayload
 }
 
 // ParseRequestFile reads and parses an HTTP request from a file
@@ -35,6 +38,16 @@ func ParseRequestFile(filepath string) (*ParsedRequest, error) {
 	return ParseRequest(string(content))
 }
 
+// ParseRequestReader parses a raw HTTP request read from r (e.g. stdin)
+func ParseRequestReader(r io.Reader) (*ParsedRequest, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request: %w", err)
+	}
+
+	return ParseRequest(string(content))
+}
+
 // ParseRequest parses a raw HTTP request string
 func ParseRequest(raw string) (*ParsedRequest, error) {
 	// Normalize line endings
@@ -56,6 +69,12 @@ func ParseRequest(raw string) (*ParsedRequest, error) {
 			break
 		}
 	}
+	// Named markers alone (see BuildRequestMulti) also make the request injectable
+	if req.MarkerPosition == -1 {
+		if loc := namedMarker.FindStringIndex(raw); loc != nil {
+			req.MarkerPosition = loc[0]
+		}
+	}
 
 	// Split into lines
 	lines := strings.Split(raw, "\n")
@@ -104,6 +123,10 @@ func ParseRequest(raw string) (*ParsedRequest, error) {
 	}
 
 	req.Body = strings.Join(bodyLines, "\n")
+	if req.isChunked() {
+		// The body is re-sent with a fixed length, without the chunk framing
+		req.Body = decodeChunked(req.Body)
+	}
 
 	// Try to determine scheme from URL or default
 	if strings.HasPrefix(req.Path, "http://") {
@@ -129,35 +152,108 @@ func ParseRequest(raw string) (*ParsedRequest, error) {
 	return req, nil
 }
 
-// ReplaceMarker replaces the marker in the raw request with the given payload
+// isChunked reports whether the request has a chunked Transfer-Encoding
+func (p *ParsedRequest) isChunked() bool {
+	for key, value := range p.Headers {
+		if strings.EqualFold(key, "Transfer-Encoding") && strings.Contains(strings.ToLower(value), "chunked") {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeChunked removes the chunk framing of a body. The chunk sizes no longer
+// match once the marker is replaced, so a chunk takes the lines after its size
+// line until they hold at least that many bytes (line breaks counted as CRLF).
+// Bodies that don't start with a chunk size are returned as they are.
+func decodeChunked(body string) string {
+	lines := strings.Split(body, "\n")
+	var decoded strings.Builder
+	for i := 0; i < len(lines); i++ {
+		sizeField, _, _ := strings.Cut(strings.TrimSpace(lines[i]), ";")
+		size, err := strconv.ParseInt(sizeField, 16, 64)
+		if err != nil {
+			return body
+		}
+		if size == 0 {
+			break // only trailers follow
+		}
+		read := 0
+		for first := true; int64(read) < size && i+1 < len(lines); first = false {
+			i++
+			if !first {
+				decoded.WriteString("\n")
+				read += 2
+			}
+			decoded.WriteString(lines[i])
+			read += len(lines[i])
+		}
+	}
+	return decoded.String()
+}
+
+// ReplaceMarker replaces the marker in the raw request with the given payload.
+// Every occurrence of the marker gets the same payload (e.g. for contexts like
+// "... AND <INJECT> ... OR <INJECT> ..."), unless SingleMarker is set, in which
+// case only the first occurrence is replaced. The payload is encoded with
+// Encoding first.
 func (p *ParsedRequest) ReplaceMarker(payload string) string {
 	if p.MarkerType == "" {
 		return p.RawRequest
 	}
+	payload = EncodePayload(payload, p.Encoding)
+
+	var b strings.Builder
+	rest := p.RawRequest
+	offset := 0
+	replaced := 0
+	for {
+		idx := strings.Index(rest, p.MarkerType)
+		if idx == -1 || (p.SingleMarker && replaced > 0) {
+			b.WriteString(rest)
+			break
+		}
+
+		b.WriteString(rest[:idx])
+		b.WriteString(p.escapeAt(offset+idx, payload))
 
-	// URL-encode the payload if the marker is in the URL (first line)
-	encodedPayload := payload
-	if p.isMarkerInURL() {
-		encodedPayload = url.QueryEscape(payload)
+		rest = rest[idx+len(p.MarkerType):]
+		offset += idx + len(p.MarkerType)
+		replaced++
 	}
 
-	// Escape special regex characters if marker contains them
-	escapedMarker := regexp.QuoteMeta(p.MarkerType)
-	re := regexp.MustCompile(escapedMarker)
+	return b.String()
+}
+
+// MarkerCount returns how many times the marker appears in the request
+func (p *ParsedRequest) MarkerCount() int {
+	if p.MarkerType == "" {
+		return 0
+	}
+	return strings.Count(p.RawRequest, p.MarkerType)
+}
 
-	// Replace only the first occurrence
-	return re.ReplaceAllStringFunc(p.RawRequest, func(match string) string {
-		return encodedPayload
-	})
+// escapeAt URL-encodes a payload replacing a marker at offset when the marker
+// is in the URL (first line): path escaping in the path, where + is a literal
+// plus, and query escaping after the ?. Elsewhere the payload is kept as is.
+func (p *ParsedRequest) escapeAt(offset int, payload string) string {
+	firstLineEnd := p.firstLineEnd()
+	if offset >= firstLineEnd {
+		return payload
+	}
+	if query := strings.Index(p.RawRequest[:firstLineEnd], "?"); query == -1 || offset < query {
+		return url.PathEscape(payload)
+	}
+	return url.QueryEscape(payload)
 }
 
-// isMarkerInURL checks if the marker is in the URL (first line of request)
-func (p *ParsedRequest) isMarkerInURL() bool {
+// firstLineEnd returns the offset of the end of the request line
+func (p *ParsedRequest) firstLineEnd() int {
 	firstLineEnd := strings.Index(p.RawRequest, "\n")
 	if firstLineEnd == -1 {
 		firstLineEnd = len(p.RawRequest)
 	}
-	return p.MarkerPosition < firstLineEnd && p.MarkerPosition >= 0
+	return firstLineEnd
 }
 
 // GetTargetURL returns the full target URL
@@ -182,6 +278,8 @@ func (p *ParsedRequest) Clone() *ParsedRequest {
 		RawRequest:     p.RawRequest,
 		MarkerPosition: p.MarkerPosition,
 		MarkerType:     p.MarkerType,
+		SingleMarker:   p.SingleMarker,
+		Encoding:       p.Encoding,
 	}
 }
 
@@ -269,8 +367,60 @@ func ParseRequestsDirectory(dirpath string) ([]*ParsedRequest, error) {
 	return requests, nil
 }
 
+// ParseURLLine splits a URL file line into method, URL and body.
+// Lines are either a bare URL or "METHOD URL [BODY]", e.g.
+// "POST https://host/path a=1&q=2". The method is empty for bare URLs.
+func ParseURLLine(line string) (method, rawURL, body string) {
+	fields := strings.SplitN(strings.TrimSpace(line), " ", 3)
+	if len(fields) >= 2 && isHTTPMethod(fields[0]) {
+		method = strings.ToUpper(fields[0])
+		rawURL = fields[1]
+		if len(fields) == 3 {
+			body = strings.TrimSpace(fields[2])
+		}
+		return method, rawURL, body
+	}
+	return "", strings.TrimSpace(line), ""
+}
+
+// isHTTPMethod reports whether s is a known HTTP method
+func isHTTPMethod(s string) bool {
+	switch strings.ToUpper(s) {
+	case "GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "HEAD":
+		return true
+	}
+	return false
+}
+
 // URLToRequest converts a URL string to a ParsedRequest for scanning
 func URLToRequest(rawURL string) (*ParsedRequest, error) {
+	return URLToRequestWithBody(rawURL, "GET", "")
+}
+
+// RequestFromURL builds an injectable request from a URL and an optional body
+// (exploit -u/-data). Markers are found as in a request file, even when the URL
+// parser escaped them.
+func RequestFromURL(rawURL, method, body string) (*ParsedRequest, error) {
+	base, err := URLToRequestWithBody(rawURL, method, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := ParseRequest(escapedMarker.ReplaceAllString(base.RawRequest, "<$1>"))
+	if err != nil {
+		return nil, err
+	}
+	req.Scheme = base.Scheme
+	return req, nil
+}
+
+// URLToRequestWithBody converts a URL string to a ParsedRequest with the given
+// method and body. Requests with a body default to form-urlencoded content.
+func URLToRequestWithBody(rawURL, method, body string) (*ParsedRequest, error) {
+	if method == "" {
+		method = "GET"
+	}
+
 	// Ensure URL has scheme
 	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
 		rawURL = "https://" + rawURL
@@ -291,16 +441,21 @@ func URLToRequest(rawURL string) (*ParsedRequest, error) {
 	}
 
 	// Build a minimal raw request
-	rawRequest := fmt.Sprintf("GET %s HTTP/1.1\nHost: %s\nUser-Agent: flatsqli/1.0\nAccept: */*\nConnection: close\n",
-		path, parsedURL.Host)
+	headers := map[string]string{"Host": parsedURL.Host, "User-Agent": "flatsqli/1.0", "Accept": "*/*", "Connection": "close"}
+	rawRequest := fmt.Sprintf("%s %s HTTP/1.1\nHost: %s\nUser-Agent: flatsqli/1.0\nAccept: */*\nConnection: close\n",
+		method, path, parsedURL.Host)
+	if body != "" {
+		headers["Content-Type"] = "application/x-www-form-urlencoded"
+		rawRequest += "Content-Type: application/x-www-form-urlencoded\n\n" + body
+	}
 
 	return &ParsedRequest{
-		Method:         "GET",
+		Method:         method,
 		Scheme:         parsedURL.Scheme,
 		Host:           parsedURL.Host,
 		Path:           path,
-		Headers:        map[string]string{"Host": parsedURL.Host, "User-Agent": "flatsqli/1.0", "Accept": "*/*", "Connection": "close"},
-		Body:           "",
+		Headers:        headers,
+		Body:           body,
 		RawRequest:     rawRequest,
 		MarkerPosition: -1,
 	}, nil
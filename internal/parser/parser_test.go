@@ -0,0 +1,39 @@
+package parser
+
+import "testing"
+
+func TestReplaceMarker(t *testing.T) {
+	raw := "POST /search?q=<INJECT> HTTP/1.1\nHost: example.com\n\nname=x' AND <INJECT> OR <INJECT>"
+
+	tests := []struct {
+		name         string
+		singleMarker bool
+		want         string
+	}{
+		{
+			name: "every marker",
+			want: "POST /search?q=1%3D1 HTTP/1.1\nHost: example.com\n\nname=x' AND 1=1 OR 1=1",
+		},
+		{
+			name:         "single marker",
+			singleMarker: true,
+			want:         "POST /search?q=1%3D1 HTTP/1.1\nHost: example.com\n\nname=x' AND <INJECT> OR <INJECT>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := ParseRequest(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := req.MarkerCount(); got != 3 {
+				t.Fatalf("MarkerCount() = %d, want 3", got)
+			}
+			req.SingleMarker = tt.singleMarker
+			if got := req.ReplaceMarker("1=1"); got != tt.want {
+				t.Errorf("ReplaceMarker() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package payloads
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Charset selects the character range searched during extraction
+type Charset int
+
+const (
+	// CharsetASCII searches printable ASCII (32-126)
+	CharsetASCII Charset = iota
+	// CharsetLatin1 searches the code points of printable ASCII plus the Latin-1 range (32-255)
+	CharsetLatin1
+	// CharsetBytes searches the full byte range and goes on above it for multibyte chars
+	CharsetBytes
+)
+
+// MaxCodePoint is the highest Unicode code point
+const MaxCodePoint = 0x10FFFF
+
+// ParseCharset parses a charset name (ascii, latin1, bytes)
+func ParseCharset(s string) (Charset, error) {
+	switch strings.ToLower(s) {
+	case "", "ascii":
+		return CharsetASCII, nil
+	case "latin1", "iso-8859-1":
+		return CharsetLatin1, nil
+	case "bytes", "byte", "utf8":
+		return CharsetBytes, nil
+	default:
+		return CharsetASCII, fmt.Errorf("unknown charset: %s (supported: ascii, latin1, bytes)", s)
+	}
+}
+
+// String returns the charset name
+func (c Charset) String() string {
+	switch c {
+	case CharsetLatin1:
+		return "latin1"
+	case CharsetBytes:
+		return "bytes"
+	default:
+		return "ascii"
+	}
+}
+
+// Bounds returns the binary-search bounds for a character
+func (c Charset) Bounds() (int, int) {
+	switch c {
+	case CharsetLatin1:
+		return 32, 255
+	case CharsetBytes:
+		return 0, 255
+	default:
+		return 32, 126 // Printable ASCII
+	}
+}
+
+// UsesCodePoints reports whether characters should be probed with GetCodePayload.
+// ASCII() reads the first byte of a multibyte char on MySQL, so even the Latin-1
+// range needs code points.
+func (c Charset) UsesCodePoints() bool {
+	return c != CharsetASCII
+}
+
+// Multibyte reports whether characters may be above the Bounds range
+func (c Charset) Multibyte() bool {
+	return c == CharsetBytes
+}
+
+// ReadsCodePoints reports whether GetCodePayload reads code points on the
+// database. DB2 has no such function: characters are read as bytes there, and
+// the value is decoded as UTF-8 with DecodeBytes.
+func ReadsCodePoints(gen DatabasePayloads) bool {
+	return gen.GetType() != DB2
+}
+
+// LengthPayload returns a payload to check if the length of the query result
+// is above n, in characters when they are read as code points: LENGTH() counts
+// bytes on MySQL
+func (c Charset) LengthPayload(gen DatabasePayloads, query string, n int) string {
+	if c.UsesCodePoints() && gen.GetType() == MySQL {
+		return fmt.Sprintf("CHAR_LENGTH((%s))>%d", query, n)
+	}
+	return gen.GetLengthPayload(query, n)
+}
+
+// DecodeBytes decodes a value read byte by byte (one rune per byte) as UTF-8.
+// Values that aren't valid UTF-8 are returned as they are.
+func DecodeBytes(value string) string {
+	raw := make([]byte, 0, len(value))
+	for _, r := range value {
+		if r > 0xFF {
+			return value
+		}
+		raw = append(raw, byte(r))
+	}
+	if !utf8.Valid(raw) {
+		return value
+	}
+	return string(raw)
+}
+
+// ValueCharset is the sorted set of characters values are known to be made of,
+// searched instead of the whole Charset range (empty = no hint)
+type ValueCharset string
+
+// valueCharsets are the sets available to -value-charset
+var valueCharsets = map[string]ValueCharset{
+	"hex":    "0123456789ABCDEFabcdef",
+	"digits": "0123456789",
+	"lower":  "abcdefghijklmnopqrstuvwxyz",
+	"upper":  "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"alnum":  "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+}
+
+// ParseValueCharset parses a value charset name (hex, digits, lower, upper, alnum)
+func ParseValueCharset(s string) (ValueCharset, error) {
+	if s == "" {
+		return "", nil
+	}
+	set, ok := valueCharsets[strings.ToLower(s)]
+	if !ok {
+		return "", fmt.Errorf("unknown value charset: %s (supported: hex, digits, lower, upper, alnum)", s)
+	}
+	return set, nil
+}
+
+// Search binary searches a character of the set, greater(n) reporting whether
+// the character code is above n. A character outside the set comes out as a
+// neighbour from the set, so this only fits values known to match it.
+func (v ValueCharset) Search(greater func(n int) (bool, error)) (rune, error) {
+	low, high := 0, len(v)-1
+	for low < high {
+		mid := (low + high + 1) / 2
+		isTrue, err := greater(int(v[mid-1]))
+		if err != nil {
+			return 0, err
+		}
+
+		if isTrue {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return rune(v[low]), nil
+}
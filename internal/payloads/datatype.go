@@ -0,0 +1,85 @@
+package payloads
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DataType is what the marker stands for in the query, which decides how a
+// boolean condition must be wrapped to be evaluated there
+type DataType int
+
+const (
+	// DataCondition means the marker already sits where a condition is evaluated
+	DataCondition DataType = iota
+	// DataNumeric means the marker replaces an unquoted number, e.g. id=<PAYLOAD>
+	DataNumeric
+	// DataString means the marker is inside a quoted literal, e.g. name='<PAYLOAD>'
+	DataString
+	// DataNumericOr is DataNumeric with an OR form that matches no row by itself,
+	// for values matching no row either. Only on request (-data-type numeric-or):
+	// in an UPDATE or DELETE, a TRUE condition hits every row.
+	DataNumericOr
+	// DataStringOr is the OR form of DataString, see DataNumericOr
+	DataStringOr
+)
+
+// defaultDataValue stands for the original value of a numeric marker when it is
+// not known, an id most tables have
+const defaultDataValue = "1"
+
+// String returns the name used by -data-type
+func (d DataType) String() string {
+	switch d {
+	case DataNumeric:
+		return "numeric"
+	case DataString:
+		return "string"
+	case DataNumericOr:
+		return "numeric-or"
+	case DataStringOr:
+		return "string-or"
+	default:
+		return "condition"
+	}
+}
+
+// ParseDataType parses a -data-type name
+func ParseDataType(name string) (DataType, error) {
+	for _, d := range []DataType{DataCondition, DataNumeric, DataString, DataNumericOr, DataStringOr} {
+		if strings.EqualFold(name, d.String()) {
+			return d, nil
+		}
+	}
+	return DataCondition, fmt.Errorf("unknown data type %q (condition, numeric, string, numeric-or, string-or)", name)
+}
+
+// UsesOR reports whether the form selects rows with OR, which makes a TRUE
+// condition match every row of the table
+func (d DataType) UsesOR() bool {
+	return d == DataNumericOr || d == DataStringOr
+}
+
+// Wrap turns a boolean condition into a value of this type whose truth follows
+// the condition. The numeric and string forms keep value, the original value of
+// the marker, and AND the condition to it, so only rows it already selected can
+// match (a numeric value defaults to 1). The OR forms use a value no row should
+// match and let the condition alone select rows. The string forms reuse the
+// quote closing the original literal.
+func (d DataType) Wrap(value, condition string) string {
+	switch d {
+	case DataNumeric:
+		if value == "" {
+			value = defaultDataValue
+		}
+		return value + " AND (" + condition + ")"
+	case DataString:
+		return value + "' AND (" + condition + ") AND 'a'='a"
+	case DataNumericOr:
+		return "0 OR (" + condition + ")"
+	case DataStringOr:
+		return "' OR (" + condition + ") AND 'a'='a"
+	default:
+		return condition
+	}
+}
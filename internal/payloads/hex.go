@@ -0,0 +1,45 @@
+package payloads
+
+import (
+	"encoding/hex"
+	"fmt"
+	"unicode/utf16"
+)
+
+// HexLow and HexHigh bound the binary search of a hex digit (upper case
+// digits fall in between)
+const (
+	HexLow  = '0'
+	HexHigh = 'f'
+)
+
+// HexDigitsPerChar returns how many hex digits a character of a single-byte
+// value takes, used to scale length limits
+func HexDigitsPerChar(dbType DatabaseType) int {
+	if dbType == MSSQL {
+		return 4
+	}
+	return 2
+}
+
+// DecodeHex decodes a value extracted through GetHexQuery. A trailing half
+// byte (from a capped or partial extraction) is dropped. MSSQL values are
+// UTF-16LE, see MSSQLPayloads.GetHexQuery.
+func DecodeHex(dbType DatabaseType, value string) (string, error) {
+	if len(value)%2 != 0 {
+		value = value[:len(value)-1]
+	}
+	raw, err := hex.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid hex value %q: %w", value, err)
+	}
+
+	if dbType != MSSQL {
+		return string(raw), nil
+	}
+	units := make([]uint16, len(raw)/2)
+	for i := range units {
+		units[i] = uint16(raw[2*i]) | uint16(raw[2*i+1])<<8
+	}
+	return string(utf16.Decode(units)), nil
+}
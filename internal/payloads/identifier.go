@@ -0,0 +1,92 @@
+package payloads
+
+import "strings"
+
+// reservedWords are keywords commonly used as table or column names, which must
+// be quoted to be referenced
+var reservedWords = map[string]bool{
+	"ACCESS": true, "ADD": true, "ALL": true, "ALTER": true, "AND": true, "AS": true,
+	"ASC": true, "BETWEEN": true, "BY": true, "CASE": true, "CHECK": true, "COLUMN": true,
+	"COMMENT": true, "CONSTRAINT": true, "CREATE": true, "CROSS": true, "CURRENT": true,
+	"DATE": true, "DEFAULT": true, "DELETE": true, "DESC": true, "DISTINCT": true,
+	"DROP": true, "ELSE": true, "END": true, "EXISTS": true, "FILE": true, "FOR": true,
+	"FROM": true, "FULL": true, "GRANT": true, "GROUP": true, "HAVING": true, "IN": true,
+	"INDEX": true, "INNER": true, "INSERT": true, "INTO": true, "IS": true, "JOIN": true,
+	"KEY": true, "LEFT": true, "LEVEL": true, "LIKE": true, "LIMIT": true, "MODE": true,
+	"NOT": true, "NULL": true, "NUMBER": true, "OF": true, "OFFSET": true, "ON": true,
+	"OPTION": true, "OR": true, "ORDER": true, "OUTER": true, "PRIMARY": true,
+	"PUBLIC": true, "RANGE": true, "RIGHT": true, "ROW": true, "ROWNUM": true,
+	"ROWS": true, "SELECT": true, "SESSION": true, "SET": true, "SIZE": true,
+	"TABLE": true, "THEN": true, "TO": true, "TOP": true, "TRIGGER": true, "UID": true,
+	"UNION": true, "UNIQUE": true, "UPDATE": true, "USER": true, "USING": true,
+	"VALUES": true, "VIEW": true, "WHEN": true, "WHERE": true, "WITH": true,
+}
+
+// quoteIdentifier quotes each dot-separated part of name with open/close,
+// doubling close inside the part. Parts already wrapped in open/close are kept.
+// When needed is non-nil, parts it rejects are left unquoted.
+func quoteIdentifier(name, open, close string, needed func(string) bool) string {
+	if name == "" || isQuoted(name, open, close) {
+		return name
+	}
+
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if isQuoted(part, open, close) {
+			continue
+		}
+		if needed != nil && !needed(part) {
+			continue
+		}
+		parts[i] = open + strings.ReplaceAll(part, close, close+close) + close
+	}
+	return strings.Join(parts, ".")
+}
+
+// isQuoted reports whether s is already wrapped in open/close
+func isQuoted(s, open, close string) bool {
+	return len(s) > len(open)+len(close) && strings.HasPrefix(s, open) && strings.HasSuffix(s, close)
+}
+
+// QuoteCatalogName quotes a name read from the catalog, whose case is exact.
+// On top of QuoteIdentifier, a single-case name in the other case than the one
+// the database folds unquoted names to (USERS on PostgreSQL, users on Oracle
+// and DB2) is quoted, since it can only have been created quoted.
+func QuoteCatalogName(gen DatabasePayloads, name string) string {
+	var fold func(string) string
+	switch gen.GetType() {
+	case PostgreSQL:
+		fold = strings.ToLower
+	case Oracle, DB2:
+		fold = strings.ToUpper
+	default:
+		return gen.QuoteIdentifier(name)
+	}
+	return quoteIdentifier(name, `"`, `"`, func(part string) bool {
+		return needsQuoting(part) || part != fold(part)
+	})
+}
+
+// needsQuoting reports whether a part must be quoted on a database that folds
+// unquoted identifiers (PostgreSQL to lower case, Oracle and DB2 to upper case).
+// Reserved words, special characters and mixed case (only possible when the
+// name was created quoted) need quoting. Single-case plain names are left
+// unquoted so names typed in either case keep matching through case folding.
+func needsQuoting(part string) bool {
+	if reservedWords[strings.ToUpper(part)] {
+		return true
+	}
+	hasUpper, hasLower := false, false
+	for i, r := range part {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			hasUpper = true
+		case r >= 'a' && r <= 'z':
+			hasLower = true
+		case r == '_' || r == '$' || (i > 0 && r >= '0' && r <= '9'):
+		default:
+			return true
+		}
+	}
+	return hasUpper && hasLower
+}
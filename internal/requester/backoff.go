@@ -0,0 +1,112 @@
+package requester
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+const (
+	healthWindow     = 20                     // recent requests the error rate is computed over
+	healthMinSamples = 10                     // requests seen before the rate is trusted
+	healthThreshold  = 0.3                    // error rate that starts the backoff
+	backoffStart     = 500 * time.Millisecond // first delay once the threshold is crossed
+	backoffMax       = 30 * time.Second
+)
+
+// healthTracker keeps a rolling error rate over the last requests and the
+// delay inserted before each request while the target looks unhealthy
+type healthTracker struct {
+	mu      sync.Mutex
+	results [healthWindow]bool // true for a failed request, used as a ring
+	next    int
+	count   int
+	delay   time.Duration
+}
+
+// SetAdaptiveBackoff slows down when the target starts failing: once the rate of
+// network errors, 429 and 502/503/504 responses over the last requests crosses a
+// threshold, an escalating sleep is inserted before each request, and it is
+// relaxed again as the error rate recovers
+func (r *Requester) SetAdaptiveBackoff(enabled bool) {
+	if !enabled {
+		r.health = nil
+		return
+	}
+	r.health = &healthTracker{}
+}
+
+// backoffWait sleeps for the current adaptive delay, if any
+func (r *Requester) backoffWait() {
+	if r.health == nil {
+		return
+	}
+	r.health.mu.Lock()
+	delay := r.health.delay
+	r.health.mu.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// overloaded reports whether a status means the target or a proxy in front of
+// it is struggling. A plain 500 is left out: it is often the FALSE or ERROR page
+// of a blind injection, and half the probes would count as failures.
+func overloaded(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// recordHealth counts the outcome of a request and adjusts the adaptive delay
+func (r *Requester) recordHealth(resp *Response, err error) {
+	if r.health == nil {
+		return
+	}
+	failed := err != nil || overloaded(resp.StatusCode)
+
+	h := r.health
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.results[h.next] = failed
+	h.next = (h.next + 1) % healthWindow
+	if h.count < healthWindow {
+		h.count++
+	}
+	if h.count < healthMinSamples {
+		return
+	}
+
+	failures := 0
+	for i := 0; i < h.count; i++ {
+		if h.results[i] {
+			failures++
+		}
+	}
+	rate := float64(failures) / float64(h.count)
+
+	previous := h.delay
+	switch {
+	case rate > healthThreshold && failed:
+		// Escalate on each new failure while unhealthy
+		h.delay = min(max(h.delay*2, backoffStart), backoffMax)
+	case rate <= healthThreshold && h.delay > 0:
+		h.delay /= 2
+		if h.delay < backoffStart/4 {
+			h.delay = 0
+		}
+	}
+
+	if h.delay != previous {
+		if h.delay == 0 {
+			ui.Verbose(r.verbose, "Error rate recovered (%.0f%%), adaptive backoff off", rate*100)
+		} else {
+			ui.Verbose(r.verbose, "Error rate %.0f%%, adaptive backoff %s", rate*100, h.delay)
+		}
+	}
+}
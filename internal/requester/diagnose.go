@@ -0,0 +1,82 @@
+package requester
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// Classes of transport failures, wrapped around the underlying error by Send
+// so callers can tell them apart with errors.Is
+var (
+	ErrDNS     = errors.New("DNS resolution failed")
+	ErrRefused = errors.New("connection refused")
+	ErrTLS     = errors.New("TLS error")
+	ErrTimeout = errors.New("request timed out")
+	ErrProxy   = errors.New("proxy error")
+)
+
+// classifyError wraps err with the class of the failure, or returns it as is
+func classifyError(err error) error {
+	if class := errorClass(err); class != nil {
+		return fmt.Errorf("%w: %w", class, err)
+	}
+	return err
+}
+
+// errorClass returns the class of a transport failure, nil if unrecognized
+func errorClass(err error) error {
+	// Dialing the proxy fails the same ways as dialing the target, check it first
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "proxyconnect" {
+		return ErrProxy
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && !dnsErr.Timeout() {
+		return ErrDNS
+	}
+
+	var recordErr tls.RecordHeaderError
+	var verifyErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &recordErr) || errors.As(err, &verifyErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) ||
+		strings.Contains(err.Error(), "server gave HTTP response to HTTPS client") ||
+		strings.Contains(err.Error(), "tls: ") {
+		return ErrTLS
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrTimeout
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrRefused
+	}
+	return nil
+}
+
+// Hint returns what to check for a classified error, "" if there is no advice
+func Hint(err error) string {
+	switch {
+	case errors.Is(err, ErrProxy):
+		return "check that the proxy set with -proxy is running and reachable"
+	case errors.Is(err, ErrDNS):
+		return "check the Host header (or -u URL) for typos, and the DNS/VPN settings"
+	case errors.Is(err, ErrTLS):
+		return "the target may not speak HTTPS on this port, try -ph (plain HTTP), or check -ca-cert/-client-cert"
+	case errors.Is(err, ErrTimeout):
+		return "the target is slow or filtered: raise -timeout, or check firewalls and the VPN"
+	case errors.Is(err, ErrRefused):
+		return "nothing listens on that port: check the port in the Host header and the scheme (-ph)"
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package requester
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/morkin1792/flatsqli/internal/fingerprint"
+)
+
+// LogEntry is one request/response transaction in the JSONL log
+type LogEntry struct {
+	Time       time.Time         `json:"time"`
+	RequestNum int               `json:"request"`
+	Payload    string            `json:"payload,omitempty"`
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Headers    map[string]string `json:"headers,omitempty"` // headers of the request file, -H ones are not logged
+	Body       string            `json:"body,omitempty"`
+	Status     int               `json:"status,omitempty"`
+	Words      int               `json:"words,omitempty"`
+	Length     int               `json:"length,omitempty"`
+	DurationMs int64             `json:"duration_ms,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// TransactionLogger appends every transaction to a file as one JSON object per line.
+// A nil logger is valid and logs nothing.
+type TransactionLogger struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewTransactionLogger opens (or creates) path for appending
+func NewTransactionLogger(path string) (*TransactionLogger, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return &TransactionLogger{
+		file: file,
+		enc:  json.NewEncoder(file),
+	}, nil
+}
+
+// Log writes an entry, errors are ignored so logging never breaks a scan
+func (l *TransactionLogger) Log(entry LogEntry) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.enc.Encode(entry)
+}
+
+// Close closes the log file
+func (l *TransactionLogger) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// ReadLog reads the entries of a JSONL transaction log
+func ReadLog(path string) ([]LogEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []LogEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry LogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("log line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log file: %w", err)
+	}
+	return entries, nil
+}
+
+// Fingerprint returns the recorded response as a fingerprint to compare with
+// Equals (status, words and length only), nil if the transaction failed
+func (e LogEntry) Fingerprint() *fingerprint.Fingerprint {
+	if e.Status == 0 {
+		return nil
+	}
+	return &fingerprint.Fingerprint{
+		StatusCode:    e.Status,
+		WordCount:     e.Words,
+		ContentLength: e.Length,
+	}
+}
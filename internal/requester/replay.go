@@ -0,0 +1,32 @@
+package requester
+
+import (
+	"fmt"
+
+	"github.com/morkin1792/flatsqli/internal/parser"
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+// Replay re-sends a logged transaction as recorded: method, URL, headers and
+// body. Custom headers (-H) apply on top, as for any request.
+func (r *Requester) Replay(entry LogEntry) (*Response, error) {
+	req, err := parser.URLToRequestWithBody(entry.URL, entry.Method, entry.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild request: %w", err)
+	}
+	if len(entry.Headers) > 0 {
+		req.Headers = entry.Headers
+	}
+
+	if err := r.checkBudget(); err != nil {
+		return nil, err
+	}
+	num := r.nextRequest()
+
+	targetURL := req.GetTargetURL()
+	ui.Verbose(r.verbose, "[Req #%d] %s %s (replay of #%d)", num, req.Method, targetURL, entry.RequestNum)
+
+	resp, err := r.sendWithRetry(req, targetURL, num)
+	r.logTransaction(num, entry.Payload, req, targetURL, resp, err)
+	return resp, err
+}
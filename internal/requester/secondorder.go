@@ -0,0 +1,39 @@
+package requester
+
+import (
+	"fmt"
+
+	"github.com/morkin1792/flatsqli/internal/parser"
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+// SetObserveRequest enables second-order mode: every payload is injected with the
+// base request, then observe is sent and its response is the one fingerprinted.
+// If observe contains a marker, it receives the same payload.
+func (r *Requester) SetObserveRequest(observe *parser.ParsedRequest) {
+	r.observeRequest = observe
+}
+
+// observe sends the observe request that follows an injection in second-order mode
+func (r *Requester) observe(payload string) (*Response, error) {
+	if err := r.checkBudget(); err != nil {
+		return nil, err
+	}
+	num := r.nextRequest()
+
+	req := r.observeRequest
+	if req.MarkerCount() > 0 {
+		built, err := req.BuildRequest(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build observe request: %w", err)
+		}
+		req = built
+	}
+
+	targetURL := req.GetTargetURL()
+	ui.Verbose(r.verbose, "[Req #%d] %s %s (observe)", num, req.Method, targetURL)
+
+	resp, err := r.sendWithRetry(req, targetURL, num)
+	r.logTransaction(num, payload, req, targetURL, resp, err)
+	return resp, err
+}
@@ -0,0 +1,87 @@
+package requester
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Stats summarizes the traffic sent by a Requester
+type Stats struct {
+	Requests  int64
+	Retries   int64
+	Errors    int64
+	BytesRead int64
+	Elapsed   time.Duration
+}
+
+// counters are updated atomically, so they stay consistent with concurrent senders
+type counters struct {
+	requests atomic.Int64
+	retries  atomic.Int64
+	errors   atomic.Int64
+	bytes    atomic.Int64
+}
+
+// nextRequest counts a new request and returns its number
+func (r *Requester) nextRequest() int {
+	return int(r.counters.requests.Add(1))
+}
+
+// Stats returns the requests, retries, errors and bytes read so far, and the
+// time since the Requester was created
+func (r *Requester) Stats() Stats {
+	return Stats{
+		Requests:  r.counters.requests.Load(),
+		Retries:   r.counters.retries.Load(),
+		Errors:    r.counters.errors.Load(),
+		BytesRead: r.counters.bytes.Load(),
+		Elapsed:   time.Since(r.started),
+	}
+}
+
+// Add accumulates other into s, for runs using several Requesters one after the other
+func (s *Stats) Add(other Stats) {
+	s.Requests += other.Requests
+	s.Retries += other.Retries
+	s.Errors += other.Errors
+	s.BytesRead += other.BytesRead
+	s.Elapsed += other.Elapsed
+}
+
+// String formats the summary line, e.g. "Sent 1,284 requests in 2m13s, 3 retries"
+func (s Stats) String() string {
+	summary := fmt.Sprintf("Sent %s requests in %s, %s retries", groupThousands(s.Requests),
+		s.Elapsed.Round(time.Second), groupThousands(s.Retries))
+	if s.Errors > 0 {
+		summary += fmt.Sprintf(", %s errors", groupThousands(s.Errors))
+	}
+	return summary + fmt.Sprintf(", %s read", formatBytes(s.BytesRead))
+}
+
+// groupThousands formats n with comma thousand separators
+func groupThousands(n int64) string {
+	if n < 0 {
+		return "-" + groupThousands(-n)
+	}
+	digits := strconv.FormatInt(n, 10)
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	return digits
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,60 @@
+package requester
+
+import (
+	"math/rand"
+	"strings"
+
+	"github.com/morkin1792/flatsqli/internal/parser"
+)
+
+// userAgents are realistic browser User-Agents rotated by SetRandomUserAgent
+var userAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:131.0) Gecko/20100101 Firefox/131.0",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36 Edg/129.0.0.0",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0 Safari/605.1.15",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0",
+	"Mozilla/5.0 (iPhone; CPU iPhone OS 18_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0 Mobile/15E148 Safari/604.1",
+	"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Mobile Safari/537.36",
+}
+
+// SetUserAgent sends ua as the User-Agent of every request ("" keeps the request's own)
+func (r *Requester) SetUserAgent(ua string) {
+	r.userAgent = ua
+	r.baseUA = headerValue(r.baseRequest.Headers, "User-Agent")
+}
+
+// SetRandomUserAgent picks a random browser User-Agent for every request
+func (r *Requester) SetRandomUserAgent(enabled bool) {
+	r.randomUA = enabled
+	r.baseUA = headerValue(r.baseRequest.Headers, "User-Agent")
+}
+
+// userAgentFor returns the User-Agent overriding the one of req, "" for none.
+// A request whose User-Agent differs from the base request's is carrying an
+// injected value (header fuzzing) and is left alone. -H still wins, since
+// custom headers are applied afterwards.
+func (r *Requester) userAgentFor(req *parser.ParsedRequest) string {
+	if !r.randomUA && r.userAgent == "" {
+		return ""
+	}
+	if headerValue(req.Headers, "User-Agent") != r.baseUA {
+		return ""
+	}
+	if r.randomUA {
+		return userAgents[rand.Intn(len(userAgents))]
+	}
+	return r.userAgent
+}
+
+// headerValue returns a header from a parsed request, matching the name case-insensitively
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
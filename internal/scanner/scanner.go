@@ -1,13 +1,16 @@
 package scanner
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"path"
 	"strconv"
 	"strings"
 
 	"github.com/morkin1792/flatsqli/internal/parser"
+	"github.com/morkin1792/flatsqli/internal/payloads"
 	"github.com/morkin1792/flatsqli/internal/requester"
 	"github.com/morkin1792/flatsqli/internal/ui"
 )
@@ -16,17 +19,71 @@ import (
 type Parameter struct {
 	Name     string
 	Value    string
-	Location string // "url", "body-form", "body-json"
-	Path     string // JSON path if applicable
+	Location string // "url", "path", "body-form", "body-json", "graphql-var", "header"
+	Path     string // JSON path if applicable (under variables for "graphql-var"), header name for "header" (cookies use "Cookie"), segment number for "path"
+	Seed     string // value probed instead of Value (-seed), Value stays the one in the request
+}
+
+// ProbeValue returns the value the probes are built around: the seed, if any,
+// else the original value
+func (p Parameter) ProbeValue() string {
+	if p.Seed != "" {
+		return p.Seed
+	}
+	return p.Value
+}
+
+// fuzzHeaders are the headers injected when header fuzzing is enabled
+var fuzzHeaders = []string{"User-Agent", "Referer", "X-Forwarded-For", "X-Forwarded-Host"}
+
+// Confidence rates how much evidence backs a finding
+type Confidence int
+
+const (
+	ConfidenceNone Confidence = iota
+	// ConfidenceLow is a single quote difference (' vs '') only
+	ConfidenceLow
+	// ConfidenceMedium is a concat/math match, or several heuristics agreeing
+	ConfidenceMedium
+	// ConfidenceHigh is confirmed by a boolean TRUE/FALSE pair
+	ConfidenceHigh
+)
+
+// String returns the confidence level name
+func (c Confidence) String() string {
+	switch c {
+	case ConfidenceLow:
+		return "low"
+	case ConfidenceMedium:
+		return "medium"
+	case ConfidenceHigh:
+		return "high"
+	default:
+		return "none"
+	}
+}
+
+// heuristicConfidence returns the confidence of an unconfirmed heuristic
+func heuristicConfidence(heuristic string) Confidence {
+	if heuristic == "quote-based" {
+		return ConfidenceLow
+	}
+	return ConfidenceMedium
 }
 
 // ScanResult represents the result of scanning a parameter
 type ScanResult struct {
 	Parameter      Parameter
 	IsVulnerable   bool
-	VulnType       string // "error-based", "concat-based"
+	VulnType       string // "boolean-confirmed", or the heuristic: "quote-based", "concat-based", "math-based"
 	Details        string
 	WorkingPayload string
+	Candidate      bool              // a heuristic matched but the boolean TRUE/FALSE pair did not confirm it
+	ColumnCount    int               // columns of the underlying query found via ORDER BY (0 = unknown)
+	Context        *Context          // closure context confirmed by the boolean pair (nil if unconfirmed)
+	BaseValue      string            // value the confirmed boolean pair was appended to
+	DataType       payloads.DataType // numeric or string, from Context (condition if unconfirmed)
+	Confidence     Confidence
 }
 
 // Scanner handles SQLi auto-discovery
@@ -34,6 +91,13 @@ type Scanner struct {
 	baseRequest *parser.ParsedRequest
 	requester   *requester.Requester
 	verbose     bool
+	fuzzHeaders bool
+	pathFuzz    bool
+	stopOnFirst bool
+	include     []string
+	exclude     []string
+	contexts    bool
+	seeds       map[string]string // parameter name or JSON path -> value probed instead of the original
 }
 
 // New creates a new Scanner
@@ -45,7 +109,44 @@ func New(baseReq *parser.ParsedRequest, req *requester.Requester, verbose bool)
 	}
 }
 
-// DiscoverParameters extracts all parameters from the request
+// SetFuzzHeaders enables injection into common headers and each cookie
+func (s *Scanner) SetFuzzHeaders(enabled bool) {
+	s.fuzzHeaders = enabled
+}
+
+// SetStopOnFirst stops scanning the remaining parameters once one is vulnerable
+func (s *Scanner) SetStopOnFirst(enabled bool) {
+	s.stopOnFirst = enabled
+}
+
+// SetParamFilter restricts which parameters are scanned. Patterns are
+// case-insensitive globs (e.g. "utm_*"); an empty include list allows all.
+func (s *Scanner) SetParamFilter(include, exclude []string) {
+	s.include = include
+	s.exclude = exclude
+}
+
+// SetSeeds sets realistic values to build the probes around, by parameter name
+// (or JSON path), for parameters whose original value is empty or a token the
+// application rejects before reaching the query
+func (s *Scanner) SetSeeds(seeds map[string]string) {
+	s.seeds = seeds
+}
+
+// seed sets the seed of param, if any
+func (s *Scanner) seed(param Parameter) Parameter {
+	value, ok := s.seeds[param.Name]
+	if !ok && isJSONParam(param) {
+		value, ok = s.seeds[param.Path]
+	}
+	if ok {
+		ui.Verbose(s.verbose, "Seeding %s with %q instead of %q", param.Name, value, param.Value)
+		param.Seed = value
+	}
+	return param
+}
+
+// DiscoverParameters extracts all parameters from the request that pass the filter
 func (s *Scanner) DiscoverParameters() []Parameter {
 	var params []Parameter
 
@@ -53,13 +154,135 @@ func (s *Scanner) DiscoverParameters() []Parameter {
 	urlParams := s.parseURLParams()
 	params = append(params, urlParams...)
 
+	// Parse path segments
+	if s.pathFuzz {
+		params = append(params, s.parsePathParams()...)
+	}
+
 	// Parse body parameters
 	bodyParams := s.parseBodyParams()
 	params = append(params, bodyParams...)
 
+	// Parse header and cookie parameters
+	if s.fuzzHeaders {
+		params = append(params, s.parseHeaderParams()...)
+	}
+
+	if len(s.include) == 0 && len(s.exclude) == 0 {
+		return params
+	}
+
+	var allowed []Parameter
+	for _, param := range params {
+		if s.allowed(param) {
+			allowed = append(allowed, param)
+		} else {
+			ui.Verbose(s.verbose, "Skipping filtered parameter: %s", param.Name)
+		}
+	}
+	return allowed
+}
+
+// allowed reports whether a parameter passes the include/exclude filters.
+// JSON parameters match on either their name or their full path.
+func (s *Scanner) allowed(param Parameter) bool {
+	names := []string{param.Name}
+	if isJSONParam(param) && param.Path != param.Name {
+		names = append(names, param.Path)
+	}
+
+	if len(s.include) > 0 && !matchAny(s.include, names) {
+		return false
+	}
+	return !matchAny(s.exclude, names)
+}
+
+// isJSONParam reports whether a parameter lives in a JSON body and has a path
+func isJSONParam(param Parameter) bool {
+	return param.Location == "body-json" || param.Location == "graphql-var"
+}
+
+// matchAny reports whether any name matches any glob pattern, ignoring case
+func matchAny(patterns, names []string) bool {
+	for _, pattern := range patterns {
+		for _, name := range names {
+			if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseHeaderParams returns the fuzzed headers and each cookie as parameters
+func (s *Scanner) parseHeaderParams() []Parameter {
+	var params []Parameter
+
+	for _, name := range fuzzHeaders {
+		value := s.headerValue(name)
+		if value == "" {
+			value = s.defaultHeaderValue(name)
+		}
+		params = append(params, Parameter{
+			Name:     name,
+			Value:    value,
+			Location: "header",
+			Path:     name,
+		})
+	}
+
+	for _, cookie := range parseCookies(s.headerValue("Cookie")) {
+		params = append(params, Parameter{
+			Name:     cookie[0],
+			Value:    cookie[1],
+			Location: "header",
+			Path:     "Cookie",
+		})
+	}
+
 	return params
 }
 
+// headerValue returns the value of a request header (case-insensitive)
+func (s *Scanner) headerValue(name string) string {
+	for k, v := range s.baseRequest.Headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// defaultHeaderValue returns a plausible value for a header missing from the request
+func (s *Scanner) defaultHeaderValue(name string) string {
+	switch name {
+	case "Referer":
+		return s.baseRequest.Scheme + "://" + s.baseRequest.Host + "/"
+	case "X-Forwarded-For":
+		return "127.0.0.1"
+	case "X-Forwarded-Host":
+		return s.baseRequest.Host
+	default:
+		return "Mozilla/5.0"
+	}
+}
+
+// parseCookies splits a Cookie header into ordered name/value pairs
+func parseCookies(header string) [][2]string {
+	var cookies [][2]string
+	for _, part := range strings.Split(header, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) == 2 {
+			cookies = append(cookies, [2]string{kv[0], kv[1]})
+		}
+	}
+	return cookies
+}
+
 // parseURLParams extracts parameters from the URL query string
 func (s *Scanner) parseURLParams() []Parameter {
 	var params []Parameter
@@ -103,9 +326,13 @@ func (s *Scanner) parseBodyParams() []Parameter {
 		}
 	}
 
-	// JSON body
+	// JSON body, GraphQL operations only expose their variables
 	if strings.Contains(contentType, "application/json") {
-		params = append(params, s.parseJSONParams(body)...)
+		if graphQL, ok := s.parseGraphQLParams(body); ok {
+			params = append(params, graphQL...)
+		} else {
+			params = append(params, s.parseJSONParams(body)...)
+		}
 	}
 
 	// Form-urlencoded body
@@ -116,16 +343,22 @@ func (s *Scanner) parseBodyParams() []Parameter {
 	return params
 }
 
-// parseJSONParams extracts parameters from JSON body
+// parseJSONParams extracts parameters from JSON body. Top-level arrays (e.g. bulk
+// endpoints) use indexed paths: [{"id":"1"}] yields 0.id.
 func (s *Scanner) parseJSONParams(body string) []Parameter {
 	var params []Parameter
-	var data map[string]interface{}
+	var data interface{}
 
 	if err := json.Unmarshal([]byte(body), &data); err != nil {
 		return params
 	}
 
-	s.extractJSONParams(data, "", &params)
+	switch v := data.(type) {
+	case map[string]interface{}:
+		s.extractJSONParams(v, "", &params)
+	case []interface{}:
+		s.extractJSONArray(v, "", &params)
+	}
 	return params
 }
 
@@ -136,18 +369,43 @@ func (s *Scanner) extractJSONParams(data map[string]interface{}, prefix string,
 		if prefix != "" {
 			path = prefix + "." + key
 		}
+		s.extractJSONValue(key, path, value, params)
+	}
+}
 
-		switch v := value.(type) {
-		case string:
-			*params = append(*params, Parameter{
-				Name:     key,
-				Value:    v,
-				Location: "body-json",
-				Path:     path,
-			})
-		case map[string]interface{}:
-			s.extractJSONParams(v, path, params)
+// extractJSONArray extracts JSON parameters from array elements using indexed paths (ids.0, ids.1)
+func (s *Scanner) extractJSONArray(data []interface{}, prefix string, params *[]Parameter) {
+	for i, value := range data {
+		path := strconv.Itoa(i)
+		if prefix != "" {
+			path = prefix + "." + path
 		}
+		s.extractJSONValue(path, path, value, params)
+	}
+}
+
+// extractJSONValue emits a parameter for string and number values and recurses
+// into objects and arrays. Numbers stay numbers where probes allow, see jsonValue.
+func (s *Scanner) extractJSONValue(name, path string, value interface{}, params *[]Parameter) {
+	switch v := value.(type) {
+	case string:
+		*params = append(*params, Parameter{
+			Name:     name,
+			Value:    v,
+			Location: "body-json",
+			Path:     path,
+		})
+	case float64:
+		*params = append(*params, Parameter{
+			Name:     name,
+			Value:    strconv.FormatFloat(v, 'f', -1, 64),
+			Location: "body-json",
+			Path:     path,
+		})
+	case map[string]interface{}:
+		s.extractJSONParams(v, path, params)
+	case []interface{}:
+		s.extractJSONArray(v, path, params)
 	}
 }
 
@@ -181,10 +439,11 @@ func (s *Scanner) ScanParameter(param Parameter) *ScanResult {
 	}
 
 	ui.Verbose(s.verbose, "Testing parameter: %s (%s)", param.Name, param.Location)
+	value := param.ProbeValue()
 
 	// Step 1: Test ' vs '' for quote-based detection with triple-quote confirmation
-	singleQuote := s.sendWithValue(param, param.Value+"'")
-	doubleQuote := s.sendWithValue(param, param.Value+"''")
+	singleQuote := s.sendWithValue(param, value+"'")
+	doubleQuote := s.sendWithValue(param, value+"''")
 
 	if singleQuote != nil && doubleQuote != nil {
 		if !singleQuote.Fingerprint.Equals(doubleQuote.Fingerprint) {
@@ -193,14 +452,13 @@ func (s *Scanner) ScanParameter(param Parameter) *ScanResult {
 			// and produce the same response, while even-quote payloads ('') produce a
 			// different (valid) response. App-level validation typically treats 1 vs 3
 			// quotes differently, so this filters out those false positives.
-			tripleQuote := s.sendWithValue(param, param.Value+"'''")
+			tripleQuote := s.sendWithValue(param, value+"'''")
 			if tripleQuote != nil && tripleQuote.Fingerprint.Equals(singleQuote.Fingerprint) {
-				result.IsVulnerable = true
-				result.VulnType = "quote-based"
-				result.Details = "Different responses for ' vs '' (confirmed with ''')"
-				result.WorkingPayload = param.Value + "'"
-				ui.Verbose(s.verbose, "Found quote-based SQLi in %s (triple-quote confirmed)", param.Name)
-				return result
+				ui.Verbose(s.verbose, "Found quote-based candidate in %s (triple-quote confirmed)", param.Name)
+				details := "Different responses for ' vs '' (confirmed with ''')"
+				if s.confirmBoolean(result, param, value, false, "quote-based", details, value+"'") {
+					return result
+				}
 			}
 			ui.Verbose(s.verbose, "Quote diff detected in %s but triple-quote confirmation failed, continuing", param.Name)
 		}
@@ -227,7 +485,7 @@ func (s *Scanner) ScanParameter(param Parameter) *ScanResult {
 
 	// Build array with original value + common testing values
 	commonTestingValues := []string{"admin", "1", "0"}
-	testValues := append([]string{param.Value}, commonTestingValues...)
+	testValues := append([]string{value}, commonTestingValues...)
 	concatOperators := []string{"||", "+", "", " "}
 
 	for _, val := range testValues {
@@ -251,12 +509,13 @@ func (s *Scanner) ScanParameter(param Parameter) *ScanResult {
 			resp := s.sendWithValue(param, payload)
 			// SQLi detected if: concat response matches original value AND differs from garbage
 			if resp != nil && valResp.Fingerprint.Equals(resp.Fingerprint) && !garbageResp.Fingerprint.Equals(resp.Fingerprint) {
-				result.IsVulnerable = true
-				result.VulnType = "concat-based"
-				result.Details = fmt.Sprintf("Concat payload '%s' produced same response as '%s'", payload, val)
-				result.WorkingPayload = payload
-				ui.Verbose(s.verbose, "Found concat-based SQLi in %s using payload: %s", param.Name, payload)
-				return result
+				ui.Verbose(s.verbose, "Found concat-based candidate in %s using payload: %s", param.Name, payload)
+				details := fmt.Sprintf("Concat payload '%s' produced same response as '%s'", payload, val)
+				if s.confirmBoolean(result, param, val, false, "concat-based", details, payload) {
+					return result
+				}
+				// Unconfirmed, the next operator may still confirm
+				continue
 			}
 		}
 
@@ -267,19 +526,65 @@ func (s *Scanner) ScanParameter(param Parameter) *ScanResult {
 			resp := s.sendWithValue(param, mathPayload)
 			// SQLi detected if: math result matches original value AND differs from garbage
 			if resp != nil && valResp.Fingerprint.Equals(resp.Fingerprint) && !garbageResp.Fingerprint.Equals(resp.Fingerprint) {
-				result.IsVulnerable = true
-				result.VulnType = "math-based"
-				result.Details = fmt.Sprintf("Math payload '%s' produced same response as '%s'", mathPayload, val)
-				result.WorkingPayload = mathPayload
-				ui.Verbose(s.verbose, "Found math-based SQLi in %s using payload: %s", param.Name, mathPayload)
-				return result
+				ui.Verbose(s.verbose, "Found math-based candidate in %s using payload: %s", param.Name, mathPayload)
+				details := fmt.Sprintf("Math payload '%s' produced same response as '%s'", mathPayload, val)
+				if s.confirmBoolean(result, param, val, true, "math-based", details, mathPayload) {
+					return result
+				}
 			}
 		}
 	}
 
+	// Step 3: probe the whole context matrix, e.g. for double-quoted literals the
+	// quote heuristics above can't see
+	if s.contexts && !result.Candidate {
+		if ctx, payload := s.findContext(param, value, s.contextsFor(value, isNumeric(value))); ctx != nil {
+			s.markConfirmed(result, param, value, ctx, payload, "Boolean pair matched context "+ctx.String(), "context-matrix")
+		}
+	}
+
 	return result
 }
 
+// confirmBoolean verifies a heuristic finding with a TRUE/FALSE boolean pair appended
+// to value: the TRUE condition must reproduce the response of value itself while the
+// FALSE condition must not. On success the result is marked vulnerable; otherwise the
+// first heuristic is kept as an unconfirmed candidate.
+func (s *Scanner) confirmBoolean(result *ScanResult, param Parameter, value string, numeric bool, heuristic, details, heuristicPayload string) bool {
+	if ctx, truePayload := s.findContext(param, value, s.contextsFor(value, numeric)); ctx != nil {
+		s.markConfirmed(result, param, value, ctx, truePayload, details, heuristic)
+		return true
+	}
+
+	ui.Verbose(s.verbose, "Boolean pair did not confirm %s finding in %s", heuristic, param.Name)
+	if !result.Candidate {
+		result.Candidate = true
+		result.VulnType = heuristic
+		result.Details = details + " (not confirmed by boolean pair)"
+		result.WorkingPayload = heuristicPayload
+		result.Confidence = heuristicConfidence(heuristic)
+	} else if heuristic != result.VulnType {
+		// An independent heuristic agrees with the first one
+		result.Confidence = max(result.Confidence, ConfidenceMedium)
+	}
+	return false
+}
+
+// markConfirmed marks result vulnerable, confirmed by the boolean pair in ctx
+func (s *Scanner) markConfirmed(result *ScanResult, param Parameter, value string, ctx *Context, truePayload, details, heuristic string) {
+	result.IsVulnerable = true
+	result.Candidate = false
+	result.VulnType = "boolean-confirmed"
+	result.Confidence = ConfidenceHigh
+	result.Details = fmt.Sprintf("%s; confirmed by boolean pair (%s)", details, heuristic)
+	result.WorkingPayload = truePayload
+	result.Context = ctx
+	result.DataType = ctx.DataType()
+	result.BaseValue = value
+	ui.Verbose(s.verbose, "Boolean pair confirmed %s finding in %s", heuristic, param.Name)
+	result.ColumnCount = s.discoverColumnCount(param, value, *ctx)
+}
+
 // ScanAll scans all discovered parameters
 func (s *Scanner) ScanAll() []*ScanResult {
 	params := s.DiscoverParameters()
@@ -288,8 +593,13 @@ func (s *Scanner) ScanAll() []*ScanResult {
 	ui.Info("Discovered %d parameters to scan", len(params))
 
 	for _, param := range params {
-		result := s.ScanParameter(param)
+		result := s.ScanParameter(s.seed(param))
 		results = append(results, result)
+
+		if s.stopOnFirst && result.IsVulnerable {
+			ui.Verbose(s.verbose, "Stopping at first vulnerable parameter: %s", param.Name)
+			break
+		}
 	}
 
 	return results
@@ -303,10 +613,14 @@ func (s *Scanner) sendWithValue(param Parameter, newValue string) *requester.Res
 	switch param.Location {
 	case "url":
 		modifiedRaw = s.replaceURLParam(param.Name, newValue)
+	case "path":
+		modifiedRaw = s.replacePathParam(param, newValue)
 	case "body-form":
 		modifiedRaw = s.replaceFormParam(param.Name, newValue)
-	case "body-json":
+	case "body-json", "graphql-var":
 		modifiedRaw = s.replaceJSONParam(param.Path, newValue)
+	case "header":
+		modifiedRaw = s.replaceHeaderParam(param, newValue)
 	default:
 		return nil
 	}
@@ -358,14 +672,14 @@ func (s *Scanner) replaceJSONParam(path, newValue string) string {
 	raw := s.baseRequest.RawRequest
 	body := s.baseRequest.Body
 
-	var data map[string]interface{}
+	var data interface{}
 	if err := json.Unmarshal([]byte(body), &data); err != nil {
 		return raw
 	}
 
-	// Set value at path
+	// Set value at path (objects and arrays alike, see setJSONValue)
 	parts := strings.Split(path, ".")
-	s.setJSONValue(data, parts, newValue)
+	setJSONValue(data, parts, newValue)
 
 	newBody, err := json.Marshal(data)
 	if err != nil {
@@ -376,18 +690,117 @@ func (s *Scanner) replaceJSONParam(path, newValue string) string {
 	return raw
 }
 
-// setJSONValue sets a value at a JSON path
-func (s *Scanner) setJSONValue(data map[string]interface{}, path []string, value string) {
-	if len(path) == 1 {
-		data[path[0]] = value
+// replaceHeaderParam sets a header (or a single cookie) value, adding the header if missing
+func (s *Scanner) replaceHeaderParam(param Parameter, newValue string) string {
+	value := newValue
+	if strings.EqualFold(param.Path, "Cookie") {
+		cookies := parseCookies(s.headerValue("Cookie"))
+		parts := make([]string, 0, len(cookies))
+		for _, c := range cookies {
+			if c[0] == param.Name {
+				c[1] = newValue
+			}
+			parts = append(parts, c[0]+"="+c[1])
+		}
+		value = strings.Join(parts, "; ")
+	}
+
+	return SetRawHeader(s.baseRequest.RawRequest, param.Path, value)
+}
+
+// SetRawHeader replaces a header value in a raw request, inserting it after the
+// request line when absent
+func SetRawHeader(rawRequest, name, value string) string {
+	lines := strings.Split(rawRequest, "\n")
+	if len(lines) == 0 {
+		return rawRequest
+	}
+
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if line == "" {
+			break // End of headers
+		}
+		colonIdx := strings.Index(line, ":")
+		if colonIdx > 0 && strings.EqualFold(strings.TrimSpace(line[:colonIdx]), name) {
+			eol := lines[i][len(line):] // keep \r if present
+			lines[i] = line[:colonIdx] + ": " + value + eol
+			return strings.Join(lines, "\n")
+		}
+	}
+
+	eol := ""
+	if strings.HasSuffix(lines[0], "\r") {
+		eol = "\r"
+	}
+	header := name + ": " + value + eol
+	lines = append(lines[:1], append([]string{header}, lines[1:]...)...)
+	return strings.Join(lines, "\n")
+}
+
+// jsonMark stands for the marker while a JSON body is re-encoded
+const jsonMark = "flatsqli-json-mark"
+
+// MarkJSONPath replaces the value at the path of a "body-json" or "graphql-var"
+// parameter with marker, which is inserted as is inside a JSON string
+func MarkJSONPath(rawRequest string, param Parameter, marker string) string {
+	req, err := parser.ParseRequest(rawRequest)
+	if err != nil {
+		return rawRequest
+	}
+	var data interface{}
+	if err := json.Unmarshal([]byte(req.Body), &data); err != nil {
+		return rawRequest
+	}
+	setJSONValue(data, strings.Split(param.Path, "."), jsonMark)
+
+	// Without HTML escaping, the < and > of the marker stay readable
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(data); err != nil {
+		return rawRequest
+	}
+	marked := strings.Replace(strings.TrimSuffix(body.String(), "\n"), `"`+jsonMark+`"`, `"`+marker+`"`, 1)
+	return strings.Replace(rawRequest, req.Body, marked, 1)
+}
+
+// setJSONValue sets a value at a JSON path, indexing into arrays for numeric segments
+func setJSONValue(data interface{}, path []string, value string) {
+	if len(path) == 0 {
 		return
 	}
 
-	if next, ok := data[path[0]].(map[string]interface{}); ok {
-		s.setJSONValue(next, path[1:], value)
+	switch node := data.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			node[path[0]] = jsonValue(node[path[0]], value)
+			return
+		}
+		setJSONValue(node[path[0]], path[1:], value)
+	case []interface{}:
+		idx, err := strconv.Atoi(path[0])
+		if err != nil || idx < 0 || idx >= len(node) {
+			return
+		}
+		if len(path) == 1 {
+			node[idx] = jsonValue(node[idx], value)
+			return
+		}
+		setJSONValue(node[idx], path[1:], value)
 	}
 }
 
+// jsonValue returns value as a JSON number when it replaces a number and is one,
+// since GraphQL rejects a string for an Int variable before any resolver runs,
+// and as a string otherwise
+func jsonValue(old interface{}, value string) interface{} {
+	if _, number := old.(float64); number && value != "" && (value[0] == '-' || (value[0] >= '0' && value[0] <= '9')) && json.Valid([]byte(value)) {
+		return json.Number(value)
+	}
+	return value
+}
+
 // PrintResults prints scan results
 func PrintResults(results []*ScanResult) {
 	vulnerable := 0
@@ -399,20 +812,35 @@ func PrintResults(results []*ScanResult) {
 
 	if vulnerable == 0 {
 		ui.Info("No SQL injection vulnerabilities found")
-		return
+	} else {
+		ui.Success("Found %d potential SQL injection point(s):", vulnerable)
+		fmt.Println()
+
+		for _, r := range results {
+			if r.IsVulnerable {
+				ui.Success("Parameter: %s", r.Parameter.Name)
+				ui.Info("  Location: %s", r.Parameter.Location)
+				ui.Info("  Type: %s", r.VulnType)
+				ui.Info("  Confidence: %s", r.Confidence)
+				ui.Info("  Details: %s", r.Details)
+				ui.Info("  Payload: %s", r.WorkingPayload)
+				if r.Context != nil {
+					ui.Info("  Context: %s", r.Context)
+					ui.Info("  Data type: %s", r.DataType)
+				}
+				if r.ColumnCount > 0 {
+					ui.Info("  Columns: %d (for UNION-based exploitation)", r.ColumnCount)
+				}
+				fmt.Println()
+			}
+		}
 	}
 
-	ui.Success("Found %d potential SQL injection point(s):", vulnerable)
-	fmt.Println()
-
+	// Lower-confidence signals that failed boolean confirmation
 	for _, r := range results {
-		if r.IsVulnerable {
-			ui.Success("Parameter: %s", r.Parameter.Name)
-			ui.Info("  Location: %s", r.Parameter.Location)
-			ui.Info("  Type: %s", r.VulnType)
+		if r.Candidate && !r.IsVulnerable {
+			ui.Warning("Unconfirmed candidate: %s (%s, %s, confidence: %s)", r.Parameter.Name, r.Parameter.Location, r.VulnType, r.Confidence)
 			ui.Info("  Details: %s", r.Details)
-			ui.Info("  Payload: %s", r.WorkingPayload)
-			fmt.Println()
 		}
 	}
 }
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+// The cache file is loaded once per run and kept in memory. Mutations only mark
+// it dirty; it is written back periodically and on Flush, instead of reading and
+// rewriting the whole file on every row, column or known string.
+//
+// Other processes may write the file meanwhile, so a flush locks it, reloads it
+// and only replaces the hosts this process changed (see merge). Two runs against
+// different hosts never lose each other's data; against the same host, the last
+// flush wins.
+const (
+	flushEvery    = 25               // pending mutations before a periodic flush
+	flushInterval = 10 * time.Second // max time between periodic flushes
+)
+
+// handle is the in-memory cache shared by the whole run
+type handle struct {
+	mu        sync.Mutex
+	cache     *Cache
+	dirty     bool
+	pending   int
+	lastFlush time.Time
+	changed   map[string]bool // normalized hosts changed since the last flush
+}
+
+var current handle
+
+// acquire locks the in-memory cache, loading it from disk on first use.
+// The returned unlock function must be called when done.
+func acquire() (*Cache, func(), error) {
+	current.mu.Lock()
+	if current.cache == nil {
+		cache, err := loadUnifiedCache()
+		if err != nil {
+			current.mu.Unlock()
+			return nil, nil, err
+		}
+		current.cache = cache
+		current.lastFlush = time.Now()
+	}
+	return current.cache, current.mu.Unlock, nil
+}
+
+// touch records that host changed since the last flush. Must be called with the lock held.
+func touch(host string) {
+	if current.changed == nil {
+		current.changed = make(map[string]bool)
+	}
+	current.changed[normalizeHost(host)] = true
+}
+
+// markDirty records a mutation of host and flushes if enough changes or time
+// have piled up. Must be called with the lock held.
+func markDirty(host string) error {
+	touch(host)
+	current.dirty = true
+	current.pending++
+	if current.pending >= flushEvery || time.Since(current.lastFlush) >= flushInterval {
+		return flushLocked()
+	}
+	return nil
+}
+
+// flushLocked writes the changed hosts of the in-memory cache to disk, under the
+// file lock. Must be called with the lock held.
+func flushLocked() error {
+	if current.cache == nil {
+		return nil
+	}
+
+	unlock, err := lockFile(GetCachePath() + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	onDisk, err := loadUnifiedCache()
+	if err != nil {
+		return err
+	}
+	merged := merge(onDisk, current.cache, current.changed)
+	if err := saveUnifiedCache(merged); err != nil {
+		return err
+	}
+	current.cache = merged
+	current.changed = nil
+	current.dirty = false
+	current.pending = 0
+	current.lastFlush = time.Now()
+	return nil
+}
+
+// reset drops the in-memory cache so the next access reloads it from disk
+func reset() {
+	current.mu.Lock()
+	defer current.mu.Unlock()
+	current.cache = nil
+	current.dirty = false
+	current.pending = 0
+	current.changed = nil
+}
+
+// merge returns the hosts of onDisk, with the changed ones taken from local
+// instead (dropped if local no longer has them)
+func merge(onDisk, local *Cache, changed map[string]bool) *Cache {
+	merged := &Cache{Hosts: make([]HostCache, 0, len(onDisk.Hosts))}
+	for _, entry := range onDisk.Hosts {
+		if !changed[normalizeHost(entry.Host)] {
+			merged.Hosts = append(merged.Hosts, entry)
+		}
+	}
+	for _, entry := range local.Hosts {
+		if changed[normalizeHost(entry.Host)] {
+			merged.Hosts = append(merged.Hosts, entry)
+		}
+	}
+	return merged
+}
+
+// Flush writes pending cache changes to disk. Call it before the program exits.
+func Flush() error {
+	current.mu.Lock()
+	defer current.mu.Unlock()
+	if !current.dirty {
+		return nil
+	}
+	return flushLocked()
+}
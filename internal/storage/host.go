@@ -0,0 +1,159 @@
+package storage
+
+// HostStore scopes cache access to a single host.
+// A disabled store never touches disk: reads return nothing and writes are dropped.
+type HostStore struct {
+	host    string
+	enabled bool
+}
+
+// ForHost returns a cache handle for the given host
+func ForHost(host string, enabled bool) *HostStore {
+	return &HostStore{
+		host:    host,
+		enabled: enabled,
+	}
+}
+
+// Enabled reports whether the store reads and writes the cache file
+func (s *HostStore) Enabled() bool {
+	return s.enabled
+}
+
+// LoadDatabase returns the cached database type, version and variant
+func (s *HostStore) LoadDatabase() (string, string, string) {
+	if !s.enabled {
+		return "", "", ""
+	}
+	return LoadDatabase(s.host)
+}
+
+// SaveDatabase saves the database type, version and variant
+func (s *HostStore) SaveDatabase(dbType, version, variant string) error {
+	if !s.enabled {
+		return nil
+	}
+	return SaveDatabase(s.host, dbType, version, variant)
+}
+
+// SaveVersionInfo saves the parsed version components
+func (s *HostStore) SaveVersionInfo(info VersionInfo) error {
+	if !s.enabled {
+		return nil
+	}
+	return SaveVersionInfo(s.host, info)
+}
+
+// LoadPartialVersion returns the version prefix left by an unfinished detection
+func (s *HostStore) LoadPartialVersion() string {
+	if !s.enabled {
+		return ""
+	}
+	return LoadPartialVersion(s.host)
+}
+
+// SavePartialVersion saves the version extracted so far, an empty string clears it
+func (s *HostStore) SavePartialVersion(partial string) error {
+	if !s.enabled {
+		return nil
+	}
+	return SavePartialVersion(s.host, partial)
+}
+
+// LoadPartialValue returns the prefix of the value of query left by an
+// unfinished extraction
+func (s *HostStore) LoadPartialValue(query string) string {
+	if !s.enabled {
+		return ""
+	}
+	return LoadPartialValue(s.host, query)
+}
+
+// SavePartialValue saves the prefix of the value of query extracted so far, an
+// empty string clears it
+func (s *HostStore) SavePartialValue(query, prefix string) error {
+	if !s.enabled {
+		return nil
+	}
+	return SavePartialValue(s.host, query, prefix)
+}
+
+// LoadTables loads all cached tables
+func (s *HostStore) LoadTables() (map[string]*TableCache, bool) {
+	if !s.enabled {
+		return nil, false
+	}
+	return LoadTables(s.host)
+}
+
+// SaveTables saves all tables
+func (s *HostStore) SaveTables(tables map[string]*TableCache) error {
+	if !s.enabled {
+		return nil
+	}
+	return SaveTables(s.host, tables)
+}
+
+// RemoveTable removes a table's cached columns and rows
+func (s *HostStore) RemoveTable(tableName string) error {
+	if !s.enabled {
+		return nil
+	}
+	return RemoveTable(s.host, tableName)
+}
+
+// LoadKnownStrings loads the known strings used for prediction
+func (s *HostStore) LoadKnownStrings() []string {
+	if !s.enabled {
+		return nil
+	}
+	return LoadKnownStrings(s.host)
+}
+
+// SaveKnownString saves a new string for prediction
+func (s *HostStore) SaveKnownString(str string) error {
+	if !s.enabled {
+		return nil
+	}
+	return SaveKnownString(s.host, str)
+}
+
+// AddTableColumn adds a column to a cached table
+func (s *HostStore) AddTableColumn(tableName, columnName string) error {
+	if !s.enabled {
+		return nil
+	}
+	return AddTableColumn(s.host, tableName, columnName)
+}
+
+// AddTableRow adds a row to a cached table
+func (s *HostStore) AddTableRow(tableName string, row map[string]string) error {
+	if !s.enabled {
+		return nil
+	}
+	return AddTableRow(s.host, tableName, row)
+}
+
+// GetTableColumns returns cached columns for a table
+func (s *HostStore) GetTableColumns(tableName string) []string {
+	if !s.enabled {
+		return nil
+	}
+	return GetTableColumns(s.host, tableName)
+}
+
+// GetTableRows returns cached rows for a table
+func (s *HostStore) GetTableRows(tableName string) []map[string]string {
+	if !s.enabled {
+		return nil
+	}
+	return GetTableRows(s.host, tableName)
+}
+
+// Flush writes pending cache changes to disk
+func (s *HostStore) Flush() error {
+	if !s.enabled {
+		return nil
+	}
+	return Flush()
+}
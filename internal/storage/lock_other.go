@@ -0,0 +1,32 @@
+//go:build !unix
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// staleLock is the age past which a lock file is assumed left by a crashed run
+const staleLock = 30 * time.Second
+
+// lockFile creates path exclusively, waiting while another process holds it.
+// Removing the file releases the lock.
+func lockFile(path string) (func(), error) {
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -9,11 +11,23 @@ import (
 
 // HostCache stores all cached data for a host
 type HostCache struct {
-	Host         string                 `json:"host"`
-	Database     string                 `json:"database,omitempty"`
-	Version      string                 `json:"version,omitempty"`
-	Tables       map[string]*TableCache `json:"tables,omitempty"`        // table_name -> columns & rows
-	KnownStrings []string               `json:"known_strings,omitempty"` // cached unique strings for prediction
+	Host           string                 `json:"host"`
+	Database       string                 `json:"database,omitempty"`
+	Version        string                 `json:"version,omitempty"`
+	Variant        string                 `json:"variant,omitempty"`         // e.g. cockroachdb on the postgres wire protocol, mariadb on mysql
+	VersionInfo    *VersionInfo           `json:"version_info,omitempty"`    // components parsed from Version
+	PartialVersion string                 `json:"partial_version,omitempty"` // version extracted so far by an unfinished detection
+	Tables         map[string]*TableCache `json:"tables,omitempty"`          // table_name -> columns & rows
+	KnownStrings   []string               `json:"known_strings,omitempty"`   // cached unique strings for prediction
+	PartialValues  map[string]string      `json:"partial_values,omitempty"`  // query hash -> prefix of an unfinished long value
+}
+
+// VersionInfo stores the components of a parsed version banner
+type VersionInfo struct {
+	Major   int    `json:"major"`
+	Minor   int    `json:"minor"`
+	Patch   int    `json:"patch"`
+	Edition string `json:"edition,omitempty"`
 }
 
 // TableCache stores columns and rows for a table
@@ -135,7 +149,9 @@ func loadUnifiedCache() (*Cache, error) {
 	return &cache, nil
 }
 
-// saveUnifiedCache saves the unified cache
+// saveUnifiedCache saves the unified cache atomically: the data is written to a
+// temp file in the same directory and renamed over the cache file, so an
+// interrupted write never leaves a truncated cache behind
 func saveUnifiedCache(cache *Cache) error {
 	cachePath := GetCachePath()
 
@@ -144,7 +160,31 @@ func saveUnifiedCache(cache *Cache) error {
 		return err
 	}
 
-	return os.WriteFile(cachePath, data, 0644)
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), ".flatsqli-*.json.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, cachePath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
 }
 
 // normalizeHost extracts base host from full host string
@@ -172,43 +212,146 @@ func findOrCreateHost(cache *Cache, host string) *HostCache {
 	return &cache.Hosts[len(cache.Hosts)-1]
 }
 
-// LoadDatabase returns the cached database type for a host
-func LoadDatabase(host string) (string, string) {
-	cache, err := loadUnifiedCache()
+// LoadDatabase returns the cached database type, version and variant for a host
+func LoadDatabase(host string) (string, string, string) {
+	cache, unlock, err := acquire()
 	if err != nil {
-		return "", ""
+		return "", "", ""
 	}
+	defer unlock()
 
 	host = normalizeHost(host)
 	for _, entry := range cache.Hosts {
 		if normalizeHost(entry.Host) == host {
-			return entry.Database, entry.Version
+			return entry.Database, entry.Version, entry.Variant
 		}
 	}
 
-	return "", ""
+	return "", "", ""
 }
 
-// SaveDatabase saves the database type for a host
-func SaveDatabase(host, dbType, version string) error {
-	cache, err := loadUnifiedCache()
+// SaveDatabase saves the database type, version and variant for a host
+func SaveDatabase(host, dbType, version, variant string) error {
+	cache, unlock, err := acquire()
 	if err != nil {
-		cache = &Cache{Hosts: []HostCache{}}
+		return err
 	}
+	defer unlock()
 
 	hostEntry := findOrCreateHost(cache, host)
 	hostEntry.Database = dbType
 	hostEntry.Version = version
+	hostEntry.Variant = variant
 
-	return saveUnifiedCache(cache)
+	return markDirty(host)
+}
+
+// SaveVersionInfo saves the parsed version components for a host
+func SaveVersionInfo(host string, info VersionInfo) error {
+	cache, unlock, err := acquire()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	hostEntry := findOrCreateHost(cache, host)
+	hostEntry.VersionInfo = &info
+
+	return markDirty(host)
+}
+
+// LoadPartialVersion returns the version prefix left by an unfinished detection
+func LoadPartialVersion(host string) string {
+	cache, unlock, err := acquire()
+	if err != nil {
+		return ""
+	}
+	defer unlock()
+
+	host = normalizeHost(host)
+	for _, entry := range cache.Hosts {
+		if normalizeHost(entry.Host) == host {
+			return entry.PartialVersion
+		}
+	}
+	return ""
+}
+
+// SavePartialVersion saves the version extracted so far for a host, an empty
+// string clears it
+func SavePartialVersion(host, partial string) error {
+	cache, unlock, err := acquire()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	hostEntry := findOrCreateHost(cache, host)
+	if hostEntry.PartialVersion == partial {
+		return nil
+	}
+	hostEntry.PartialVersion = partial
+
+	return markDirty(host)
+}
+
+// queryKey returns the key of a query in PartialValues
+func queryKey(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:8])
+}
+
+// LoadPartialValue returns the prefix of the value of query left by an
+// unfinished extraction
+func LoadPartialValue(host, query string) string {
+	cache, unlock, err := acquire()
+	if err != nil {
+		return ""
+	}
+	defer unlock()
+
+	host = normalizeHost(host)
+	for _, entry := range cache.Hosts {
+		if normalizeHost(entry.Host) == host {
+			return entry.PartialValues[queryKey(query)]
+		}
+	}
+	return ""
+}
+
+// SavePartialValue saves the prefix of the value of query extracted so far, an
+// empty string clears it
+func SavePartialValue(host, query, prefix string) error {
+	cache, unlock, err := acquire()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	hostEntry := findOrCreateHost(cache, host)
+	key := queryKey(query)
+	if hostEntry.PartialValues[key] == prefix {
+		return nil
+	}
+	if prefix == "" {
+		delete(hostEntry.PartialValues, key)
+	} else {
+		if hostEntry.PartialValues == nil {
+			hostEntry.PartialValues = make(map[string]string)
+		}
+		hostEntry.PartialValues[key] = prefix
+	}
+
+	return markDirty(host)
 }
 
 // LoadTables loads all cached tables for a host
 func LoadTables(host string) (map[string]*TableCache, bool) {
-	cache, err := loadUnifiedCache()
+	cache, unlock, err := acquire()
 	if err != nil {
 		return nil, false
 	}
+	defer unlock()
 
 	host = normalizeHost(host)
 	for _, entry := range cache.Hosts {
@@ -225,29 +368,65 @@ func LoadTables(host string) (map[string]*TableCache, bool) {
 
 // SaveTables saves all tables for a host
 func SaveTables(host string, tables map[string]*TableCache) error {
-	cache, err := loadUnifiedCache()
+	cache, unlock, err := acquire()
 	if err != nil {
-		cache = &Cache{Hosts: []HostCache{}}
+		return err
 	}
+	defer unlock()
 
 	hostEntry := findOrCreateHost(cache, host)
 	hostEntry.Tables = tables
 
-	return saveUnifiedCache(cache)
+	return markDirty(host)
+}
+
+// LoadHosts returns all cached host entries
+func LoadHosts() ([]HostCache, error) {
+	cache, unlock, err := acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return cache.Hosts, nil
+}
+
+// LoadHost returns the cached entry for a host
+func LoadHost(host string) (*HostCache, bool) {
+	cache, unlock, err := acquire()
+	if err != nil {
+		return nil, false
+	}
+	defer unlock()
+
+	host = normalizeHost(host)
+	for i := range cache.Hosts {
+		if normalizeHost(cache.Hosts[i].Host) == host {
+			return &cache.Hosts[i], true
+		}
+	}
+	return nil, false
 }
 
 // ClearCache removes all cached entries
 func ClearCache() error {
+	reset()
 	cachePath := GetCachePath()
+	unlock, err := lockFile(cachePath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	return os.Remove(cachePath)
 }
 
 // RemoveHost removes a specific host from the cache
 func RemoveHost(host string) error {
-	cache, err := loadUnifiedCache()
+	cache, unlock, err := acquire()
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
 	host = normalizeHost(host)
 	var newHosts []HostCache
@@ -258,15 +437,35 @@ func RemoveHost(host string) error {
 	}
 	cache.Hosts = newHosts
 
-	return saveUnifiedCache(cache)
+	touch(host)
+	return flushLocked()
+}
+
+// RemoveTable removes a table's cached columns and rows
+func RemoveTable(host, tableName string) error {
+	cache, unlock, err := acquire()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	host = normalizeHost(host)
+	for i := range cache.Hosts {
+		if normalizeHost(cache.Hosts[i].Host) == host && cache.Hosts[i].Tables != nil {
+			delete(cache.Hosts[i].Tables, tableName)
+		}
+	}
+
+	return markDirty(host)
 }
 
 // LoadKnownStrings loads all known strings for a host
 func LoadKnownStrings(host string) []string {
-	cache, err := loadUnifiedCache()
+	cache, unlock, err := acquire()
 	if err != nil {
 		return nil
 	}
+	defer unlock()
 
 	host = normalizeHost(host)
 	for _, entry := range cache.Hosts {
@@ -283,10 +482,11 @@ func SaveKnownString(host, str string) error {
 		return nil
 	}
 
-	cache, err := loadUnifiedCache()
+	cache, unlock, err := acquire()
 	if err != nil {
-		cache = &Cache{Hosts: []HostCache{}}
+		return err
 	}
+	defer unlock()
 
 	hostEntry := findOrCreateHost(cache, host)
 
@@ -297,15 +497,16 @@ func SaveKnownString(host, str string) error {
 	}
 
 	hostEntry.KnownStrings = append(hostEntry.KnownStrings, str)
-	return saveUnifiedCache(cache)
+	return markDirty(host)
 }
 
 // AddTableColumn adds a column to a table in the cache
 func AddTableColumn(host, tableName, columnName string) error {
-	cache, err := loadUnifiedCache()
+	cache, unlock, err := acquire()
 	if err != nil {
-		cache = &Cache{Hosts: []HostCache{}}
+		return err
 	}
+	defer unlock()
 
 	hostEntry := findOrCreateHost(cache, host)
 	if hostEntry.Tables == nil {
@@ -331,15 +532,16 @@ func AddTableColumn(host, tableName, columnName string) error {
 	}
 	hostEntry.Tables[tableName] = tableCache
 
-	return saveUnifiedCache(cache)
+	return markDirty(host)
 }
 
 // AddTableRow adds a row to a table in the cache
 func AddTableRow(host, tableName string, row map[string]string) error {
-	cache, err := loadUnifiedCache()
+	cache, unlock, err := acquire()
 	if err != nil {
-		cache = &Cache{Hosts: []HostCache{}}
+		return err
 	}
+	defer unlock()
 
 	hostEntry := findOrCreateHost(cache, host)
 	if hostEntry.Tables == nil {
@@ -354,15 +556,16 @@ func AddTableRow(host, tableName string, row map[string]string) error {
 	tableCache.Rows = append(tableCache.Rows, row)
 	hostEntry.Tables[tableName] = tableCache
 
-	return saveUnifiedCache(cache)
+	return markDirty(host)
 }
 
 // GetTableColumns returns cached columns for a table
 func GetTableColumns(host, tableName string) []string {
-	cache, err := loadUnifiedCache()
+	cache, unlock, err := acquire()
 	if err != nil {
 		return nil
 	}
+	defer unlock()
 
 	host = normalizeHost(host)
 	for _, entry := range cache.Hosts {
@@ -380,10 +583,11 @@ func GetTableColumns(host, tableName string) []string {
 
 // GetTableRows returns cached rows for a table
 func GetTableRows(host, tableName string) []map[string]string {
-	cache, err := loadUnifiedCache()
+	cache, unlock, err := acquire()
 	if err != nil {
 		return nil
 	}
+	defer unlock()
 
 	host = normalizeHost(host)
 	for _, entry := range cache.Hosts {
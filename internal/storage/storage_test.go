@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+)
+
+// writerHostEnv makes the test binary run TestWriterProcess as a cache writer
+// for the host it holds, see TestFlushKeepsConcurrentWriters
+const writerHostEnv = "FLATSQLI_TEST_WRITER_HOST"
+
+// writerFlushes is how many times each writer changes and flushes its host
+const writerFlushes = 20
+
+// TestWriterProcess is the body of a writer subprocess, skipped otherwise
+func TestWriterProcess(t *testing.T) {
+	host := os.Getenv(writerHostEnv)
+	if host == "" {
+		t.Skip("only runs as a writer subprocess")
+	}
+	if err := SaveDatabase(host, "MySQL", "8.0.36", ""); err != nil {
+		t.Fatal(err)
+	}
+	for i := range writerFlushes {
+		if err := SaveKnownString(host, fmt.Sprintf("value%d", i)); err != nil {
+			t.Fatal(err)
+		}
+		if err := Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFlushKeepsConcurrentWriters(t *testing.T) {
+	if os.Getenv(writerHostEnv) != "" {
+		t.Skip("running as a writer subprocess")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	reset()
+	t.Cleanup(reset)
+
+	// Writers are separate processes, each with its own in-memory cache and
+	// host, flushing at the same time under the file lock
+	const writers = 4
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestWriterProcess$", "-test.count=1")
+			cmd.Env = append(os.Environ(), "HOME="+home, fmt.Sprintf("%s=host%d.example", writerHostEnv, i))
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("writer %d: %v\n%s", i, err, out)
+			}
+		}()
+	}
+	wg.Wait()
+
+	onDisk, err := loadUnifiedCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := make(map[string]HostCache)
+	for _, entry := range onDisk.Hosts {
+		hosts[entry.Host] = entry
+	}
+	for i := range writers {
+		host := fmt.Sprintf("host%d.example", i)
+		got, ok := hosts[host]
+		if !ok {
+			t.Errorf("%s lost", host)
+			continue
+		}
+		if got.Database != "MySQL" || len(got.KnownStrings) != writerFlushes {
+			t.Errorf("%s = %q with %d known strings, want MySQL with %d", host, got.Database, len(got.KnownStrings), writerFlushes)
+		}
+	}
+}
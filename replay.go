@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/morkin1792/flatsqli/internal/parser"
+	"github.com/morkin1792/flatsqli/internal/requester"
+	"github.com/morkin1792/flatsqli/internal/ui"
+)
+
+// ReplayConfig holds replay mode configuration
+type ReplayConfig struct {
+	HTTPOptions
+	Verbose bool
+	Quiet   bool
+	NoColor bool
+}
+
+func runReplayMode() {
+	replayCmd := flag.NewFlagSet("replay", flag.ExitOnError)
+	var config ReplayConfig
+
+	replayCmd.BoolVar(&config.Verbose, "v", false, "")
+	replayCmd.BoolVar(&config.Verbose, "verbose", false, "Enable verbose output")
+	replayCmd.BoolVar(&config.Quiet, "quiet", false, "Only print errors and results")
+	replayCmd.BoolVar(&config.NoColor, "no-color", false, "Disable colored output")
+	registerHTTPFlags(replayCmd, &config.HTTPOptions)
+
+	replayCmd.Usage = func() {
+		ui.Banner(version)
+		fmt.Fprintf(os.Stderr, `Usage: flatsqli replay [options] <log.jsonl>
+
+Re-sends every request of a -log-file transaction log, in order, and compares
+each response (status, words, length) with the recorded one. Responses that
+changed are reported as drift.
+
+%s
+Examples:
+  flatsqli exploit -rf req.txt -fid -log-file run.jsonl
+  flatsqli replay run.jsonl
+  flatsqli replay -proxy http://127.0.0.1:8080 run.jsonl
+
+`, generalOptionsHelp)
+	}
+
+	replayCmd.Parse(os.Args[2:])
+	ui.SetQuiet(config.Quiet)
+	ui.SetNoColor(config.NoColor)
+
+	if replayCmd.NArg() != 1 {
+		ui.Error("A transaction log is required")
+		replayCmd.Usage()
+		os.Exit(1)
+	}
+	logPath := replayCmd.Arg(0)
+	if config.LogFile != "" && sameFile(config.LogFile, logPath) {
+		ui.Error("-log-file cannot be the log being replayed")
+		os.Exit(1)
+	}
+
+	runReplay(config, logPath)
+}
+
+// runReplay re-sends the logged requests and reports fingerprint drift
+func runReplay(config ReplayConfig, logPath string) {
+	entries, err := requester.ReadLog(logPath)
+	if err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	if len(entries) == 0 {
+		ui.Info("No requests in %s", logPath)
+		return
+	}
+	ui.Info("Replaying %d request(s) from %s", len(entries), logPath)
+
+	if err := config.loadHeadersFile(); err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	if err := config.openLogger(); err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	defer config.logger.Close()
+
+	// The first request is the base of the requester, each entry brings its own
+	base, err := parser.URLToRequestWithBody(entries[0].URL, entries[0].Method, entries[0].Body)
+	if err != nil {
+		ui.Error("Invalid logged request: %v", err)
+		os.Exit(1)
+	}
+	httpRequester, err := newRequester(base, config.HTTPOptions, config.Verbose)
+	if err != nil {
+		ui.Error("Failed to create requester: %v", err)
+		os.Exit(1)
+	}
+
+	drifted, failed := 0, 0
+	for i, entry := range entries {
+		ui.Progress("Replaying request %d/%d...", i+1, len(entries))
+		resp, err := httpRequester.Replay(entry)
+
+		recorded := entry.Fingerprint()
+		switch {
+		case err != nil && recorded == nil:
+			// Failed then, fails now
+		case err != nil:
+			failed++
+			ui.ProgressDone()
+			ui.Warning("#%d %s %s: now fails (%v), was [Status: %d, Words: %d, Length: %d]",
+				entry.RequestNum, entry.Method, entry.URL, err, entry.Status, entry.Words, entry.Length)
+		case recorded == nil:
+			drifted++
+			ui.ProgressDone()
+			ui.Warning("#%d %s %s: now answers [Status: %d, Words: %d, Length: %d], failed before (%s)",
+				entry.RequestNum, entry.Method, entry.URL, resp.Fingerprint.StatusCode, resp.Fingerprint.WordCount, resp.Fingerprint.ContentLength, entry.Error)
+		case !recorded.Equals(resp.Fingerprint):
+			drifted++
+			ui.ProgressDone()
+			ui.Warning("#%d %s %s: [Status: %d, Words: %d, Length: %d] -> [Status: %d, Words: %d, Length: %d]",
+				entry.RequestNum, entry.Method, entry.URL, entry.Status, entry.Words, entry.Length,
+				resp.Fingerprint.StatusCode, resp.Fingerprint.WordCount, resp.Fingerprint.ContentLength)
+			if entry.Payload != "" {
+				ui.Info("  Payload: %s", entry.Payload)
+			}
+		}
+	}
+	ui.ProgressDone()
+
+	if drifted == 0 && failed == 0 {
+		ui.Success("All %d response(s) match the log", len(entries))
+	} else {
+		ui.Warning("%d of %d response(s) drifted, %d now fail", drifted, len(entries), failed)
+	}
+	if ui.Quiet() {
+		ui.Data("%d/%d drifted, %d failed", drifted, len(entries), failed)
+	}
+	ui.Info("%s", httpRequester.Stats())
+}
+
+// sameFile reports whether two paths name the same file
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA == nil && errB == nil {
+		return os.SameFile(infoA, infoB)
+	}
+	absA, _ := filepath.Abs(a)
+	absB, _ := filepath.Abs(b)
+	return absA == absB
+}